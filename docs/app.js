@@ -6,6 +6,8 @@ const SCHEMA_FILES = {
   "opensspm.connector_manifest": "opensspm.connector_manifest.schema.json",
   "opensspm.profile": "opensspm.profile.schema.json",
   "opensspm.dictionary": "opensspm.dictionary.schema.json",
+  "opensspm.conformance_suite": "opensspm.conformance_suite.schema.json",
+  "opensspm.evaluation_result": "opensspm.evaluation_result.schema.json",
 };
 
 const state = {
@@ -5,6 +5,7 @@ package v1
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 type DatasetErrorKind string
@@ -24,15 +25,35 @@ const (
 	ScopeKind_GLOBAL             ScopeKind = "global"
 )
 
+type ResultStatus string
+
+const (
+	ResultStatus_ERROR          ResultStatus = "error"
+	ResultStatus_FAIL           ResultStatus = "fail"
+	ResultStatus_NOT_APPLICABLE ResultStatus = "not_applicable"
+	ResultStatus_PASS           ResultStatus = "pass"
+	ResultStatus_UNKNOWN        ResultStatus = "unknown"
+)
+
 type EvalContext struct {
 	ScopeKind         ScopeKind `json:"scope_kind"`
 	ConnectorKind     string    `json:"connector_kind,omitempty"`
 	ConnectorInstance string    `json:"connector_instance,omitempty"`
+	// ConnectorInstances maps connector kinds to the instance that serves datasets
+	// qualified with that kind (check.connector_kind) in global rulesets.
+	ConnectorInstances map[string]string `json:"connector_instances,omitempty"`
+	// ReferenceTime is the "now" that older_than and newer_than compare against. Engines
+	// that leave it unset use the time evaluation starts.
+	ReferenceTime *time.Time `json:"reference_time,omitempty"`
 }
 
 type DatasetRef struct {
 	Dataset string `json:"dataset"`
 	Version int    `json:"version"`
+	// ConnectorKind is set for datasets a global ruleset qualifies with a connector kind, and
+	// ConnectorInstance to the EvalContext's instance of that kind (if any).
+	ConnectorKind     string `json:"connector_kind,omitempty"`
+	ConnectorInstance string `json:"connector_instance,omitempty"`
 }
 
 type DatasetError struct {
@@ -49,3 +70,50 @@ type DatasetProvider interface {
 	Capabilities(ctx context.Context) []DatasetRef
 	GetDataset(ctx context.Context, eval EvalContext, ref DatasetRef) DatasetResult
 }
+
+type EvaluationResultDoc struct {
+	SchemaVersion int              `json:"schema_version"`
+	Kind          string           `json:"kind"`
+	Result        EvaluationResult `json:"result"`
+}
+
+type EvaluationResult struct {
+	Ruleset     EvaluationRuleset      `json:"ruleset"`
+	EvalContext EvalContext            `json:"eval_context"`
+	StartedAt   time.Time              `json:"started_at"`
+	FinishedAt  time.Time              `json:"finished_at"`
+	Rules       []EvaluationRuleResult `json:"rules"`
+}
+
+type EvaluationRuleset struct {
+	Key  string `json:"key"`
+	Hash string `json:"hash"`
+}
+
+type EvaluationRuleResult struct {
+	RuleKey           string                   `json:"rule_key"`
+	Status            ResultStatus             `json:"status"`
+	Reason            string                   `json:"reason,omitempty"`
+	EvaluatedAt       *time.Time               `json:"evaluated_at,omitempty"`
+	AffectedResources []EvaluationResource     `json:"affected_resources,omitempty"`
+	DatasetErrors     []EvaluationDatasetError `json:"dataset_errors,omitempty"`
+}
+
+type EvaluationResource struct {
+	ID      string `json:"id"`
+	Display string `json:"display,omitempty"`
+}
+
+type EvaluationDatasetError struct {
+	Dataset           string           `json:"dataset"`
+	Version           int              `json:"version"`
+	ConnectorKind     string           `json:"connector_kind,omitempty"`
+	ConnectorInstance string           `json:"connector_instance,omitempty"`
+	Kind              DatasetErrorKind `json:"kind"`
+	Message           string           `json:"message,omitempty"`
+}
+
+func ParseEvaluationResultDoc(b []byte) (EvaluationResultDoc, error) {
+	var d EvaluationResultDoc
+	return d, json.Unmarshal(b, &d)
+}
@@ -4,12 +4,24 @@ package v1
 
 import "encoding/json"
 
+type AggregateFunction string
+
+const (
+	AggregateFunction_AVG            AggregateFunction = "avg"
+	AggregateFunction_COUNT_DISTINCT AggregateFunction = "count_distinct"
+	AggregateFunction_MAX            AggregateFunction = "max"
+	AggregateFunction_MIN            AggregateFunction = "min"
+	AggregateFunction_SUM            AggregateFunction = "sum"
+)
+
 type CheckType string
 
 const (
+	CheckType_DATASET_AGGREGATE_COMPARE  CheckType = "dataset.aggregate_compare"
 	CheckType_DATASET_COUNT_COMPARE      CheckType = "dataset.count_compare"
 	CheckType_DATASET_FIELD_COMPARE      CheckType = "dataset.field_compare"
 	CheckType_DATASET_JOIN_COUNT_COMPARE CheckType = "dataset.join_count_compare"
+	CheckType_DATASET_JOIN_FIELD_COMPARE CheckType = "dataset.join_field_compare"
 	CheckType_MANUAL_ATTESTATION         CheckType = "manual.attestation"
 )
 
@@ -86,16 +98,29 @@ const (
 type Operator string
 
 const (
-	Operator_ABSENT   Operator = "absent"
-	Operator_CONTAINS Operator = "contains"
-	Operator_EQ       Operator = "eq"
-	Operator_EXISTS   Operator = "exists"
-	Operator_GT       Operator = "gt"
-	Operator_GTE      Operator = "gte"
-	Operator_IN       Operator = "in"
-	Operator_LT       Operator = "lt"
-	Operator_LTE      Operator = "lte"
-	Operator_NEQ      Operator = "neq"
+	Operator_ABSENT         Operator = "absent"
+	Operator_CONTAINS       Operator = "contains"
+	Operator_ENDS_WITH      Operator = "ends_with"
+	Operator_EQ             Operator = "eq"
+	Operator_EQ_IGNORE_CASE Operator = "eq_ignore_case"
+	Operator_EXISTS         Operator = "exists"
+	Operator_GT             Operator = "gt"
+	Operator_GTE            Operator = "gte"
+	Operator_IN             Operator = "in"
+	Operator_LT             Operator = "lt"
+	Operator_LTE            Operator = "lte"
+	Operator_MATCHES        Operator = "matches"
+	Operator_NEQ            Operator = "neq"
+	Operator_NEWER_THAN     Operator = "newer_than"
+	Operator_OLDER_THAN     Operator = "older_than"
+	Operator_STARTS_WITH    Operator = "starts_with"
+)
+
+type Quantifier string
+
+const (
+	Quantifier_ALL Quantifier = "all"
+	Quantifier_ANY Quantifier = "any"
 )
 
 type ReferenceType string
@@ -116,6 +141,16 @@ const (
 	RemediationEffort_MEDIUM RemediationEffort = "medium"
 )
 
+type ResultStatus string
+
+const (
+	ResultStatus_ERROR          ResultStatus = "error"
+	ResultStatus_FAIL           ResultStatus = "fail"
+	ResultStatus_NOT_APPLICABLE ResultStatus = "not_applicable"
+	ResultStatus_PASS           ResultStatus = "pass"
+	ResultStatus_UNKNOWN        ResultStatus = "unknown"
+)
+
 type ScopeKind string
 
 const (
@@ -172,6 +207,7 @@ type RulesetDoc struct {
 
 type Ruleset struct {
 	Key               string               `json:"key"`
+	Version           string               `json:"version,omitempty"`
 	Name              string               `json:"name"`
 	Scope             Scope                `json:"scope"`
 	Source            *Source              `json:"source,omitempty"`
@@ -223,31 +259,39 @@ type Check struct {
 	OnSyncError        ErrorPolicy `json:"on_sync_error,omitempty"`
 	Notes              string      `json:"notes,omitempty"`
 
-	Dataset string      `json:"dataset,omitempty"`
-	Where   []Predicate `json:"where,omitempty"`
+	Dataset       string      `json:"dataset,omitempty"`
+	ConnectorKind string      `json:"connector_kind,omitempty"`
+	Where         []Predicate `json:"where,omitempty"`
 
 	Assert *Predicate          `json:"assert,omitempty"`
 	Expect *FieldCompareExpect `json:"expect,omitempty"`
 
 	Compare *Compare `json:"compare,omitempty"`
 
+	Aggregate *Aggregate `json:"aggregate,omitempty"`
+
 	Left            *JoinSide       `json:"left,omitempty"`
 	Right           *JoinSide       `json:"right,omitempty"`
 	OnUnmatchedLeft OnUnmatchedLeft `json:"on_unmatched_left,omitempty"`
 }
 
 type Predicate struct {
-	Path       string   `json:"path,omitempty"`
-	LeftPath   string   `json:"left_path,omitempty"`
-	RightPath  string   `json:"right_path,omitempty"`
-	Op         Operator `json:"op"`
-	Value      any      `json:"value,omitempty"`
-	ValueParam string   `json:"value_param,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	LeftPath   string     `json:"left_path,omitempty"`
+	RightPath  string     `json:"right_path,omitempty"`
+	Op         Operator   `json:"op,omitempty"`
+	Value      any        `json:"value,omitempty"`
+	ValueParam string     `json:"value_param,omitempty"`
+	Quantifier Quantifier `json:"quantifier,omitempty"`
+
+	AllOf []Predicate `json:"all_of,omitempty"`
+	AnyOf []Predicate `json:"any_of,omitempty"`
+	Not   *Predicate  `json:"not,omitempty"`
 }
 
 type Compare struct {
 	Op         CompareOp `json:"op"`
-	Value      *int      `json:"value,omitempty"`
+	Value      *float64  `json:"value,omitempty"`
 	ValueParam string    `json:"value_param,omitempty"`
 }
 
@@ -257,14 +301,22 @@ type FieldCompareExpect struct {
 	OnEmpty     FieldCompareOnEmpty `json:"on_empty,omitempty"`
 }
 
+type Aggregate struct {
+	Function AggregateFunction   `json:"function"`
+	Path     string              `json:"path"`
+	OnEmpty  FieldCompareOnEmpty `json:"on_empty,omitempty"`
+}
+
 type JoinSide struct {
-	Dataset string `json:"dataset"`
-	KeyPath string `json:"key_path"`
+	Dataset       string `json:"dataset"`
+	KeyPath       string `json:"key_path"`
+	ConnectorKind string `json:"connector_kind,omitempty"`
 }
 
 type DatasetRefSpec struct {
-	Dataset string `json:"dataset"`
-	Version int    `json:"version"`
+	Dataset       string `json:"dataset"`
+	Version       int    `json:"version"`
+	ConnectorKind string `json:"connector_kind,omitempty"`
 }
 
 type FrameworkMapping struct {
@@ -363,12 +415,72 @@ type Profile struct {
 	Key         string              `json:"key"`
 	Name        string              `json:"name"`
 	Description string              `json:"description,omitempty"`
+	Extends     []string            `json:"extends,omitempty"`
 	Rulesets    []ProfileRulesetRef `json:"rulesets"`
 }
 
 type ProfileRulesetRef struct {
-	Key     string `json:"key"`
-	Version string `json:"version,omitempty"`
+	Key       string         `json:"key"`
+	Version   string         `json:"version,omitempty"`
+	Overrides []RuleOverride `json:"overrides,omitempty"`
+}
+
+// RuleOverride tailors one rule of a ruleset included by a profile.
+type RuleOverride struct {
+	RuleKey    string         `json:"rule_key"`
+	Exclude    bool           `json:"exclude,omitempty"`
+	Severity   Severity       `json:"severity,omitempty"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+	Rationale  string         `json:"rationale,omitempty"`
+}
+
+type ConformanceSuiteDoc struct {
+	SchemaVersion int              `json:"schema_version"`
+	Kind          string           `json:"kind"`
+	Suite         ConformanceSuite `json:"suite"`
+}
+
+type ConformanceSuite struct {
+	Key         string              `json:"key"`
+	Description string              `json:"description,omitempty"`
+	Vectors     []ConformanceVector `json:"vectors"`
+}
+
+type ConformanceVector struct {
+	Key           string               `json:"key"`
+	Description   string               `json:"description,omitempty"`
+	Rule          *ConformanceRuleRef  `json:"rule,omitempty"`
+	Check         *Check               `json:"check,omitempty"`
+	Evidence      *Evidence            `json:"evidence,omitempty"`
+	Parameters    map[string]any       `json:"parameters,omitempty"`
+	ReferenceTime string               `json:"reference_time,omitempty"`
+	Datasets      []ConformanceDataset `json:"datasets"`
+	Expect        ConformanceExpect    `json:"expect"`
+}
+
+type ConformanceRuleRef struct {
+	RulesetKey string `json:"ruleset_key"`
+	RuleKey    string `json:"rule_key"`
+}
+
+type ConformanceDataset struct {
+	Dataset       string                   `json:"dataset"`
+	Version       int                      `json:"version"`
+	ConnectorKind string                   `json:"connector_kind,omitempty"`
+	Rows          []json.RawMessage        `json:"rows,omitempty"`
+	Error         *ConformanceDatasetError `json:"error,omitempty"`
+}
+
+type ConformanceDatasetError struct {
+	Kind    DatasetErrorKind `json:"kind"`
+	Message string           `json:"message,omitempty"`
+}
+
+type ConformanceExpect struct {
+	Status ResultStatus `json:"status"`
+	// AffectedResourceIDs is nil if the vector does not check affected resources; an
+	// empty list expects none.
+	AffectedResourceIDs *[]string `json:"affected_resource_ids,omitempty"`
 }
 
 type DictionaryDoc struct {
@@ -399,13 +511,15 @@ type RequirementsIndex struct {
 }
 
 type RulesetRequirement struct {
-	RulesetKey  string            `json:"ruleset_key"`
-	Status      string            `json:"status"`
-	Scope       Scope             `json:"scope"`
-	Datasets    []DatasetRefSpec  `json:"datasets"`
-	CheckTypes  []CheckType       `json:"check_types"`
-	ValueParams []string          `json:"value_params"`
-	Rules       []RuleRequirement `json:"rules"`
+	RulesetKey     string            `json:"ruleset_key"`
+	RulesetVersion string            `json:"ruleset_version,omitempty"`
+	Status         string            `json:"status"`
+	Scope          Scope             `json:"scope"`
+	Datasets       []DatasetRefSpec  `json:"datasets"`
+	CheckTypes     []CheckType       `json:"check_types"`
+	ValueParams    []string          `json:"value_params"`
+	Rules          []RuleRequirement `json:"rules"`
+	InactiveRules  []RuleRequirement `json:"inactive_rules,omitempty"`
 }
 
 type RuleRequirement struct {
@@ -414,6 +528,7 @@ type RuleRequirement struct {
 	Datasets    []DatasetRefSpec `json:"datasets"`
 	CheckType   *CheckType       `json:"check_type"`
 	ValueParams []string         `json:"value_params"`
+	ReplacedBy  string           `json:"replaced_by,omitempty"`
 	Monitoring  struct {
 		Status MonitoringStatus `json:"status"`
 	} `json:"monitoring"`
@@ -444,3 +559,8 @@ func ParseDescriptorV1(b []byte) (DescriptorV1, error) {
 	var d DescriptorV1
 	return d, json.Unmarshal(b, &d)
 }
+
+func ParseConformanceSuiteDoc(b []byte) (ConformanceSuiteDoc, error) {
+	var d ConformanceSuiteDoc
+	return d, json.Unmarshal(b, &d)
+}
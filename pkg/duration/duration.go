@@ -0,0 +1,82 @@
+// Package duration parses the ISO-8601 durations taken by the older_than and newer_than
+// predicate operators.
+//
+// A duration is "P" followed by years (Y), months (M), weeks (W) and days (D), then
+// optionally "T" and hours (H), minutes (M) and seconds (S), such as P90D, P1Y6M or PT12H.
+// Every component is a non-negative integer except seconds, which may have a fraction, and
+// at least one component is required. Years, months, weeks and days are calendar units:
+// they are subtracted from a time in its location with time.Time.AddDate. Durations whose
+// calendar part exceeds about 10000 years, or whose time part does not fit in a
+// time.Duration, are out of range.
+package duration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDays bounds the calendar part of a duration to about 10000 years.
+const maxDays = 10000 * 366
+
+// Duration is a parsed ISO-8601 duration.
+type Duration struct {
+	Years, Months, Weeks, Days int
+	// Clock is the time part (hours, minutes and seconds).
+	Clock time.Duration
+}
+
+var pattern = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// Parse parses an ISO-8601 duration such as "P90D".
+func Parse(s string) (Duration, error) {
+	m := pattern.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return Duration{}, fmt.Errorf("%q is not an ISO-8601 duration (such as P90D or PT12H)", s)
+	}
+	var d Duration
+	// The calendar part may span at most maxDays, counting years and months at their
+	// longest, so that Before cannot overflow.
+	days := 0
+	for i, c := range []struct {
+		dst  *int
+		days int
+	}{{&d.Years, 366}, {&d.Months, 31}, {&d.Weeks, 7}, {&d.Days, 1}} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil || n > (maxDays-days)/c.days {
+			return Duration{}, fmt.Errorf("%q is out of range", s)
+		}
+		*c.dst = n
+		days += n * c.days
+	}
+	// The time part must fit in a time.Duration as a whole, not just per component.
+	const maxClock = time.Duration(1<<63 - 1)
+	for i, unit := range []time.Duration{time.Hour, time.Minute} {
+		if m[i+5] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(m[i+5], 10, 64)
+		if err != nil || n > int64((maxClock-d.Clock)/unit) {
+			return Duration{}, fmt.Errorf("%q is out of range", s)
+		}
+		d.Clock += time.Duration(n) * unit
+	}
+	if m[7] != "" {
+		sec, err := strconv.ParseFloat(m[7], 64)
+		if err != nil || sec*float64(time.Second) >= float64(maxClock-d.Clock) {
+			return Duration{}, fmt.Errorf("%q is out of range", s)
+		}
+		d.Clock += time.Duration(sec * float64(time.Second))
+	}
+	return d, nil
+}
+
+// Before returns t moved back by d: the calendar components first, then the time part.
+func (d Duration) Before(t time.Time) time.Time {
+	return t.AddDate(-d.Years, -d.Months, -7*d.Weeks-d.Days).Add(-d.Clock)
+}
@@ -0,0 +1,59 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Duration
+	}{
+		{in: "P90D", want: Duration{Days: 90}},
+		{in: "P1Y6M", want: Duration{Years: 1, Months: 6}},
+		{in: "P2W", want: Duration{Weeks: 2}},
+		{in: "PT12H", want: Duration{Clock: 12 * time.Hour}},
+		{in: "P1DT1H30M0.5S", want: Duration{Days: 1, Clock: time.Hour + 30*time.Minute + 500*time.Millisecond}},
+		{in: "PT2562047H", want: Duration{Clock: 2562047 * time.Hour}},
+		{in: "P10000Y", want: Duration{Years: 10000}},
+		{in: "P3660000D", want: Duration{Days: 3660000}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "P", "PT", "P1DT", "90D", "P-1D", "P1.5D", "P1H", "PT1D", "P1M1Y", "p90d", "PT2562048H", "PT2562047H59M", "PT153722867M60S", "P10001Y", "P3660001D", "P9999Y53W", "P2000000000000000000W", "P99999999999999999999D"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q): expected an error", in)
+		}
+	}
+}
+
+func TestBefore(t *testing.T) {
+	ref := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{in: "P90D", want: time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC)},
+		{in: "P1M", want: time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)}, // February 31st normalizes
+		{in: "P1WT13H", want: time.Date(2026, 3, 23, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		d, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := d.Before(ref); !got.Equal(tt.want) {
+			t.Errorf("%s before %s = %s, want %s", tt.in, ref, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	runtimev1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/runtime/v1"
+	specv1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/spec/v1"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec"
+)
+
+// TestConformance runs every compiled conformance vector (dist/conformance) through the reference evaluator.
+func TestConformance(t *testing.T) {
+	d := loadRepoDescriptor(t)
+	// Vectors reference the highest version of a ruleset, as osspec validate checks them.
+	rulesets := map[string]specv1.Ruleset{}
+	for _, rs := range d.Rulesets {
+		prev, ok := rulesets[rs.Object.Ruleset.Key]
+		if !ok || osspec.CompareVersions(rs.Object.Ruleset.Version, prev.Version) > 0 {
+			rulesets[rs.Object.Ruleset.Key] = rs.Object.Ruleset
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join("..", "..", "dist", "conformance", "*.json"))
+	if err != nil {
+		t.Fatalf("glob conformance suites: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no conformance suites found in dist/conformance")
+	}
+
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read %s: %v", p, err)
+		}
+		doc, err := specv1.ParseConformanceSuiteDoc(b)
+		if err != nil {
+			t.Fatalf("parse %s: %v", p, err)
+		}
+		suite := doc.Suite
+		if len(suite.Vectors) == 0 {
+			t.Fatalf("%s: suite has no vectors", p)
+		}
+		t.Run(suite.Key, func(t *testing.T) {
+			for _, v := range suite.Vectors {
+				t.Run(v.Key, func(t *testing.T) {
+					runVector(t, rulesets, v)
+				})
+			}
+		})
+	}
+}
+
+func runVector(t *testing.T, rulesets map[string]specv1.Ruleset, v specv1.ConformanceVector) {
+	t.Helper()
+
+	var (
+		ruleset specv1.Ruleset
+		rule    specv1.Rule
+		params  map[string]any
+	)
+	if v.Rule != nil {
+		rs, ok := rulesets[v.Rule.RulesetKey]
+		if !ok {
+			t.Fatalf("unknown ruleset %q", v.Rule.RulesetKey)
+		}
+		i := slices.IndexFunc(rs.Rules, func(r specv1.Rule) bool { return r.Key == v.Rule.RuleKey })
+		if i < 0 {
+			t.Fatalf("unknown rule %q in ruleset %q", v.Rule.RuleKey, v.Rule.RulesetKey)
+		}
+		ruleset, rule, params = rs, rs.Rules[i], v.Parameters
+	} else {
+		ruleset = specv1.Ruleset{Key: "conformance.inline", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}
+		rule = specv1.Rule{Key: v.Key, Check: v.Check, Evidence: v.Evidence}
+		if v.Parameters != nil {
+			rule.Parameters = &specv1.Parameters{Defaults: v.Parameters}
+		}
+	}
+
+	eval := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}
+	if ruleset.Scope.Kind == specv1.ScopeKind_CONNECTOR_INSTANCE {
+		eval = runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_CONNECTOR_INSTANCE, ConnectorKind: ruleset.Scope.ConnectorKind, ConnectorInstance: "conformance"}
+	}
+	if v.ReferenceTime != "" {
+		now, err := time.Parse(time.RFC3339, v.ReferenceTime)
+		if err != nil {
+			t.Fatalf("reference_time: %v", err)
+		}
+		eval.ReferenceTime = &now
+	}
+
+	provider := fakeProvider{}
+	for _, ds := range v.Datasets {
+		ref := runtimev1.DatasetRef{Dataset: ds.Dataset, Version: ds.Version, ConnectorKind: ds.ConnectorKind}
+		if ds.Error != nil {
+			provider[ref] = runtimev1.DatasetResult{Error: &runtimev1.DatasetError{Kind: runtimev1.DatasetErrorKind(ds.Error.Kind), Message: ds.Error.Message}}
+			continue
+		}
+		provider[ref] = runtimev1.DatasetResult{Rows: ds.Rows}
+	}
+
+	got := EvaluateRule(context.Background(), ruleset, rule, eval, provider, params)
+	if string(got.Status) != string(v.Expect.Status) {
+		t.Fatalf("expected status %q, got %q (reason: %s)", v.Expect.Status, got.Status, got.Reason)
+	}
+	if v.Expect.AffectedResourceIDs == nil {
+		return
+	}
+	ids := []string{}
+	for _, r := range got.AffectedResources {
+		ids = append(ids, r.ID)
+	}
+	if diff := cmp.Diff(*v.Expect.AffectedResourceIDs, ids, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Fatalf("affected resource IDs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,924 @@
+// Package evaluator is the hand-written reference evaluator for Open SSPM rulesets.
+//
+// It executes compiled rulesets (as emitted by osspec into descriptor.v1.json) against a
+// runtime DatasetProvider and reports one result per rule. It is deliberately separate from
+// the generated data models under gen/go: osspec generates types only, while the check
+// semantics live here so engines can share (or test against) a single interpretation.
+//
+// Semantics:
+//
+//   - Dataset errors map to check policies: missing_integration and missing_dataset use
+//     on_missing_dataset, permission_denied uses on_permission_denied, sync_failed uses
+//     on_sync_error, and engine_error is always an error. Policy "unknown" yields
+//     StatusUnknown and "error" yields StatusError.
+//   - Unset check fields take the same defaults osspec applies during normalization
+//     (on_missing_dataset=unknown, on_permission_denied=unknown, on_sync_error=error,
+//     expect.match=all, expect.on_empty=unknown, on_unmatched_left=ignore, quantifier=any).
+//   - Predicates over a missing or null value are false for every operator except absent.
+//     Ordering operators (lt/lte/gt/gte) only hold between numbers, "in" requires an array
+//     value and "contains" requires an array field. The string operators (matches,
+//     starts_with, ends_with, eq_ignore_case) only hold for string fields and require a
+//     string value; matches is an unanchored RE2 search and eq_ignore_case compares under
+//     Unicode simple case folding.
+//   - older_than and newer_than hold for RFC 3339 timestamp strings before (respectively
+//     after) the cutoff: the EvalContext's reference_time (the time evaluation starts if
+//     unset, recorded in the result) minus the ISO-8601 duration value, with calendar units
+//     applied in UTC.
+//   - A "*" path segment matches every element of an array. The comparison is applied to
+//     each value reached and combined per quantifier: any holds if one matches, all if every
+//     one does (so all holds when there are none). A value missing below the last "*"
+//     counts as missing for that element.
+//   - Predicates may be boolean groups: all_of holds if every nested predicate holds, any_of
+//     if at least one does, and not if its nested predicate does not (so not over a missing
+//     value holds).
+//   - dataset.field_compare selects rows matching all where predicates. If fewer than
+//     max(1, expect.min_selected) rows are selected the result is expect.on_empty.
+//     Otherwise assert is applied per expect.match (all, any or none).
+//   - dataset.count_compare compares the number of selected rows using check.compare.
+//   - dataset.aggregate_compare applies aggregate.function to the aggregate.path values of
+//     the selected rows, skipping missing and null values (and, except for count_distinct,
+//     non-numbers), and compares the result with check.compare, whose value (unlike for
+//     counts) may be fractional. sum of no values is 0 and
+//     count_distinct 0; min, max and avg of no values yield aggregate.on_empty.
+//   - dataset.join_count_compare filters left rows by left_path predicates and right rows by
+//     right_path predicates (a group belongs to the side its comparisons read), then counts
+//     (left, right) pairs whose key_path values are equal. Left rows without a match are
+//     ignored, counted once each, or turn the result into an error depending on
+//     on_unmatched_left.
+//   - dataset.join_field_compare joins like dataset.join_count_compare and applies expect to
+//     the selected left rows like dataset.field_compare. assert is evaluated on (left, right)
+//     pairs and holds for a left row if it holds for at least one of its matches. With
+//     on_unmatched_left=count a left row without a match is selected and paired with no
+//     right row, so its right_path values are missing; ignore skips it and error turns the
+//     result into an error. Affected resources are left rows.
+//   - In global rulesets check.connector_kind (left.connector_kind and right.connector_kind
+//     for joins) qualifies a dataset with the connector it comes from. The DatasetRef passed
+//     to the provider carries that kind and the EvalContext's connector_instances entry for
+//     it; a provider without that connector reports missing_integration.
+//   - Rules without a check or with manual.attestation are StatusUnknown. Rulesets scoped to a
+//     connector kind other than the EvalContext's, and inactive rules, are StatusNotApplicable.
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	runtimev1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/runtime/v1"
+	specv1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/spec/v1"
+	"github.com/open-sspm/open-sspm-spec/pkg/duration"
+)
+
+// Status is the dictionary enum ResultStatus.
+type Status = runtimev1.ResultStatus
+
+const (
+	StatusPass          = runtimev1.ResultStatus_PASS
+	StatusFail          = runtimev1.ResultStatus_FAIL
+	StatusUnknown       = runtimev1.ResultStatus_UNKNOWN
+	StatusError         = runtimev1.ResultStatus_ERROR
+	StatusNotApplicable = runtimev1.ResultStatus_NOT_APPLICABLE
+)
+
+type Resource struct {
+	ID      string `json:"id"`
+	Display string `json:"display,omitempty"`
+}
+
+type DatasetError struct {
+	Dataset runtimev1.DatasetRef       `json:"dataset"`
+	Kind    runtimev1.DatasetErrorKind `json:"kind"`
+	Message string                     `json:"message,omitempty"`
+}
+
+type RuleResult struct {
+	RuleKey           string         `json:"rule_key"`
+	Status            Status         `json:"status"`
+	Reason            string         `json:"reason,omitempty"`
+	AffectedResources []Resource     `json:"affected_resources,omitempty"`
+	DatasetErrors     []DatasetError `json:"dataset_errors,omitempty"`
+}
+
+type Result struct {
+	RulesetKey  string                `json:"ruleset_key"`
+	RulesetHash string                `json:"ruleset_hash"`
+	EvalContext runtimev1.EvalContext `json:"eval_context"`
+	Rules       []RuleResult          `json:"rules"`
+}
+
+// Document converts r into an opensspm.evaluation_result document.
+func (r Result) Document(startedAt, finishedAt time.Time) runtimev1.EvaluationResultDoc {
+	doc := runtimev1.EvaluationResultDoc{
+		SchemaVersion: 1,
+		Kind:          "opensspm.evaluation_result",
+		Result: runtimev1.EvaluationResult{
+			Ruleset:     runtimev1.EvaluationRuleset{Key: r.RulesetKey, Hash: r.RulesetHash},
+			EvalContext: r.EvalContext,
+			StartedAt:   startedAt.UTC(),
+			FinishedAt:  finishedAt.UTC(),
+			Rules:       make([]runtimev1.EvaluationRuleResult, 0, len(r.Rules)),
+		},
+	}
+	for _, rr := range r.Rules {
+		out := runtimev1.EvaluationRuleResult{RuleKey: rr.RuleKey, Status: rr.Status, Reason: rr.Reason}
+		for _, res := range rr.AffectedResources {
+			out.AffectedResources = append(out.AffectedResources, runtimev1.EvaluationResource{ID: res.ID, Display: res.Display})
+		}
+		for _, de := range rr.DatasetErrors {
+			out.DatasetErrors = append(out.DatasetErrors, runtimev1.EvaluationDatasetError{
+				Dataset:           de.Dataset.Dataset,
+				Version:           de.Dataset.Version,
+				ConnectorKind:     de.Dataset.ConnectorKind,
+				ConnectorInstance: de.Dataset.ConnectorInstance,
+				Kind:              de.Kind,
+				Message:           de.Message,
+			})
+		}
+		doc.Result.Rules = append(doc.Result.Rules, out)
+	}
+	return doc
+}
+
+type Options struct {
+	// Parameters overrides rule parameter defaults, keyed by rule key and then parameter name.
+	Parameters map[string]map[string]any
+}
+
+// Evaluate runs every rule of a compiled ruleset. Rule results keep the ruleset's rule order.
+func Evaluate(ctx context.Context, rs specv1.Compiled[specv1.RulesetDoc], eval runtimev1.EvalContext, provider runtimev1.DatasetProvider, opts Options) Result {
+	ruleset := rs.Object.Ruleset
+	e := newRun(eval, provider)
+	out := Result{
+		RulesetKey:  ruleset.Key,
+		RulesetHash: rs.Hash,
+		EvalContext: e.eval,
+		Rules:       make([]RuleResult, 0, len(ruleset.Rules)),
+	}
+
+	applicable, reason := scopeApplies(ruleset.Scope, eval)
+	for i := range ruleset.Rules {
+		r := &ruleset.Rules[i]
+		if !applicable {
+			out.Rules = append(out.Rules, RuleResult{RuleKey: r.Key, Status: StatusNotApplicable, Reason: reason})
+			continue
+		}
+		out.Rules = append(out.Rules, e.evaluateRule(ctx, &ruleset, r, opts.Parameters[r.Key]))
+	}
+	return out
+}
+
+// EvaluateRule runs a single rule of ruleset. params overrides the rule's parameter defaults.
+func EvaluateRule(ctx context.Context, ruleset specv1.Ruleset, rule specv1.Rule, eval runtimev1.EvalContext, provider runtimev1.DatasetProvider, params map[string]any) RuleResult {
+	if ok, reason := scopeApplies(ruleset.Scope, eval); !ok {
+		return RuleResult{RuleKey: rule.Key, Status: StatusNotApplicable, Reason: reason}
+	}
+	return newRun(eval, provider).evaluateRule(ctx, &ruleset, &rule, params)
+}
+
+func scopeApplies(s specv1.Scope, eval runtimev1.EvalContext) (bool, string) {
+	if s.Kind != specv1.ScopeKind_CONNECTOR_INSTANCE {
+		return true, ""
+	}
+	if eval.ScopeKind != runtimev1.ScopeKind_CONNECTOR_INSTANCE || eval.ConnectorKind != s.ConnectorKind {
+		return false, fmt.Sprintf("ruleset is scoped to connector kind %q", s.ConnectorKind)
+	}
+	return true, ""
+}
+
+// run holds per-evaluation state; datasets are fetched at most once per reference and
+// op=matches patterns compiled at most once.
+type run struct {
+	eval     runtimev1.EvalContext // with ReferenceTime set
+	provider runtimev1.DatasetProvider
+	datasets map[runtimev1.DatasetRef]*loadedDataset
+	patterns map[string]*regexp.Regexp
+}
+
+type loadedDataset struct {
+	rows []any
+	err  *runtimev1.DatasetError
+}
+
+func newRun(eval runtimev1.EvalContext, provider runtimev1.DatasetProvider) *run {
+	now := time.Now()
+	if eval.ReferenceTime != nil {
+		now = *eval.ReferenceTime
+	}
+	now = now.UTC()
+	eval.ReferenceTime = &now
+	return &run{
+		eval:     eval,
+		provider: provider,
+		datasets: map[runtimev1.DatasetRef]*loadedDataset{},
+		patterns: map[string]*regexp.Regexp{},
+	}
+}
+
+func (e *run) evaluateRule(ctx context.Context, rs *specv1.Ruleset, r *specv1.Rule, overrides map[string]any) RuleResult {
+	res := RuleResult{RuleKey: r.Key}
+
+	if r.Lifecycle != nil && r.Lifecycle.IsActive != nil && !*r.Lifecycle.IsActive {
+		res.Status = StatusNotApplicable
+		res.Reason = "rule is inactive"
+		return res
+	}
+	c := r.Check
+	if c == nil || c.Type == specv1.CheckType_MANUAL_ATTESTATION {
+		res.Status = StatusUnknown
+		res.Reason = "rule requires manual attestation"
+		return res
+	}
+
+	rc := &ruleContext{
+		run:     e,
+		ruleset: rs,
+		rule:    r,
+		check:   c,
+		params:  mergeParams(r.Parameters, overrides),
+		result:  &res,
+	}
+
+	var err error
+	switch c.Type {
+	case specv1.CheckType_DATASET_FIELD_COMPARE:
+		err = rc.fieldCompare(ctx)
+	case specv1.CheckType_DATASET_COUNT_COMPARE:
+		err = rc.countCompare(ctx)
+	case specv1.CheckType_DATASET_JOIN_COUNT_COMPARE:
+		err = rc.joinCountCompare(ctx)
+	case specv1.CheckType_DATASET_JOIN_FIELD_COMPARE:
+		err = rc.joinFieldCompare(ctx)
+	case specv1.CheckType_DATASET_AGGREGATE_COMPARE:
+		err = rc.aggregateCompare(ctx)
+	default:
+		err = fmt.Errorf("unsupported check.type %q", c.Type)
+	}
+	if err != nil {
+		res.Status = StatusError
+		res.Reason = err.Error()
+		res.AffectedResources = nil
+	}
+	return res
+}
+
+func mergeParams(p *specv1.Parameters, overrides map[string]any) map[string]any {
+	out := map[string]any{}
+	if p != nil {
+		for k, v := range p.Defaults {
+			out[k] = v
+		}
+	}
+	for k, v := range overrides {
+		out[k] = v
+	}
+	return out
+}
+
+type ruleContext struct {
+	*run
+	ruleset *specv1.Ruleset
+	rule    *specv1.Rule
+	check   *specv1.Check
+	params  map[string]any
+	result  *RuleResult
+}
+
+// dataset returns the rows of a dataset referenced by the check, requested from the
+// EvalContext's instance of connectorKind if the check qualifies the dataset with one. ok is
+// false when the provider reported an error; in that case the rule result has already been
+// set.
+func (rc *ruleContext) dataset(ctx context.Context, dataset, connectorKind string) ([]any, bool, error) {
+	ref := runtimev1.DatasetRef{
+		Dataset:       dataset,
+		Version:       effectiveDatasetVersion(dataset, rc.ruleset.DataContracts, rc.check.DatasetVersion),
+		ConnectorKind: connectorKind,
+	}
+	if connectorKind != "" {
+		ref.ConnectorInstance = rc.eval.ConnectorInstances[connectorKind]
+	}
+	ds, ok := rc.datasets[ref]
+	if !ok {
+		var err error
+		ds, err = rc.load(ctx, ref)
+		if err != nil {
+			return nil, false, err
+		}
+		rc.datasets[ref] = ds
+	}
+	if ds.err == nil {
+		return ds.rows, true, nil
+	}
+
+	rc.result.DatasetErrors = append(rc.result.DatasetErrors, DatasetError{Dataset: ref, Kind: ds.err.Kind, Message: ds.err.Message})
+	policy := specv1.ErrorPolicy_ERROR
+	switch ds.err.Kind {
+	case runtimev1.DatasetErrorKind_MISSING_INTEGRATION, runtimev1.DatasetErrorKind_MISSING_DATASET:
+		policy = orDefault(rc.check.OnMissingDataset, specv1.ErrorPolicy_UNKNOWN)
+	case runtimev1.DatasetErrorKind_PERMISSION_DENIED:
+		policy = orDefault(rc.check.OnPermissionDenied, specv1.ErrorPolicy_UNKNOWN)
+	case runtimev1.DatasetErrorKind_SYNC_FAILED:
+		policy = orDefault(rc.check.OnSyncError, specv1.ErrorPolicy_ERROR)
+	}
+	if policy == specv1.ErrorPolicy_UNKNOWN {
+		rc.result.Status = StatusUnknown
+	} else {
+		rc.result.Status = StatusError
+	}
+	rc.result.Reason = fmt.Sprintf("dataset %s: %s", datasetRefString(ref), ds.err.Kind)
+	return nil, false, nil
+}
+
+func (rc *ruleContext) load(ctx context.Context, ref runtimev1.DatasetRef) (*loadedDataset, error) {
+	if rc.provider == nil {
+		return &loadedDataset{err: &runtimev1.DatasetError{Kind: runtimev1.DatasetErrorKind_MISSING_INTEGRATION, Message: "no dataset provider"}}, nil
+	}
+	dr := rc.provider.GetDataset(ctx, rc.eval, ref)
+	if dr.Error != nil {
+		return &loadedDataset{err: dr.Error}, nil
+	}
+	rows := make([]any, 0, len(dr.Rows))
+	for i, raw := range dr.Rows {
+		v, err := decodeJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("dataset %s@%d: row %d: %w", ref.Dataset, ref.Version, i, err)
+		}
+		rows = append(rows, v)
+	}
+	return &loadedDataset{rows: rows}, nil
+}
+
+func (rc *ruleContext) selectRows(rows []any, where []specv1.Predicate) ([]any, error) {
+	var out []any
+	for _, row := range rows {
+		ok, err := rc.matchAll(row, where, pathOf)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (rc *ruleContext) matchAll(row any, preds []specv1.Predicate, path func(specv1.Predicate) string) (bool, error) {
+	for _, p := range preds {
+		ok, err := rc.match(row, p, path)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (rc *ruleContext) matchAny(row any, preds []specv1.Predicate, path func(specv1.Predicate) string) (bool, error) {
+	for _, p := range preds {
+		ok, err := rc.match(row, p, path)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// match evaluates p against row; path selects the field a comparison reads.
+func (rc *ruleContext) match(row any, p specv1.Predicate, path func(specv1.Predicate) string) (bool, error) {
+	switch {
+	case p.AllOf != nil:
+		return rc.matchAll(row, p.AllOf, path)
+	case p.AnyOf != nil:
+		return rc.matchAny(row, p.AnyOf, path)
+	case p.Not != nil:
+		ok, err := rc.match(row, *p.Not, path)
+		return !ok, err
+	}
+
+	var expected any
+	if p.Op != specv1.Operator_EXISTS && p.Op != specv1.Operator_ABSENT {
+		v, err := rc.operand(p.Value, p.ValueParam)
+		if err != nil {
+			return false, err
+		}
+		expected = v
+	}
+	if p.Op == specv1.Operator_OLDER_THAN || p.Op == specv1.Operator_NEWER_THAN {
+		cutoff, err := rc.cutoff(p.Op, expected)
+		if err != nil {
+			return false, err
+		}
+		expected = cutoff
+	}
+	if p.Op == specv1.Operator_MATCHES {
+		re, err := rc.pattern(expected)
+		if err != nil {
+			return false, err
+		}
+		expected = re
+	}
+	ptr := path(p)
+	if hasWildcard(ptr) {
+		// any holds at the first element that matches, all fails at the first that does not.
+		all := p.Quantifier == specv1.Quantifier_ALL
+		for _, v := range resolveWildcard(row, ptr) {
+			ok, err := evalOperator(p.Op, v.value, v.found, expected)
+			if err != nil {
+				return false, err
+			}
+			if ok != all {
+				return ok, nil
+			}
+		}
+		return all, nil
+	}
+	actual, found := resolvePointer(row, ptr)
+	return evalOperator(p.Op, actual, found, expected)
+}
+
+func (rc *ruleContext) operand(value any, valueParam string) (any, error) {
+	vp := strings.TrimSpace(valueParam)
+	if vp == "" {
+		return value, nil
+	}
+	v, ok := rc.params[vp]
+	if !ok {
+		return nil, fmt.Errorf("value_param %q has no value", vp)
+	}
+	return v, nil
+}
+
+// cutoff returns the time older_than and newer_than compare against: the reference time
+// minus the ISO-8601 duration value.
+func (rc *ruleContext) cutoff(op specv1.Operator, value any) (time.Time, error) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("op=%s requires an ISO-8601 duration value, got %T", op, value)
+	}
+	d, err := duration.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("op=%s: %w", op, err)
+	}
+	return d.Before(*rc.eval.ReferenceTime), nil
+}
+
+// pattern returns the compiled op=matches pattern value.
+func (rc *ruleContext) pattern(value any) (*regexp.Regexp, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("op=matches requires a string value, got %T", value)
+	}
+	if re, ok := rc.patterns[s]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("op=matches: %w", err)
+	}
+	rc.patterns[s] = re
+	return re, nil
+}
+
+func (rc *ruleContext) fieldCompare(ctx context.Context) error {
+	rows, ok, err := rc.dataset(ctx, rc.check.Dataset, rc.check.ConnectorKind)
+	if err != nil || !ok {
+		return err
+	}
+	selected, err := rc.selectRows(rows, rc.check.Where)
+	if err != nil {
+		return err
+	}
+	return rc.expectRows(rc.check.Dataset, selected, func(i int) (bool, error) {
+		return rc.match(selected[i], *rc.check.Assert, pathOf)
+	})
+}
+
+// expectRows applies check.expect to the selected rows of dataset: holds reports whether
+// check.assert holds for selected[i].
+func (rc *ruleContext) expectRows(dataset string, selected []any, holds func(i int) (bool, error)) error {
+	match := specv1.FieldCompareMatch_ALL
+	onEmpty := specv1.FieldCompareOnEmpty_UNKNOWN
+	minSelected := 1
+	if rc.check.Expect != nil {
+		match = orDefault(rc.check.Expect.Match, match)
+		onEmpty = orDefault(rc.check.Expect.OnEmpty, onEmpty)
+		minSelected = max(minSelected, rc.check.Expect.MinSelected)
+	}
+	if len(selected) < minSelected {
+		rc.result.Status = onEmptyStatus(onEmpty)
+		rc.result.Reason = fmt.Sprintf("%d rows selected, expected at least %d", len(selected), minSelected)
+		return nil
+	}
+	if rc.check.Assert == nil {
+		return fmt.Errorf("%s requires check.assert", rc.check.Type)
+	}
+
+	var satisfied, violated []any
+	for i, row := range selected {
+		ok, err := holds(i)
+		if err != nil {
+			return err
+		}
+		if ok {
+			satisfied = append(satisfied, row)
+		} else {
+			violated = append(violated, row)
+		}
+	}
+
+	var offending []any
+	switch match {
+	case specv1.FieldCompareMatch_ALL:
+		offending = violated
+	case specv1.FieldCompareMatch_ANY:
+		if len(satisfied) == 0 {
+			offending = selected
+		}
+	case specv1.FieldCompareMatch_NONE:
+		offending = satisfied
+	default:
+		return fmt.Errorf("unsupported expect.match %q", match)
+	}
+
+	if len(offending) == 0 {
+		rc.result.Status = StatusPass
+		return nil
+	}
+	rc.result.Status = StatusFail
+	rc.result.Reason = fmt.Sprintf("%d of %d selected rows do not meet expect.match=%s", len(offending), len(selected), match)
+	rc.result.AffectedResources = rc.affectedResources(dataset, offending)
+	return nil
+}
+
+func (rc *ruleContext) countCompare(ctx context.Context) error {
+	rows, ok, err := rc.dataset(ctx, rc.check.Dataset, rc.check.ConnectorKind)
+	if err != nil || !ok {
+		return err
+	}
+	selected, err := rc.selectRows(rows, rc.check.Where)
+	if err != nil {
+		return err
+	}
+	return rc.compareCount(len(selected))
+}
+
+func (rc *ruleContext) joinCountCompare(ctx context.Context) error {
+	leftRows, matches, ok, err := rc.join(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	count, unmatched := 0, 0
+	for i := range leftRows {
+		if len(matches[i]) == 0 {
+			unmatched++
+		}
+		count += len(matches[i])
+	}
+
+	if unmatched > 0 {
+		switch orDefault(rc.check.OnUnmatchedLeft, specv1.OnUnmatchedLeft_IGNORE) {
+		case specv1.OnUnmatchedLeft_COUNT:
+			count += unmatched
+		case specv1.OnUnmatchedLeft_ERROR:
+			rc.unmatchedLeftError(unmatched)
+			return nil
+		}
+	}
+	return rc.compareCount(count)
+}
+
+func (rc *ruleContext) joinFieldCompare(ctx context.Context) error {
+	leftRows, matches, ok, err := rc.join(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	// Unmatched left rows are selected with a nil right row when on_unmatched_left=count,
+	// so that their right_path values are missing.
+	var selected []any
+	var rights [][]any
+	unmatched := 0
+	policy := orDefault(rc.check.OnUnmatchedLeft, specv1.OnUnmatchedLeft_IGNORE)
+	for i, row := range leftRows {
+		m := matches[i]
+		if len(m) == 0 {
+			unmatched++
+			if policy != specv1.OnUnmatchedLeft_COUNT {
+				continue
+			}
+			m = []any{nil}
+		}
+		selected = append(selected, row)
+		rights = append(rights, m)
+	}
+	if unmatched > 0 && policy == specv1.OnUnmatchedLeft_ERROR {
+		rc.unmatchedLeftError(unmatched)
+		return nil
+	}
+
+	// A left row satisfies assert if at least one of its (left, right) pairs does.
+	return rc.expectRows(rc.check.Left.Dataset, selected, func(i int) (bool, error) {
+		for _, right := range rights[i] {
+			ok, err := rc.match(joinedRow(selected[i], right), *rc.check.Assert, joinedPathOf)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	})
+}
+
+// join loads both datasets of a join check and filters left rows by left_path predicates
+// and right rows by right_path predicates (a group belongs to the side its comparisons
+// read). It returns the selected left rows and, for each, the selected right rows whose
+// key_path value equals its own. ok is false when a dataset error has set the result.
+func (rc *ruleContext) join(ctx context.Context) (leftRows []any, matches [][]any, ok bool, err error) {
+	left, right := rc.check.Left, rc.check.Right
+	if left == nil || right == nil {
+		return nil, nil, false, fmt.Errorf("%s requires check.left and check.right", rc.check.Type)
+	}
+	allLeft, ok, err := rc.dataset(ctx, left.Dataset, left.ConnectorKind)
+	if err != nil || !ok {
+		return nil, nil, false, err
+	}
+	allRight, ok, err := rc.dataset(ctx, right.Dataset, right.ConnectorKind)
+	if err != nil || !ok {
+		return nil, nil, false, err
+	}
+
+	var leftWhere, rightWhere []specv1.Predicate
+	for _, p := range rc.check.Where {
+		if isLeftPredicate(p) {
+			leftWhere = append(leftWhere, p)
+		} else {
+			rightWhere = append(rightWhere, p)
+		}
+	}
+
+	rightByKey := map[string][]any{}
+	for _, row := range allRight {
+		ok, err := rc.matchAll(row, rightWhere, rightPathOf)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		if k, found := joinKey(row, right.KeyPath); found {
+			rightByKey[k] = append(rightByKey[k], row)
+		}
+	}
+
+	for _, row := range allLeft {
+		ok, err := rc.matchAll(row, leftWhere, leftPathOf)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		var m []any
+		if k, found := joinKey(row, left.KeyPath); found {
+			m = rightByKey[k]
+		}
+		leftRows = append(leftRows, row)
+		matches = append(matches, m)
+	}
+	return leftRows, matches, true, nil
+}
+
+func (rc *ruleContext) unmatchedLeftError(unmatched int) {
+	rc.result.Status = StatusError
+	rc.result.Reason = fmt.Sprintf("%d left rows have no match in %s", unmatched, rc.check.Right.Dataset)
+}
+
+func (rc *ruleContext) aggregateCompare(ctx context.Context) error {
+	a := rc.check.Aggregate
+	if a == nil {
+		return fmt.Errorf("dataset.aggregate_compare requires check.aggregate")
+	}
+	rows, ok, err := rc.dataset(ctx, rc.check.Dataset, rc.check.ConnectorKind)
+	if err != nil || !ok {
+		return err
+	}
+	selected, err := rc.selectRows(rows, rc.check.Where)
+	if err != nil {
+		return err
+	}
+
+	var values []any
+	for _, row := range selected {
+		if v, found := resolvePointer(row, a.Path); found && v != nil {
+			values = append(values, v)
+		}
+	}
+	result, ok, err := aggregate(a.Function, values)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rc.result.Status = onEmptyStatus(orDefault(a.OnEmpty, specv1.FieldCompareOnEmpty_UNKNOWN))
+		rc.result.Reason = fmt.Sprintf("%s of %s: no values in %d selected rows", a.Function, a.Path, len(selected))
+		return nil
+	}
+
+	c := rc.check.Compare
+	if c == nil {
+		return fmt.Errorf("%s requires check.compare", rc.check.Type)
+	}
+	target, err := rc.compareTarget(false)
+	if err != nil {
+		return err
+	}
+	ok, err = compareOrdered(c.Op, result, target)
+	if err != nil {
+		return err
+	}
+	if ok {
+		rc.result.Status = StatusPass
+		return nil
+	}
+	rc.result.Status = StatusFail
+	rc.result.Reason = fmt.Sprintf("%s of %s is %s, which does not satisfy %s %s", a.Function, a.Path, strconv.FormatFloat(result, 'g', -1, 64), c.Op, strconv.FormatFloat(target, 'g', -1, 64))
+	return nil
+}
+
+func (rc *ruleContext) compareCount(count int) error {
+	c := rc.check.Compare
+	if c == nil {
+		return fmt.Errorf("%s requires check.compare", rc.check.Type)
+	}
+	target, err := rc.compareTarget(true)
+	if err != nil {
+		return err
+	}
+
+	ok, err := compareOrdered(c.Op, count, int(target))
+	if err != nil {
+		return err
+	}
+	if ok {
+		rc.result.Status = StatusPass
+		return nil
+	}
+	rc.result.Status = StatusFail
+	rc.result.Reason = fmt.Sprintf("count %d does not satisfy %s %d", count, c.Op, int(target))
+	return nil
+}
+
+// compareTarget returns the value check.compare compares with: value, or the numeric
+// parameter named by value_param. Counts are compared with integers only.
+func (rc *ruleContext) compareTarget(integer bool) (float64, error) {
+	c := rc.check.Compare
+	n, ok := 0.0, false
+	if c.Value != nil {
+		n, ok = *c.Value, true
+	} else {
+		v, err := rc.operand(nil, c.ValueParam)
+		if err != nil {
+			return 0, err
+		}
+		n, ok = toNumber(v)
+	}
+	switch {
+	case !ok:
+		return 0, fmt.Errorf("check.compare value_param %q must be a number", c.ValueParam)
+	case integer && n != math.Trunc(n):
+		if c.Value != nil {
+			return 0, fmt.Errorf("check.compare value must be an integer for %s", rc.check.Type)
+		}
+		return 0, fmt.Errorf("check.compare value_param %q must be an integer", c.ValueParam)
+	}
+	return n, nil
+}
+
+func (rc *ruleContext) affectedResources(dataset string, rows []any) []Resource {
+	if rc.rule.Evidence == nil || rc.rule.Evidence.AffectedResources == nil {
+		return nil
+	}
+	ar := rc.rule.Evidence.AffectedResources
+	if ar.Dataset != dataset {
+		return nil
+	}
+	out := make([]Resource, 0, len(rows))
+	for _, row := range rows {
+		id, ok := resolvePointer(row, ar.IDField)
+		if !ok {
+			continue
+		}
+		res := Resource{ID: scalarString(id)}
+		if display, ok := resolvePointer(row, ar.DisplayField); ok {
+			res.Display = scalarString(display)
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+func onEmptyStatus(v specv1.FieldCompareOnEmpty) Status {
+	switch v {
+	case specv1.FieldCompareOnEmpty_PASS:
+		return StatusPass
+	case specv1.FieldCompareOnEmpty_FAIL:
+		return StatusFail
+	case specv1.FieldCompareOnEmpty_ERROR:
+		return StatusError
+	default:
+		return StatusUnknown
+	}
+}
+
+func compareOrdered[T int | float64](op specv1.CompareOp, a, b T) (bool, error) {
+	switch op {
+	case specv1.CompareOp_EQ:
+		return a == b, nil
+	case specv1.CompareOp_NEQ:
+		return a != b, nil
+	case specv1.CompareOp_LT:
+		return a < b, nil
+	case specv1.CompareOp_LTE:
+		return a <= b, nil
+	case specv1.CompareOp_GT:
+		return a > b, nil
+	case specv1.CompareOp_GTE:
+		return a >= b, nil
+	default:
+		return false, fmt.Errorf("unsupported check.compare op %q", op)
+	}
+}
+
+// effectiveDatasetVersion mirrors the compiler's resolution: an explicit check.dataset_version
+// wins, then a single matching ruleset.data_contracts entry, then version 1.
+func effectiveDatasetVersion(dataset string, contracts []specv1.DatasetContractRef, checkVersion int) int {
+	if checkVersion > 0 {
+		return checkVersion
+	}
+	version, matches := 0, 0
+	for _, dc := range contracts {
+		if dc.Dataset == dataset {
+			matches++
+			version = dc.Version
+		}
+	}
+	if matches == 1 {
+		return version
+	}
+	return 1
+}
+
+func orDefault[T ~string](v, def T) T {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// isLeftPredicate reports whether a join predicate filters left rows: it compares a
+// left_path, or is a group of such comparisons.
+func isLeftPredicate(p specv1.Predicate) bool {
+	switch {
+	case len(p.AllOf) > 0:
+		return isLeftPredicate(p.AllOf[0])
+	case len(p.AnyOf) > 0:
+		return isLeftPredicate(p.AnyOf[0])
+	case p.Not != nil:
+		return isLeftPredicate(*p.Not)
+	}
+	return strings.TrimSpace(p.LeftPath) != ""
+}
+
+func pathOf(p specv1.Predicate) string      { return p.Path }
+func leftPathOf(p specv1.Predicate) string  { return p.LeftPath }
+func rightPathOf(p specv1.Predicate) string { return p.RightPath }
+
+// joinedRow pairs a left row with a matched right row (nil if there is none); joinedPathOf
+// maps left_path and right_path into it.
+func joinedRow(left, right any) any {
+	return map[string]any{"left": left, "right": right}
+}
+
+func joinedPathOf(p specv1.Predicate) string {
+	if p.LeftPath != "" {
+		return "/left" + p.LeftPath
+	}
+	return "/right" + p.RightPath
+}
+
+// datasetRefString formats ref as "dataset@version", followed by the connector kind and
+// instance in parentheses for qualified datasets.
+func datasetRefString(ref runtimev1.DatasetRef) string {
+	s := fmt.Sprintf("%s@%d", ref.Dataset, ref.Version)
+	switch {
+	case ref.ConnectorInstance != "":
+		s += fmt.Sprintf(" (%s %s)", ref.ConnectorKind, ref.ConnectorInstance)
+	case ref.ConnectorKind != "":
+		s += fmt.Sprintf(" (%s)", ref.ConnectorKind)
+	}
+	return s
+}
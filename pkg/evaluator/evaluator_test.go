@@ -0,0 +1,364 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	runtimev1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/runtime/v1"
+	specv1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/spec/v1"
+)
+
+type fakeProvider map[runtimev1.DatasetRef]runtimev1.DatasetResult
+
+func (p fakeProvider) Capabilities(context.Context) []runtimev1.DatasetRef {
+	out := make([]runtimev1.DatasetRef, 0, len(p))
+	for ref := range p {
+		out = append(out, ref)
+	}
+	return out
+}
+
+func (p fakeProvider) GetDataset(_ context.Context, _ runtimev1.EvalContext, ref runtimev1.DatasetRef) runtimev1.DatasetResult {
+	if res, ok := p[ref]; ok {
+		return res
+	}
+	return runtimev1.DatasetResult{Error: &runtimev1.DatasetError{Kind: runtimev1.DatasetErrorKind_MISSING_DATASET}}
+}
+
+func rows(t *testing.T, v ...any) runtimev1.DatasetResult {
+	t.Helper()
+	out := make([]json.RawMessage, 0, len(v))
+	for _, r := range v {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("marshal row: %v", err)
+		}
+		out = append(out, b)
+	}
+	return runtimev1.DatasetResult{Rows: out}
+}
+
+func loadRepoDescriptor(t *testing.T) specv1.DescriptorV1 {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("..", "..", "dist", "descriptor.v1.json"))
+	if err != nil {
+		t.Fatalf("read descriptor: %v", err)
+	}
+	d, err := specv1.ParseDescriptorV1(b)
+	if err != nil {
+		t.Fatalf("parse descriptor: %v", err)
+	}
+	return d
+}
+
+func statusByRule(res Result) map[string]Status {
+	out := map[string]Status{}
+	for _, r := range res.Rules {
+		out[r.RuleKey] = r.Status
+	}
+	return out
+}
+
+func TestEvaluate_CISOkta(t *testing.T) {
+	d := loadRepoDescriptor(t)
+	var rs *specv1.Compiled[specv1.RulesetDoc]
+	for i := range d.Rulesets {
+		if d.Rulesets[i].Object.Ruleset.Key == "cis.okta.idaas_stig.v1" {
+			rs = &d.Rulesets[i]
+		}
+	}
+	if rs == nil {
+		t.Fatalf("missing cis.okta.idaas_stig.v1 in descriptor")
+	}
+
+	provider := fakeProvider{
+		{Dataset: "okta:policies/sign-on", Version: 1}: rows(t,
+			map[string]any{
+				"id": "r1", "name": "Strict", "priority": 1,
+				"policy":  map[string]any{"name": "Default Policy"},
+				"actions": map[string]any{"signon": map[string]any{"session": map[string]any{"maxSessionIdleMinutes": 15, "maxSessionLifetimeMinutes": 1440, "usePersistentCookie": false}}},
+			},
+			map[string]any{"id": "r2", "name": "Default Rule", "priority": 1, "policy": map[string]any{"name": "Default Policy"}},
+		),
+		{Dataset: "okta:log-streams", Version: 1}: rows(t,
+			map[string]any{"id": "ls1", "status": "INACTIVE"},
+		),
+		{Dataset: "okta:policies/password", Version: 1}: {Error: &runtimev1.DatasetError{Kind: runtimev1.DatasetErrorKind_SYNC_FAILED}},
+	}
+	eval := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_CONNECTOR_INSTANCE, ConnectorKind: "okta", ConnectorInstance: "acme"}
+
+	res := Evaluate(context.Background(), *rs, eval, provider, Options{})
+	if res.RulesetKey != "cis.okta.idaas_stig.v1" || res.RulesetHash != rs.Hash {
+		t.Fatalf("unexpected ruleset identity: %q %q", res.RulesetKey, res.RulesetHash)
+	}
+	got := statusByRule(res)
+	want := map[string]Status{
+		"OKTA-APP-000020": StatusPass,    // idle timeout is 15
+		"OKTA-APP-001665": StatusFail,    // lifetime is 1440, expected 1080
+		"OKTA-APP-001710": StatusPass,    // persistent cookie disabled
+		"OKTA-APP-001430": StatusFail,    // no ACTIVE log stream
+		"OKTA-APP-000170": StatusError,   // sync_failed -> on_sync_error=error
+		"OKTA-APP-001670": StatusUnknown, // missing_dataset -> on_missing_dataset=unknown
+		"OKTA-APP-000025": StatusUnknown, // manual attestation
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Fatalf("rule %s: expected %q, got %q", k, w, got[k])
+		}
+	}
+
+	other := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_CONNECTOR_INSTANCE, ConnectorKind: "github"}
+	for _, r := range Evaluate(context.Background(), *rs, other, provider, Options{}).Rules {
+		if r.Status != StatusNotApplicable {
+			t.Fatalf("rule %s: expected not_applicable for other connector kind, got %q", r.RuleKey, r.Status)
+		}
+	}
+}
+
+func TestEvaluateRule_FieldCompareAffectedResourcesAndParams(t *testing.T) {
+	ruleset := specv1.Ruleset{
+		Key:           "example.v1",
+		Scope:         specv1.Scope{Kind: specv1.ScopeKind_GLOBAL},
+		DataContracts: []specv1.DatasetContractRef{{Dataset: "core:users", Version: 2}},
+	}
+	rule := specv1.Rule{
+		Key:        "R1",
+		Parameters: &specv1.Parameters{Defaults: map[string]any{"max_age": float64(90)}},
+		Check: &specv1.Check{
+			Type:    specv1.CheckType_DATASET_FIELD_COMPARE,
+			Dataset: "core:users",
+			Where:   []specv1.Predicate{{Path: "/status", Op: specv1.Operator_IN, Value: []any{"ACTIVE", "LOCKED"}}},
+			Assert:  &specv1.Predicate{Path: "/password_age_days", Op: specv1.Operator_LTE, ValueParam: "max_age"},
+		},
+		Evidence: &specv1.Evidence{AffectedResources: &specv1.AffectedResources{Dataset: "core:users", IDField: "/id", DisplayField: "/email"}},
+	}
+	provider := fakeProvider{
+		{Dataset: "core:users", Version: 2}: rows(t,
+			map[string]any{"id": "u1", "email": "a@example.com", "status": "ACTIVE", "password_age_days": 10},
+			map[string]any{"id": "u2", "email": "b@example.com", "status": "LOCKED", "password_age_days": 120},
+			map[string]any{"id": "u3", "email": "c@example.com", "status": "DEPROVISIONED", "password_age_days": 400},
+			map[string]any{"id": "u4", "email": "d@example.com", "status": "ACTIVE"},
+		),
+	}
+
+	got := EvaluateRule(context.Background(), ruleset, rule, runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}, provider, nil)
+	if got.Status != StatusFail {
+		t.Fatalf("expected fail, got %+v", got)
+	}
+	want := []Resource{{ID: "u2", Display: "b@example.com"}, {ID: "u4", Display: "d@example.com"}}
+	if diff := cmp.Diff(want, got.AffectedResources); diff != "" {
+		t.Fatalf("affected resources mismatch (-want +got):\n%s", diff)
+	}
+
+	got = EvaluateRule(context.Background(), ruleset, rule, runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}, provider, map[string]any{"max_age": 500})
+	if got.Status != StatusFail || len(got.AffectedResources) != 1 || got.AffectedResources[0].ID != "u4" {
+		t.Fatalf("expected only u4 (missing field) to fail with override, got %+v", got)
+	}
+}
+
+func TestEvaluateRule_JoinCountCompareUnmatchedLeft(t *testing.T) {
+	ruleset := specv1.Ruleset{Key: "example.v1", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}
+	zero := 0.0
+	check := specv1.Check{
+		Type:    specv1.CheckType_DATASET_JOIN_COUNT_COMPARE,
+		Left:    &specv1.JoinSide{Dataset: "core:identities", KeyPath: "/email"},
+		Right:   &specv1.JoinSide{Dataset: "core:entitlement_assignments", KeyPath: "/identity/email"},
+		Where:   []specv1.Predicate{{RightPath: "/entitlement/tags", Op: specv1.Operator_CONTAINS, Value: "admin"}},
+		Compare: &specv1.Compare{Op: specv1.CompareOp_LTE, Value: &zero},
+	}
+	provider := fakeProvider{
+		{Dataset: "core:identities", Version: 1}: rows(t,
+			map[string]any{"email": "a@example.com"},
+			map[string]any{"email": "b@example.com"},
+		),
+		{Dataset: "core:entitlement_assignments", Version: 1}: rows(t,
+			map[string]any{"identity": map[string]any{"email": "a@example.com"}, "entitlement": map[string]any{"tags": []any{"read"}}},
+		),
+	}
+	eval := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}
+
+	cases := []struct {
+		policy specv1.OnUnmatchedLeft
+		want   Status
+	}{
+		{policy: specv1.OnUnmatchedLeft_IGNORE, want: StatusPass},
+		{policy: specv1.OnUnmatchedLeft_COUNT, want: StatusFail},
+		{policy: specv1.OnUnmatchedLeft_ERROR, want: StatusError},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			c := check
+			c.OnUnmatchedLeft = tc.policy
+			rule := specv1.Rule{Key: "R1", Check: &c}
+			got := EvaluateRule(context.Background(), ruleset, rule, eval, provider, nil)
+			if got.Status != tc.want {
+				t.Fatalf("expected %q, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEvaluateRule_JoinLargeIntegerKeys(t *testing.T) {
+	ruleset := specv1.Ruleset{Key: "example.v1", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}
+	rule := specv1.Rule{
+		Key: "R1",
+		Check: &specv1.Check{
+			Type:   specv1.CheckType_DATASET_JOIN_FIELD_COMPARE,
+			Left:   &specv1.JoinSide{Dataset: "core:identities", KeyPath: "/id"},
+			Right:  &specv1.JoinSide{Dataset: "core:mfa_factors", KeyPath: "/identity_id"},
+			Assert: &specv1.Predicate{RightPath: "/status", Op: specv1.Operator_EQ, Value: "ACTIVE"},
+		},
+		Evidence: &specv1.Evidence{AffectedResources: &specv1.AffectedResources{Dataset: "core:identities", IDField: "/id", DisplayField: "/id"}},
+	}
+	// Both IDs round to the same float64.
+	provider := fakeProvider{
+		{Dataset: "core:identities", Version: 1}: {Rows: []json.RawMessage{
+			json.RawMessage(`{"id":9007199254740993}`),
+			json.RawMessage(`{"id":9007199254740992}`),
+		}},
+		{Dataset: "core:mfa_factors", Version: 1}: {Rows: []json.RawMessage{
+			json.RawMessage(`{"identity_id":9007199254740993,"status":"ACTIVE"}`),
+			json.RawMessage(`{"identity_id":9007199254740992,"status":"INACTIVE"}`),
+		}},
+	}
+
+	got := EvaluateRule(context.Background(), ruleset, rule, runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}, provider, nil)
+	if got.Status != StatusFail {
+		t.Fatalf("expected fail, got %+v", got)
+	}
+	want := []Resource{{ID: "9007199254740992", Display: "9007199254740992"}}
+	if diff := cmp.Diff(want, got.AffectedResources); diff != "" {
+		t.Fatalf("affected resources mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluateRule_ConnectorQualifiedDatasets(t *testing.T) {
+	ruleset := specv1.Ruleset{Key: "example.v1", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}
+	zero := 0.0
+	rule := specv1.Rule{Key: "R1", Check: &specv1.Check{
+		Type:    specv1.CheckType_DATASET_JOIN_COUNT_COMPARE,
+		Left:    &specv1.JoinSide{Dataset: "core:users", ConnectorKind: "okta", KeyPath: "/email"},
+		Right:   &specv1.JoinSide{Dataset: "core:users", ConnectorKind: "slack", KeyPath: "/email"},
+		Where:   []specv1.Predicate{{LeftPath: "/status", Op: specv1.Operator_EQ, Value: "DEPROVISIONED"}, {RightPath: "/status", Op: specv1.Operator_EQ, Value: "ACTIVE"}},
+		Compare: &specv1.Compare{Op: specv1.CompareOp_EQ, Value: &zero},
+	}}
+	eval := runtimev1.EvalContext{
+		ScopeKind:          runtimev1.ScopeKind_GLOBAL,
+		ConnectorInstances: map[string]string{"okta": "okta-prod", "slack": "slack-main"},
+	}
+	okta := runtimev1.DatasetRef{Dataset: "core:users", Version: 1, ConnectorKind: "okta", ConnectorInstance: "okta-prod"}
+	slack := runtimev1.DatasetRef{Dataset: "core:users", Version: 1, ConnectorKind: "slack", ConnectorInstance: "slack-main"}
+	provider := fakeProvider{
+		okta: rows(t, map[string]any{"email": "a@example.com", "status": "DEPROVISIONED"}),
+	}
+
+	got := EvaluateRule(context.Background(), ruleset, rule, eval, provider, nil)
+	if got.Status != StatusUnknown || len(got.DatasetErrors) != 1 || got.DatasetErrors[0].Dataset != slack {
+		t.Fatalf("expected unknown with a dataset error for %+v, got %+v", slack, got)
+	}
+	if want := "dataset core:users@1 (slack slack-main): missing_dataset"; got.Reason != want {
+		t.Fatalf("expected reason %q, got %q", want, got.Reason)
+	}
+
+	provider[slack] = rows(t, map[string]any{"email": "a@example.com", "status": "ACTIVE"})
+	got = EvaluateRule(context.Background(), ruleset, rule, eval, provider, nil)
+	if got.Status != StatusFail {
+		t.Fatalf("expected the okta user still active in slack to fail, got %+v", got)
+	}
+}
+
+func TestEvaluateRule_PredicateGroups(t *testing.T) {
+	ruleset := specv1.Ruleset{Key: "example.v1", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}
+	rule := specv1.Rule{
+		Key: "R1",
+		Check: &specv1.Check{
+			Type:    specv1.CheckType_DATASET_FIELD_COMPARE,
+			Dataset: "core:users",
+			Where:   []specv1.Predicate{{Not: &specv1.Predicate{Path: "/status", Op: specv1.Operator_EQ, Value: "DEPROVISIONED"}}},
+			Assert: &specv1.Predicate{AnyOf: []specv1.Predicate{
+				{Path: "/mfa_enrolled", Op: specv1.Operator_EQ, Value: true},
+				{AllOf: []specv1.Predicate{
+					{Path: "/type", Op: specv1.Operator_EQ, Value: "SERVICE"},
+					{Path: "/last_login", Op: specv1.Operator_ABSENT},
+				}},
+			}},
+		},
+		Evidence: &specv1.Evidence{AffectedResources: &specv1.AffectedResources{Dataset: "core:users", IDField: "/id"}},
+	}
+	provider := fakeProvider{
+		{Dataset: "core:users", Version: 1}: rows(t,
+			map[string]any{"id": "u1", "status": "ACTIVE", "mfa_enrolled": true},
+			map[string]any{"id": "u2", "status": "ACTIVE", "type": "SERVICE"},
+			map[string]any{"id": "u3", "status": "ACTIVE", "type": "SERVICE", "last_login": "2026-01-01"},
+			map[string]any{"id": "u4", "status": "DEPROVISIONED"},
+			map[string]any{"id": "u5"},
+		),
+	}
+
+	got := EvaluateRule(context.Background(), ruleset, rule, runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}, provider, nil)
+	if got.Status != StatusFail {
+		t.Fatalf("expected fail, got %+v", got)
+	}
+	// u4 is not selected; u5 has no status, so not(status == DEPROVISIONED) selects it.
+	var ids []string
+	for _, r := range got.AffectedResources {
+		ids = append(ids, r.ID)
+	}
+	if diff := cmp.Diff([]string{"u3", "u5"}, ids); diff != "" {
+		t.Fatalf("affected resources mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluateRule_MatchesParamOverride(t *testing.T) {
+	ruleset := specv1.Ruleset{Key: "example.v1", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}
+	rule := specv1.Rule{
+		Key:        "R1",
+		Parameters: &specv1.Parameters{Defaults: map[string]any{"endpoint": `^https://`}},
+		Check: &specv1.Check{
+			Type:    specv1.CheckType_DATASET_FIELD_COMPARE,
+			Dataset: "core:log-streams",
+			Assert:  &specv1.Predicate{Path: "/url", Op: specv1.Operator_MATCHES, ValueParam: "endpoint"},
+		},
+	}
+	provider := fakeProvider{
+		{Dataset: "core:log-streams", Version: 1}: rows(t,
+			map[string]any{"id": "s1", "url": "https://siem.example.com"},
+			map[string]any{"id": "s2", "url": "http://siem.example.com"},
+		),
+	}
+	eval := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}
+
+	if got := EvaluateRule(context.Background(), ruleset, rule, eval, provider, nil); got.Status != StatusFail {
+		t.Fatalf("expected fail, got %+v", got)
+	}
+	if got := EvaluateRule(context.Background(), ruleset, rule, eval, provider, map[string]any{"endpoint": `^https?://`}); got.Status != StatusPass {
+		t.Fatalf("expected pass with override, got %+v", got)
+	}
+	// Patterns from parameter overrides are only compiled at evaluation time.
+	if got := EvaluateRule(context.Background(), ruleset, rule, eval, provider, map[string]any{"endpoint": `(`}); got.Status != StatusError {
+		t.Fatalf("expected error for an invalid pattern, got %+v", got)
+	}
+}
+
+func TestEvaluate_RecordsReferenceTime(t *testing.T) {
+	rs := specv1.Compiled[specv1.RulesetDoc]{Object: specv1.RulesetDoc{Ruleset: specv1.Ruleset{Key: "example.v1", Scope: specv1.Scope{Kind: specv1.ScopeKind_GLOBAL}}}}
+	eval := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_GLOBAL}
+
+	before := time.Now()
+	got := Evaluate(context.Background(), rs, eval, fakeProvider{}, Options{}).EvalContext.ReferenceTime
+	if got == nil || got.Before(before.Add(-time.Second)) || got.After(time.Now()) {
+		t.Fatalf("expected the evaluation start as reference_time, got %v", got)
+	}
+
+	ref := time.Date(2026, 4, 1, 0, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	eval.ReferenceTime = &ref
+	got = Evaluate(context.Background(), rs, eval, fakeProvider{}, Options{}).EvalContext.ReferenceTime
+	if got == nil || !got.Equal(ref) || got.Location() != time.UTC {
+		t.Fatalf("expected reference_time %s in UTC, got %v", ref, got)
+	}
+}
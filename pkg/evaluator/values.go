@@ -0,0 +1,382 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	specv1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/spec/v1"
+)
+
+func decodeJSON(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// resolvePointer resolves an RFC 6901 JSON pointer against a decoded JSON value.
+func resolvePointer(v any, pointer string) (any, bool) {
+	if pointer == "" {
+		return v, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	cur := v
+	for _, seg := range strings.Split(pointer[1:], "/") {
+		next, ok := step(cur, seg)
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+	return cur, true
+}
+
+// pointerValue is a value reached by a JSON pointer; found is false if it is missing.
+type pointerValue struct {
+	value any
+	found bool
+}
+
+// hasWildcard reports whether pointer has a "*" segment.
+func hasWildcard(pointer string) bool {
+	return slices.Contains(strings.Split(pointer, "/"), "*")
+}
+
+// resolveWildcard resolves a JSON pointer whose "*" segments match every element of an
+// array, and returns one value per element reached. If the value at a "*" segment is
+// missing or not an array it has no elements; a value missing below the last "*" is
+// returned as not found.
+func resolveWildcard(v any, pointer string) []pointerValue {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil
+	}
+	return resolveSegments(v, strings.Split(pointer[1:], "/"))
+}
+
+func resolveSegments(v any, segs []string) []pointerValue {
+	for i, seg := range segs {
+		if seg == "*" {
+			elems, _ := v.([]any)
+			var out []pointerValue
+			for _, e := range elems {
+				out = append(out, resolveSegments(e, segs[i+1:])...)
+			}
+			return out
+		}
+		next, ok := step(v, seg)
+		if !ok {
+			if slices.Contains(segs[i+1:], "*") {
+				return nil
+			}
+			return []pointerValue{{}}
+		}
+		v = next
+	}
+	return []pointerValue{{value: v, found: true}}
+}
+
+// step resolves one (escaped) JSON pointer segment.
+func step(v any, seg string) (any, bool) {
+	seg = strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+	switch node := v.(type) {
+	case map[string]any:
+		next, ok := node[seg]
+		return next, ok
+	case []any:
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 || i >= len(node) {
+			return nil, false
+		}
+		return node[i], true
+	default:
+		return nil, false
+	}
+}
+
+func evalOperator(op specv1.Operator, actual any, found bool, expected any) (bool, error) {
+	present := found && actual != nil
+	switch op {
+	case specv1.Operator_EXISTS:
+		return present, nil
+	case specv1.Operator_ABSENT:
+		return !present, nil
+	}
+	if !present {
+		return false, nil
+	}
+
+	switch op {
+	case specv1.Operator_EQ:
+		return jsonEqual(actual, expected), nil
+	case specv1.Operator_NEQ:
+		return !jsonEqual(actual, expected), nil
+	case specv1.Operator_LT, specv1.Operator_LTE, specv1.Operator_GT, specv1.Operator_GTE:
+		a, okA := toNumber(actual)
+		b, okB := toNumber(expected)
+		if !okA || !okB {
+			return false, nil
+		}
+		switch op {
+		case specv1.Operator_LT:
+			return a < b, nil
+		case specv1.Operator_LTE:
+			return a <= b, nil
+		case specv1.Operator_GT:
+			return a > b, nil
+		default:
+			return a >= b, nil
+		}
+	case specv1.Operator_IN:
+		list, ok := expected.([]any)
+		if !ok {
+			return false, fmt.Errorf("op=in requires an array value, got %T", expected)
+		}
+		for _, item := range list {
+			if jsonEqual(actual, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case specv1.Operator_CONTAINS:
+		list, ok := actual.([]any)
+		if !ok {
+			return false, nil
+		}
+		for _, item := range list {
+			if jsonEqual(item, expected) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case specv1.Operator_MATCHES:
+		re, ok := expected.(*regexp.Regexp)
+		if !ok {
+			return false, fmt.Errorf("op=matches requires a compiled pattern, got %T", expected)
+		}
+		got, ok := actual.(string)
+		return ok && re.MatchString(got), nil
+	case specv1.Operator_STARTS_WITH, specv1.Operator_ENDS_WITH, specv1.Operator_EQ_IGNORE_CASE:
+		want, ok := expected.(string)
+		if !ok {
+			return false, fmt.Errorf("op=%s requires a string value, got %T", op, expected)
+		}
+		got, ok := actual.(string)
+		if !ok {
+			return false, nil
+		}
+		switch op {
+		case specv1.Operator_STARTS_WITH:
+			return strings.HasPrefix(got, want), nil
+		case specv1.Operator_ENDS_WITH:
+			return strings.HasSuffix(got, want), nil
+		default:
+			return strings.EqualFold(got, want), nil
+		}
+	case specv1.Operator_OLDER_THAN, specv1.Operator_NEWER_THAN:
+		cutoff, ok := expected.(time.Time)
+		if !ok {
+			return false, fmt.Errorf("op=%s requires a cutoff time, got %T", op, expected)
+		}
+		s, ok := actual.(string)
+		if !ok {
+			return false, nil
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return false, nil
+		}
+		if op == specv1.Operator_OLDER_THAN {
+			return t.Before(cutoff), nil
+		}
+		return t.After(cutoff), nil
+	default:
+		return false, fmt.Errorf("unsupported op %q", op)
+	}
+}
+
+// aggregate applies fn to values (present and non-null). Numeric functions skip values that
+// are not numbers. ok is false if min, max or avg have no numbers to aggregate.
+func aggregate(fn specv1.AggregateFunction, values []any) (result float64, ok bool, err error) {
+	if fn == specv1.AggregateFunction_COUNT_DISTINCT {
+		seen := map[string]struct{}{}
+		for _, v := range values {
+			seen[canonicalKey(v)] = struct{}{}
+		}
+		return float64(len(seen)), true, nil
+	}
+
+	var nums []float64
+	for _, v := range values {
+		if n, isNum := toNumber(v); isNum {
+			nums = append(nums, n)
+		}
+	}
+	var sum float64
+	for _, n := range nums {
+		sum += n
+	}
+	switch fn {
+	case specv1.AggregateFunction_SUM:
+		return sum, true, nil
+	case specv1.AggregateFunction_MIN, specv1.AggregateFunction_MAX, specv1.AggregateFunction_AVG:
+		if len(nums) == 0 {
+			return 0, false, nil
+		}
+		switch fn {
+		case specv1.AggregateFunction_MIN:
+			return slices.Min(nums), true, nil
+		case specv1.AggregateFunction_MAX:
+			return slices.Max(nums), true, nil
+		default:
+			return sum / float64(len(nums)), true, nil
+		}
+	default:
+		return 0, false, fmt.Errorf("unsupported check.aggregate function %q", fn)
+	}
+}
+
+// toNumber accepts both decoded rows (json.Number) and values from the descriptor (float64).
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// integerText returns the normalized decimal text of an integer value, without
+// leading zeros or a negative zero.
+func integerText(v any) (string, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		s := string(n)
+		neg := strings.HasPrefix(s, "-")
+		s = strings.TrimPrefix(s, "-")
+		if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+			return "", false
+		}
+		s = strings.TrimLeft(s, "0")
+		if s == "" {
+			return "0", true
+		}
+		if neg {
+			s = "-" + s
+		}
+		return s, true
+	case int:
+		return strconv.Itoa(n), true
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	default:
+		return "", false
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	if na, ok := toNumber(a); ok {
+		nb, ok := toNumber(b)
+		return ok && na == nb
+	}
+	switch av := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// joinKey renders a key_path value so that equal JSON values produce equal keys.
+func joinKey(row any, pointer string) (string, bool) {
+	v, ok := resolvePointer(row, pointer)
+	if !ok || v == nil {
+		return "", false
+	}
+	return canonicalKey(v), true
+}
+
+// canonicalKey renders v so that equal JSON values (1 and 1.0 included) produce equal keys.
+// Integers are keyed by their exact text, so IDs beyond float64 precision stay distinct.
+func canonicalKey(v any) string {
+	if s, ok := integerText(v); ok {
+		return "n:" + s
+	}
+	if n, ok := toNumber(v); ok {
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "n:" + strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return "n:" + strconv.FormatFloat(n, 'g', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("v:%v", v)
+	}
+	return "j:" + string(b)
+}
+
+func scalarString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case json.Number:
+		return s.String()
+	case nil:
+		return ""
+	default:
+		b, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Sprint(s)
+		}
+		return string(b)
+	}
+}
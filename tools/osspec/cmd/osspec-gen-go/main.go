@@ -11,6 +11,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/buildinfo"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
 
@@ -29,6 +31,9 @@ func main() {
 	if req.Language != "go" {
 		fail(fmt.Errorf("unsupported language %q", req.Language))
 	}
+	if err := checkSpecVersion(req.Descriptor.Version.SpecVersion); err != nil {
+		fail(err)
+	}
 
 	specCode, err := generateSpecTypes(req)
 	if err != nil {
@@ -40,8 +45,10 @@ func main() {
 	}
 
 	resp := types.CodegenResponse{
-		SchemaVersion: 1,
-		Kind:          "opensspm.codegen_response",
+		SchemaVersion:         1,
+		Kind:                  "opensspm.codegen_response",
+		PluginVersion:         buildinfo.Version,
+		SupportedSpecVersions: supportedSpecVersions,
 		Files: []types.CodegenFile{
 			{Path: "opensspm/spec/v1/types.gen.go", Content: specCode},
 			{Path: "opensspm/runtime/v1/runtime.gen.go", Content: runtimeCode},
@@ -54,6 +61,25 @@ func main() {
 	os.Stdout.Write(out)
 }
 
+// supportedSpecVersions are the version.json spec_version values this plugin generates
+// code for.
+const supportedSpecVersions = "1.x"
+
+func checkSpecVersion(specVersion string) error {
+	c, err := semver.ParseConstraint(supportedSpecVersions)
+	if err != nil {
+		return err
+	}
+	v, err := semver.Parse(specVersion)
+	if err != nil {
+		return fmt.Errorf("invalid spec_version %q: %w", specVersion, err)
+	}
+	if !c.Check(v) {
+		return fmt.Errorf("osspec-gen-go %s supports spec versions %s, not spec_version %s", buildinfo.Version, supportedSpecVersions, specVersion)
+	}
+	return nil
+}
+
 func fail(err error) {
 	fmt.Fprintln(os.Stderr, err.Error())
 	os.Exit(1)
@@ -126,6 +152,7 @@ type RulesetDoc struct {
 
 type Ruleset struct {
 	Key               string               ` + "`json:\"key\"`" + `
+	Version           string               ` + "`json:\"version,omitempty\"`" + `
 	Name              string               ` + "`json:\"name\"`" + `
 	Scope             Scope                ` + "`json:\"scope\"`" + `
 	Source            *Source              ` + "`json:\"source,omitempty\"`" + `
@@ -177,14 +204,17 @@ type Check struct {
 	OnSyncError        ErrorPolicy ` + "`json:\"on_sync_error,omitempty\"`" + `
 	Notes              string      ` + "`json:\"notes,omitempty\"`" + `
 
-	Dataset string      ` + "`json:\"dataset,omitempty\"`" + `
-	Where   []Predicate ` + "`json:\"where,omitempty\"`" + `
+	Dataset       string      ` + "`json:\"dataset,omitempty\"`" + `
+	ConnectorKind string      ` + "`json:\"connector_kind,omitempty\"`" + `
+	Where         []Predicate ` + "`json:\"where,omitempty\"`" + `
 
 	Assert *Predicate          ` + "`json:\"assert,omitempty\"`" + `
 	Expect *FieldCompareExpect ` + "`json:\"expect,omitempty\"`" + `
 
 	Compare *Compare ` + "`json:\"compare,omitempty\"`" + `
 
+	Aggregate *Aggregate ` + "`json:\"aggregate,omitempty\"`" + `
+
 	Left            *JoinSide       ` + "`json:\"left,omitempty\"`" + `
 	Right           *JoinSide       ` + "`json:\"right,omitempty\"`" + `
 	OnUnmatchedLeft OnUnmatchedLeft ` + "`json:\"on_unmatched_left,omitempty\"`" + `
@@ -194,14 +224,19 @@ type Predicate struct {
 	Path      string ` + "`json:\"path,omitempty\"`" + `
 	LeftPath  string ` + "`json:\"left_path,omitempty\"`" + `
 	RightPath string ` + "`json:\"right_path,omitempty\"`" + `
-	Op        Operator ` + "`json:\"op\"`" + `
+	Op        Operator ` + "`json:\"op,omitempty\"`" + `
 	Value     any      ` + "`json:\"value,omitempty\"`" + `
 	ValueParam string  ` + "`json:\"value_param,omitempty\"`" + `
+	Quantifier Quantifier ` + "`json:\"quantifier,omitempty\"`" + `
+
+	AllOf []Predicate ` + "`json:\"all_of,omitempty\"`" + `
+	AnyOf []Predicate ` + "`json:\"any_of,omitempty\"`" + `
+	Not   *Predicate  ` + "`json:\"not,omitempty\"`" + `
 }
 
 type Compare struct {
 	Op        CompareOp ` + "`json:\"op\"`" + `
-	Value     *int      ` + "`json:\"value,omitempty\"`" + `
+	Value     *float64  ` + "`json:\"value,omitempty\"`" + `
 	ValueParam string   ` + "`json:\"value_param,omitempty\"`" + `
 }
 
@@ -211,14 +246,22 @@ type FieldCompareExpect struct {
 	OnEmpty     FieldCompareOnEmpty ` + "`json:\"on_empty,omitempty\"`" + `
 }
 
+type Aggregate struct {
+	Function AggregateFunction   ` + "`json:\"function\"`" + `
+	Path     string              ` + "`json:\"path\"`" + `
+	OnEmpty  FieldCompareOnEmpty ` + "`json:\"on_empty,omitempty\"`" + `
+}
+
 type JoinSide struct {
-	Dataset string ` + "`json:\"dataset\"`" + `
-	KeyPath string ` + "`json:\"key_path\"`" + `
+	Dataset       string ` + "`json:\"dataset\"`" + `
+	KeyPath       string ` + "`json:\"key_path\"`" + `
+	ConnectorKind string ` + "`json:\"connector_kind,omitempty\"`" + `
 }
 
 type DatasetRefSpec struct {
-	Dataset string ` + "`json:\"dataset\"`" + `
-	Version int    ` + "`json:\"version\"`" + `
+	Dataset       string ` + "`json:\"dataset\"`" + `
+	Version       int    ` + "`json:\"version\"`" + `
+	ConnectorKind string ` + "`json:\"connector_kind,omitempty\"`" + `
 }
 
 type FrameworkMapping struct {
@@ -317,12 +360,72 @@ type Profile struct {
 	Key         string              ` + "`json:\"key\"`" + `
 	Name        string              ` + "`json:\"name\"`" + `
 	Description string              ` + "`json:\"description,omitempty\"`" + `
+	Extends     []string            ` + "`json:\"extends,omitempty\"`" + `
 	Rulesets    []ProfileRulesetRef ` + "`json:\"rulesets\"`" + `
 }
 
 type ProfileRulesetRef struct {
-	Key     string ` + "`json:\"key\"`" + `
-	Version string ` + "`json:\"version,omitempty\"`" + `
+	Key       string         ` + "`json:\"key\"`" + `
+	Version   string         ` + "`json:\"version,omitempty\"`" + `
+	Overrides []RuleOverride ` + "`json:\"overrides,omitempty\"`" + `
+}
+
+// RuleOverride tailors one rule of a ruleset included by a profile.
+type RuleOverride struct {
+	RuleKey    string         ` + "`json:\"rule_key\"`" + `
+	Exclude    bool           ` + "`json:\"exclude,omitempty\"`" + `
+	Severity   Severity       ` + "`json:\"severity,omitempty\"`" + `
+	Parameters map[string]any ` + "`json:\"parameters,omitempty\"`" + `
+	Rationale  string         ` + "`json:\"rationale,omitempty\"`" + `
+}
+
+type ConformanceSuiteDoc struct {
+	SchemaVersion int              ` + "`json:\"schema_version\"`" + `
+	Kind          string           ` + "`json:\"kind\"`" + `
+	Suite         ConformanceSuite ` + "`json:\"suite\"`" + `
+}
+
+type ConformanceSuite struct {
+	Key         string              ` + "`json:\"key\"`" + `
+	Description string              ` + "`json:\"description,omitempty\"`" + `
+	Vectors     []ConformanceVector ` + "`json:\"vectors\"`" + `
+}
+
+type ConformanceVector struct {
+	Key         string               ` + "`json:\"key\"`" + `
+	Description string               ` + "`json:\"description,omitempty\"`" + `
+	Rule        *ConformanceRuleRef  ` + "`json:\"rule,omitempty\"`" + `
+	Check       *Check               ` + "`json:\"check,omitempty\"`" + `
+	Evidence    *Evidence            ` + "`json:\"evidence,omitempty\"`" + `
+	Parameters  map[string]any       ` + "`json:\"parameters,omitempty\"`" + `
+	ReferenceTime string ` + "`json:\"reference_time,omitempty\"`" + `
+	Datasets    []ConformanceDataset ` + "`json:\"datasets\"`" + `
+	Expect      ConformanceExpect    ` + "`json:\"expect\"`" + `
+}
+
+type ConformanceRuleRef struct {
+	RulesetKey string ` + "`json:\"ruleset_key\"`" + `
+	RuleKey    string ` + "`json:\"rule_key\"`" + `
+}
+
+type ConformanceDataset struct {
+	Dataset       string                   ` + "`json:\"dataset\"`" + `
+	Version       int                      ` + "`json:\"version\"`" + `
+	ConnectorKind string                   ` + "`json:\"connector_kind,omitempty\"`" + `
+	Rows          []json.RawMessage        ` + "`json:\"rows,omitempty\"`" + `
+	Error         *ConformanceDatasetError ` + "`json:\"error,omitempty\"`" + `
+}
+
+type ConformanceDatasetError struct {
+	Kind    DatasetErrorKind ` + "`json:\"kind\"`" + `
+	Message string           ` + "`json:\"message,omitempty\"`" + `
+}
+
+type ConformanceExpect struct {
+	Status ResultStatus ` + "`json:\"status\"`" + `
+	// AffectedResourceIDs is nil if the vector does not check affected resources; an
+	// empty list expects none.
+	AffectedResourceIDs *[]string ` + "`json:\"affected_resource_ids,omitempty\"`" + `
 }
 
 type DictionaryDoc struct {
@@ -354,12 +457,14 @@ type RequirementsIndex struct {
 
 type RulesetRequirement struct {
 	RulesetKey  string           ` + "`json:\"ruleset_key\"`" + `
+	RulesetVersion string        ` + "`json:\"ruleset_version,omitempty\"`" + `
 	Status      string           ` + "`json:\"status\"`" + `
 	Scope       Scope            ` + "`json:\"scope\"`" + `
 	Datasets    []DatasetRefSpec ` + "`json:\"datasets\"`" + `
 	CheckTypes  []CheckType      ` + "`json:\"check_types\"`" + `
 	ValueParams []string         ` + "`json:\"value_params\"`" + `
 	Rules       []RuleRequirement ` + "`json:\"rules\"`" + `
+	InactiveRules []RuleRequirement ` + "`json:\"inactive_rules,omitempty\"`" + `
 }
 
 type RuleRequirement struct {
@@ -368,6 +473,7 @@ type RuleRequirement struct {
 	Datasets    []DatasetRefSpec ` + "`json:\"datasets\"`" + `
 	CheckType   *CheckType       ` + "`json:\"check_type\"`" + `
 	ValueParams []string         ` + "`json:\"value_params\"`" + `
+	ReplacedBy  string           ` + "`json:\"replaced_by,omitempty\"`" + `
 	Monitoring  struct {
 		Status MonitoringStatus ` + "`json:\"status\"`" + `
 	} ` + "`json:\"monitoring\"`" + `
@@ -398,6 +504,11 @@ func ParseDescriptorV1(b []byte) (DescriptorV1, error) {
 	var d DescriptorV1
 	return d, json.Unmarshal(b, &d)
 }
+
+func ParseConformanceSuiteDoc(b []byte) (ConformanceSuiteDoc, error) {
+	var d ConformanceSuiteDoc
+	return d, json.Unmarshal(b, &d)
+}
 `)
 
 	formatted, err := format.Source(b.Bytes())
@@ -411,7 +522,7 @@ func generateRuntimeTypes(req types.CodegenRequest) (string, error) {
 	var b bytes.Buffer
 	b.WriteString("// Code generated by osspec-gen-go. DO NOT EDIT.\n\n")
 	b.WriteString("package v1\n\n")
-	b.WriteString("import (\n\t\"context\"\n\t\"encoding/json\"\n)\n\n")
+	b.WriteString("import (\n\t\"context\"\n\t\"encoding/json\"\n\t\"time\"\n)\n\n")
 
 	// Keep a stable copy of DatasetErrorKind constants in runtime package.
 	enums := req.Descriptor.Dictionary.Object.Dictionary.Enums
@@ -441,16 +552,40 @@ func generateRuntimeTypes(req types.CodegenRequest) (string, error) {
 		}
 		b.WriteString(")\n\n")
 	}
+	// Evaluation results (opensspm.evaluation_result) are engine output, so they live in runtime.
+	if values, ok := enums["ResultStatus"]; ok {
+		values = append([]string(nil), values...)
+		slices.Sort(values)
+		values = slices.Compact(values)
+
+		b.WriteString("type ResultStatus string\n\n")
+		b.WriteString("const (\n")
+		for _, v := range values {
+			constName := "ResultStatus_" + sanitizeGoIdent(strings.ToUpper(v))
+			b.WriteString("\t" + constName + " ResultStatus = " + quote(v) + "\n")
+		}
+		b.WriteString(")\n\n")
+	}
 
 	b.WriteString(`type EvalContext struct {
 	ScopeKind         ScopeKind ` + "`json:\"scope_kind\"`" + `
 	ConnectorKind     string    ` + "`json:\"connector_kind,omitempty\"`" + `
 	ConnectorInstance string    ` + "`json:\"connector_instance,omitempty\"`" + `
+	// ConnectorInstances maps connector kinds to the instance that serves datasets
+	// qualified with that kind (check.connector_kind) in global rulesets.
+	ConnectorInstances map[string]string ` + "`json:\"connector_instances,omitempty\"`" + `
+	// ReferenceTime is the "now" that older_than and newer_than compare against. Engines
+	// that leave it unset use the time evaluation starts.
+	ReferenceTime *time.Time ` + "`json:\"reference_time,omitempty\"`" + `
 }
 
 type DatasetRef struct {
 	Dataset string ` + "`json:\"dataset\"`" + `
 	Version int    ` + "`json:\"version\"`" + `
+	// ConnectorKind is set for datasets a global ruleset qualifies with a connector kind, and
+	// ConnectorInstance to the EvalContext's instance of that kind (if any).
+	ConnectorKind     string ` + "`json:\"connector_kind,omitempty\"`" + `
+	ConnectorInstance string ` + "`json:\"connector_instance,omitempty\"`" + `
 }
 
 type DatasetError struct {
@@ -467,6 +602,53 @@ type DatasetProvider interface {
 	Capabilities(ctx context.Context) []DatasetRef
 	GetDataset(ctx context.Context, eval EvalContext, ref DatasetRef) DatasetResult
 }
+
+type EvaluationResultDoc struct {
+	SchemaVersion int              ` + "`json:\"schema_version\"`" + `
+	Kind          string           ` + "`json:\"kind\"`" + `
+	Result        EvaluationResult ` + "`json:\"result\"`" + `
+}
+
+type EvaluationResult struct {
+	Ruleset     EvaluationRuleset      ` + "`json:\"ruleset\"`" + `
+	EvalContext EvalContext            ` + "`json:\"eval_context\"`" + `
+	StartedAt   time.Time              ` + "`json:\"started_at\"`" + `
+	FinishedAt  time.Time              ` + "`json:\"finished_at\"`" + `
+	Rules       []EvaluationRuleResult ` + "`json:\"rules\"`" + `
+}
+
+type EvaluationRuleset struct {
+	Key  string ` + "`json:\"key\"`" + `
+	Hash string ` + "`json:\"hash\"`" + `
+}
+
+type EvaluationRuleResult struct {
+	RuleKey           string                   ` + "`json:\"rule_key\"`" + `
+	Status            ResultStatus             ` + "`json:\"status\"`" + `
+	Reason            string                   ` + "`json:\"reason,omitempty\"`" + `
+	EvaluatedAt       *time.Time               ` + "`json:\"evaluated_at,omitempty\"`" + `
+	AffectedResources []EvaluationResource     ` + "`json:\"affected_resources,omitempty\"`" + `
+	DatasetErrors     []EvaluationDatasetError ` + "`json:\"dataset_errors,omitempty\"`" + `
+}
+
+type EvaluationResource struct {
+	ID      string ` + "`json:\"id\"`" + `
+	Display string ` + "`json:\"display,omitempty\"`" + `
+}
+
+type EvaluationDatasetError struct {
+	Dataset           string           ` + "`json:\"dataset\"`" + `
+	Version           int              ` + "`json:\"version\"`" + `
+	ConnectorKind     string           ` + "`json:\"connector_kind,omitempty\"`" + `
+	ConnectorInstance string           ` + "`json:\"connector_instance,omitempty\"`" + `
+	Kind              DatasetErrorKind ` + "`json:\"kind\"`" + `
+	Message           string           ` + "`json:\"message,omitempty\"`" + `
+}
+
+func ParseEvaluationResultDoc(b []byte) (EvaluationResultDoc, error) {
+	var d EvaluationResultDoc
+	return d, json.Unmarshal(b, &d)
+}
 `)
 
 	formatted, err := format.Source(b.Bytes())
@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/buildinfo"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/compiler"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diff"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/loader"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/plugin"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
@@ -25,6 +31,10 @@ func main() {
 		runBuild(os.Args[2:])
 	case "codegen":
 		runCodegen(os.Args[2:])
+	case "diff":
+		runDiff(os.Args[2:])
+	case "version":
+		fmt.Fprintln(os.Stdout, "osspec "+buildinfo.Version)
 	default:
 		usage()
 		os.Exit(2)
@@ -35,23 +45,68 @@ func usage() {
 	fmt.Fprintln(os.Stderr, "osspec: compile and validate Open SSPM JSON specs")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Usage:")
-	fmt.Fprintln(os.Stderr, "  osspec validate [--repo .]")
+	fmt.Fprintln(os.Stderr, "  osspec validate [--repo .|bundle.tar.gz|bundle.zip] [--format text|json|sarif] [--baseline dist]")
 	fmt.Fprintln(os.Stderr, "  osspec build    [--repo .] [--out dist]")
 	fmt.Fprintln(os.Stderr, "  osspec codegen  --lang go --out gen/go [--repo .]")
+	fmt.Fprintln(os.Stderr, "  osspec diff     [--repo .] [--format text|json] <old> <new>")
+	fmt.Fprintln(os.Stderr, "  osspec version")
 }
 
 func runValidate(args []string) {
 	fs := flag.NewFlagSet("validate", flag.ExitOnError)
-	repo := fs.String("repo", ".", "repo root")
+	repo := fs.String("repo", ".", "repo root, or a .tar.gz/.tgz/.zip spec bundle")
+	format := fs.String("format", "text", "output format (text, json or sarif)")
+	baseline := fs.String("baseline", "", "descriptor, dist dir or git ref whose dataset contract versions must stay compatible")
 	_ = fs.Parse(args)
 
-	ctx := context.Background()
-	_, err := compiler.Compile(ctx, compiler.Options{RepoRoot: *repo})
+	if *format != "text" && *format != "json" && *format != "sarif" {
+		fmt.Fprintf(os.Stderr, "validate: unknown --format %q (want text, json or sarif)\n", *format)
+		os.Exit(2)
+	}
+
+	src, err := loader.Open(*repo)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
-	fmt.Fprintln(os.Stdout, "ok")
+
+	ctx := context.Background()
+	opts := compiler.Options{FS: src}
+	if *baseline != "" {
+		if opts.Baseline, err = loadDescriptor(ctx, *repo, *baseline); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+	_, err = compiler.Compile(ctx, opts)
+	if *format == "text" {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, "ok")
+		return
+	}
+
+	diags := diag.FromError(err)
+	if diags == nil {
+		diags = diag.List{}
+	}
+	var out any = struct {
+		Diagnostics diag.List `json:"diagnostics"`
+	}{diags}
+	if *format == "sarif" {
+		out = diag.SARIF(diags, buildinfo.Version)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(out); encErr != nil {
+		fmt.Fprintln(os.Stderr, encErr.Error())
+		os.Exit(1)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
 }
 
 func runBuild(args []string) {
@@ -95,10 +150,11 @@ func runCodegen(args []string) {
 	}
 
 	req := types.CodegenRequest{
-		SchemaVersion: 1,
-		Kind:          "opensspm.codegen_request",
-		Language:      *lang,
-		Descriptor:    res.Descriptor,
+		SchemaVersion:    1,
+		Kind:             "opensspm.codegen_request",
+		Language:         *lang,
+		GeneratorVersion: buildinfo.Version,
+		Descriptor:       res.Descriptor,
 	}
 
 	r := plugin.Runner{RepoRoot: repoAbs}
@@ -115,6 +171,94 @@ func runCodegen(args []string) {
 	fmt.Fprintf(os.Stdout, "generated %d files\n", len(resp.Files))
 }
 
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	repo := fs.String("repo", ".", "git repository used to resolve refs")
+	format := fs.String("format", "text", "output format (text or json)")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "diff requires <old> and <new> (descriptor files, spec trees, dist dirs or git refs)")
+		os.Exit(2)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "diff: unknown --format %q (want text or json)\n", *format)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	var descs [2]*types.DescriptorV1
+	for i, arg := range fs.Args() {
+		d, err := loadDescriptor(ctx, *repo, arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		descs[i] = d
+	}
+
+	report, err := diff.Descriptors(descs[0], descs[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// loadDescriptor resolves a diff argument: a descriptor JSON file, a dist dir holding
+// descriptor.v1.json, a spec tree (directory or bundle) that is compiled, or otherwise a git
+// ref whose dist/descriptor.v1.json is read from repo.
+func loadDescriptor(ctx context.Context, repo, arg string) (*types.DescriptorV1, error) {
+	var b []byte
+	info, err := os.Stat(arg)
+	switch {
+	case err == nil && !info.IsDir() && strings.HasSuffix(arg, ".json"):
+		if b, err = os.ReadFile(arg); err != nil {
+			return nil, err
+		}
+	case err == nil && fileExists(filepath.Join(arg, "descriptor.v1.json")):
+		if b, err = os.ReadFile(filepath.Join(arg, "descriptor.v1.json")); err != nil {
+			return nil, err
+		}
+	case err == nil:
+		src, err := loader.Open(arg)
+		if err != nil {
+			return nil, err
+		}
+		res, err := compiler.Compile(ctx, compiler.Options{FS: src})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		return &res.Descriptor, nil
+	default:
+		cmd := exec.CommandContext(ctx, "git", "-C", repo, "show", arg+":dist/descriptor.v1.json")
+		cmd.Stderr = os.Stderr
+		if b, err = cmd.Output(); err != nil {
+			return nil, fmt.Errorf("%q is not a file, directory or git ref with dist/descriptor.v1.json: %w", arg, err)
+		}
+	}
+	var d types.DescriptorV1
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("%s: decode descriptor: %w", arg, err)
+	}
+	return &d, nil
+}
+
+func fileExists(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && !info.IsDir()
+}
+
 func writeGeneratedFiles(repoRootAbs, outDir string, files []types.CodegenFile) error {
 	outAbs := outDir
 	if !filepath.IsAbs(outAbs) {
@@ -0,0 +1,8 @@
+// Package buildinfo holds the version of osspec and its codegen plugins.
+package buildinfo
+
+// Version is the osspec version, compared against version.json generator_min_version and
+// exchanged with codegen plugins. Release builds may set it at link time:
+//
+//	go build -ldflags "-X github.com/open-sspm/open-sspm-spec/tools/osspec/internal/buildinfo.Version=0.2.1" ./tools/osspec/cmd/osspec
+var Version = "0.2.0"
@@ -5,36 +5,48 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/buildinfo"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/hash"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/loader"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/normalize"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/schemasem"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
 
 type Options struct {
 	RepoRoot string
+	// FS is the source tree holding version.json, dictionary.json, the metaschema and
+	// specs. If nil, os.DirFS(RepoRoot) is used. Build always writes under RepoRoot.
+	FS fs.FS
 
 	SpecsDir     string
 	MetaschemaDir string
 	DistDir      string
+
+	// Baseline is a previously published descriptor. If set, dataset contract versions it
+	// contains must not change incompatibly.
+	Baseline *types.DescriptorV1
 }
 
 type Result struct {
 	Descriptor   types.DescriptorV1
 	Artifacts    types.ArtifactsIndex
 	Requirements types.RequirementsIndex
+	Conformance  []types.Compiled[types.ConformanceSuiteDoc]
+	// EffectiveRulesets maps a profile key to the rulesets it includes, with the profile's
+	// rule overrides applied.
+	EffectiveRulesets map[string][]types.Compiled[types.RulesetDoc]
 }
 
 func Compile(ctx context.Context, opts Options) (*Result, error) {
-	if opts.RepoRoot == "" {
-		return nil, errors.New("compiler: RepoRoot is required")
-	}
 	if opts.SpecsDir == "" {
 		opts.SpecsDir = "specs"
 	}
@@ -42,32 +54,41 @@ func Compile(ctx context.Context, opts Options) (*Result, error) {
 		opts.MetaschemaDir = "metaschema"
 	}
 
-	repoRootAbs, err := filepath.Abs(opts.RepoRoot)
-	if err != nil {
-		return nil, err
+	fsys := opts.FS
+	if fsys == nil {
+		if opts.RepoRoot == "" {
+			return nil, errors.New("compiler: FS or RepoRoot is required")
+		}
+		repoRootAbs, err := filepath.Abs(opts.RepoRoot)
+		if err != nil {
+			return nil, err
+		}
+		fsys = os.DirFS(repoRootAbs)
 	}
 
-	reg, err := schemasem.LoadRegistry(filepath.Join(repoRootAbs, opts.MetaschemaDir))
+	reg, err := schemasem.LoadRegistryFS(fsys, filepath.ToSlash(opts.MetaschemaDir))
 	if err != nil {
 		return nil, err
 	}
 
-	version, versionHash, err := loadVersion(repoRootAbs)
+	version, versionHash, err := loadVersion(fsys)
 	if err != nil {
 		return nil, err
 	}
 
-	dictPath := filepath.Join(repoRootAbs, "dictionary.json")
-	dictBytes, err := os.ReadFile(dictPath)
+	// Diagnostics are collected across all inputs; files with errors are left out of the
+	// bundle so that semantic validation only sees well-formed documents.
+	var errs diag.List
+
+	dictBytes, err := fs.ReadFile(fsys, "dictionary.json")
 	if err != nil {
 		return nil, fmt.Errorf("compiler: read dictionary.json: %w", err)
 	}
 	var dictDoc types.DictionaryDoc
-	if err := json.Unmarshal(dictBytes, &dictDoc); err != nil {
-		return nil, fmt.Errorf("compiler: parse dictionary.json: %w", err)
-	}
-	if err := reg.ValidateKindJSON(dictDoc.Kind, dictBytes); err != nil {
-		return nil, fmt.Errorf("dictionary.json: %w", err)
+	if d := decodeDoc("dictionary.json", dictBytes, "dictionary", &dictDoc); d != nil {
+		errs = append(errs, d)
+	} else if err := reg.ValidateKindJSON(dictDoc.Kind, dictBytes); err != nil {
+		errs = append(errs, diag.FromError(inFile(err, "dictionary.json", dictBytes))...)
 	}
 	normalize.DictionaryDoc(&dictDoc)
 	dictHash, _, err := hash.HashObjectJCS(dictDoc)
@@ -75,75 +96,34 @@ func Compile(ctx context.Context, opts Options) (*Result, error) {
 		return nil, err
 	}
 
-	specFiles, err := loader.LoadSpecFiles(ctx, loader.Options{RepoRoot: repoRootAbs, SpecsDir: opts.SpecsDir})
-	if err != nil {
-		return nil, err
-	}
-
 	var bundle schemasem.Bundle
 	bundle.Version = version
 	bundle.Dictionary.Path = "dictionary.json"
 	bundle.Dictionary.Doc = dictDoc
+	bundle.Sources = map[string][]byte{}
+	bundle.Incomplete = map[string]bool{}
+	bundle.Baseline = opts.Baseline
 
-	for _, f := range specFiles {
-		var hdr types.Header
-		if err := json.Unmarshal(f.Bytes, &hdr); err != nil {
-			return nil, fmt.Errorf("%s: parse header: %w", f.RelPath, err)
-		}
-		if hdr.SchemaVersion != 1 {
-			return nil, fmt.Errorf("%s: unsupported schema_version %d", f.RelPath, hdr.SchemaVersion)
-		}
-		if err := reg.ValidateKindJSON(hdr.Kind, f.Bytes); err != nil {
-			return nil, fmt.Errorf("%s: %w", f.RelPath, err)
+	specFiles, err := loader.LoadSpecFiles(ctx, loader.Options{FS: fsys, SpecsDir: opts.SpecsDir})
+	if err != nil {
+		var loadErrs diag.List
+		if !errors.As(err, &loadErrs) {
+			return nil, err
 		}
+		errs = append(errs, loadErrs...)
+		markIncomplete(&bundle, "")
+	}
 
-		switch hdr.Kind {
-		case "opensspm.ruleset":
-			var doc types.RulesetDoc
-			if err := json.Unmarshal(f.Bytes, &doc); err != nil {
-				return nil, fmt.Errorf("%s: parse ruleset: %w", f.RelPath, err)
-			}
-			normalize.RulesetDoc(&doc)
-			bundle.Rulesets = append(bundle.Rulesets, struct {
-				Path string
-				Doc  types.RulesetDoc
-			}{Path: f.RelPath, Doc: doc})
-		case "opensspm.dataset_contract":
-			var doc types.DatasetContractDoc
-			if err := json.Unmarshal(f.Bytes, &doc); err != nil {
-				return nil, fmt.Errorf("%s: parse dataset_contract: %w", f.RelPath, err)
-			}
-			bundle.DatasetContracts = append(bundle.DatasetContracts, struct {
-				Path string
-				Doc  types.DatasetContractDoc
-			}{Path: f.RelPath, Doc: doc})
-		case "opensspm.connector_manifest":
-			var doc types.ConnectorManifestDoc
-			if err := json.Unmarshal(f.Bytes, &doc); err != nil {
-				return nil, fmt.Errorf("%s: parse connector_manifest: %w", f.RelPath, err)
-			}
-			normalize.ConnectorManifestDoc(&doc)
-			bundle.Connectors = append(bundle.Connectors, struct {
-				Path string
-				Doc  types.ConnectorManifestDoc
-			}{Path: f.RelPath, Doc: doc})
-		case "opensspm.profile":
-			var doc types.ProfileDoc
-			if err := json.Unmarshal(f.Bytes, &doc); err != nil {
-				return nil, fmt.Errorf("%s: parse profile: %w", f.RelPath, err)
-			}
-			normalize.ProfileDoc(&doc)
-			bundle.Profiles = append(bundle.Profiles, struct {
-				Path string
-				Doc  types.ProfileDoc
-			}{Path: f.RelPath, Doc: doc})
-		default:
-			return nil, fmt.Errorf("%s: unknown kind %q", f.RelPath, hdr.Kind)
+	for _, f := range specFiles {
+		bundle.Sources[f.RelPath] = f.Bytes
+		if fileErrs := addSpecFile(reg, &bundle, f, opts.SpecsDir); len(fileErrs) > 0 {
+			errs = append(errs, fileErrs...)
 		}
 	}
 
-	if semErrs := schemasem.ValidateSemantic(&bundle); len(semErrs) > 0 {
-		return nil, joinErrors(semErrs)
+	errs = append(errs, schemasem.ValidateSemantic(&bundle)...)
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
 	reqIndex := buildRequirements(&bundle)
@@ -177,7 +157,7 @@ func Compile(ctx context.Context, opts Options) (*Result, error) {
 			return nil, fmt.Errorf("%s: hash: %w", rs.Path, err)
 		}
 		desc.Rulesets = append(desc.Rulesets, types.Compiled[types.RulesetDoc]{SourcePath: rs.Path, Hash: h, Object: rs.Doc})
-		artifactsIndex.Artifacts = append(artifactsIndex.Artifacts, types.Artifact{Kind: rs.Doc.Kind, Key: rs.Doc.Ruleset.Key, SourcePath: rs.Path, Hash: h})
+		artifactsIndex.Artifacts = append(artifactsIndex.Artifacts, types.Artifact{Kind: rs.Doc.Kind, Key: rs.Doc.Ruleset.ID(), SourcePath: rs.Path, Hash: h})
 	}
 	for _, dc := range bundle.DatasetContracts {
 		h, _, err := hash.HashObjectJCS(dc.Doc)
@@ -195,14 +175,34 @@ func Compile(ctx context.Context, opts Options) (*Result, error) {
 		desc.Connectors = append(desc.Connectors, types.Compiled[types.ConnectorManifestDoc]{SourcePath: c.Path, Hash: h, Object: c.Doc})
 		artifactsIndex.Artifacts = append(artifactsIndex.Artifacts, types.Artifact{Kind: c.Doc.Kind, Key: c.Doc.Connector.Kind, SourcePath: c.Path, Hash: h})
 	}
+	// Profiles are compiled flattened: inherited rulesets and overrides are merged in, and
+	// ruleset version constraints are resolved to the selected version.
+	flat := schemasem.FlattenProfiles(&bundle)
 	for _, p := range bundle.Profiles {
-		h, _, err := hash.HashObjectJCS(p.Doc)
+		doc := p.Doc
+		doc.Profile = flat[p.Doc.Profile.Key]
+		doc.Profile.Rulesets = slices.Clone(doc.Profile.Rulesets)
+		for i, ref := range doc.Profile.Rulesets {
+			if rs := schemasem.ResolveRuleset(&bundle, ref.Key, ref.Version); rs != nil && rs.Ruleset.Version != "" {
+				doc.Profile.Rulesets[i].Version = rs.Ruleset.Version
+			}
+		}
+		h, _, err := hash.HashObjectJCS(doc)
 		if err != nil {
 			return nil, fmt.Errorf("%s: hash: %w", p.Path, err)
 		}
-		desc.Profiles = append(desc.Profiles, types.Compiled[types.ProfileDoc]{SourcePath: p.Path, Hash: h, Object: p.Doc})
+		desc.Profiles = append(desc.Profiles, types.Compiled[types.ProfileDoc]{SourcePath: p.Path, Hash: h, Object: doc})
 		artifactsIndex.Artifacts = append(artifactsIndex.Artifacts, types.Artifact{Kind: p.Doc.Kind, Key: p.Doc.Profile.Key, SourcePath: p.Path, Hash: h})
 	}
+	var conformance []types.Compiled[types.ConformanceSuiteDoc]
+	for _, s := range bundle.Conformance {
+		h, _, err := hash.HashObjectJCS(s.Doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: hash: %w", s.Path, err)
+		}
+		conformance = append(conformance, types.Compiled[types.ConformanceSuiteDoc]{SourcePath: s.Path, Hash: h, Object: s.Doc})
+		artifactsIndex.Artifacts = append(artifactsIndex.Artifacts, types.Artifact{Kind: s.Doc.Kind, Key: s.Doc.Suite.Key, SourcePath: s.Path, Hash: h})
+	}
 
 	slices.SortFunc(artifactsIndex.Artifacts, func(a, b types.Artifact) int {
 		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
@@ -212,15 +212,22 @@ func Compile(ctx context.Context, opts Options) (*Result, error) {
 	})
 	desc.Index.Artifacts = artifactsIndex
 
+	effective, err := buildEffectiveRulesets(&bundle, desc.Profiles)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Result{
-		Descriptor:   desc,
-		Artifacts:    artifactsIndex,
-		Requirements: reqIndex,
+		Descriptor:        desc,
+		Artifacts:         artifactsIndex,
+		Requirements:      reqIndex,
+		Conformance:       conformance,
+		EffectiveRulesets: effective,
 	}, nil
 }
 
-func loadVersion(repoRootAbs string) (types.Version, string, error) {
-	b, err := os.ReadFile(filepath.Join(repoRootAbs, "version.json"))
+func loadVersion(fsys fs.FS) (types.Version, string, error) {
+	b, err := fs.ReadFile(fsys, "version.json")
 	if err != nil {
 		return types.Version{}, "", fmt.Errorf("compiler: read version.json: %w", err)
 	}
@@ -231,6 +238,9 @@ func loadVersion(repoRootAbs string) (types.Version, string, error) {
 	if v.Project == "" || v.Repo == "" || v.SpecVersion == "" || v.SchemaVersion != 1 {
 		return types.Version{}, "", fmt.Errorf("compiler: invalid version.json (missing required fields)")
 	}
+	if err := checkGeneratorVersion(v.GeneratorMinVersion, buildinfo.Version); err != nil {
+		return types.Version{}, "", err
+	}
 	h, _, err := hash.HashObjectJCS(v)
 	if err != nil {
 		return types.Version{}, "", err
@@ -238,13 +248,150 @@ func loadVersion(repoRootAbs string) (types.Version, string, error) {
 	return v, h, nil
 }
 
-func joinErrors(errs []error) error {
-	var b strings.Builder
-	b.WriteString("validation failed:\n")
-	for _, e := range errs {
-		b.WriteString(" - ")
-		b.WriteString(e.Error())
-		b.WriteString("\n")
+// checkGeneratorVersion returns an error if osspec version current is older than the
+// repo's generator_min_version. An empty minimum accepts any version.
+func checkGeneratorVersion(minimum, current string) error {
+	if minimum == "" {
+		return nil
+	}
+	minV, err := semver.Parse(minimum)
+	if err != nil {
+		return fmt.Errorf("compiler: invalid version.json generator_min_version %q: %w", minimum, err)
+	}
+	cur, err := semver.Parse(current)
+	if err != nil {
+		return fmt.Errorf("compiler: invalid osspec version %q: %w", current, err)
+	}
+	if semver.Compare(cur, minV) < 0 {
+		return fmt.Errorf("compiler: this repo requires osspec %s or newer (version.json generator_min_version), but this is osspec %s; upgrade osspec", minV, cur)
+	}
+	return nil
+}
+
+// addSpecFile parses, schema-validates and normalizes f and adds it to b. Files with
+// errors are not added; their kind is marked incomplete so that references to them are
+// not also reported as unresolved.
+func addSpecFile(reg *schemasem.Registry, b *schemasem.Bundle, f loader.LoadedFile, specsDir string) diag.List {
+	var hdr types.Header
+	if d := decodeDoc(f.RelPath, f.Bytes, "header", &hdr); d != nil {
+		markIncomplete(b, "")
+		return diag.List{d}
+	}
+	if hdr.SchemaVersion != 1 {
+		markIncomplete(b, hdr.Kind)
+		d := diag.New(diag.CodeUnsupportedSchemaVersion, f.RelPath, "/schema_version", "unsupported schema_version %d", hdr.SchemaVersion)
+		d.Locate(f.Bytes)
+		return diag.List{d}
+	}
+	if hdr.Kind == "opensspm.evaluation_result" {
+		d := diag.New(diag.CodeUnknownKind, f.RelPath, "/kind", "%s documents are engine output and must not be placed under %s", hdr.Kind, specsDir)
+		d.Locate(f.Bytes)
+		return diag.List{d}
+	}
+	if err := reg.ValidateKindJSON(hdr.Kind, f.Bytes); err != nil {
+		markIncomplete(b, hdr.Kind)
+		return diag.FromError(inFile(err, f.RelPath, f.Bytes))
+	}
+
+	switch hdr.Kind {
+	case "opensspm.ruleset":
+		var doc types.RulesetDoc
+		if d := decodeDoc(f.RelPath, f.Bytes, "ruleset", &doc); d != nil {
+			markIncomplete(b, hdr.Kind)
+			return diag.List{d}
+		}
+		normalize.RulesetDoc(&doc)
+		b.Rulesets = append(b.Rulesets, struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{Path: f.RelPath, Doc: doc})
+	case "opensspm.dataset_contract":
+		var doc types.DatasetContractDoc
+		if d := decodeDoc(f.RelPath, f.Bytes, "dataset_contract", &doc); d != nil {
+			markIncomplete(b, hdr.Kind)
+			return diag.List{d}
+		}
+		b.DatasetContracts = append(b.DatasetContracts, struct {
+			Path string
+			Doc  types.DatasetContractDoc
+		}{Path: f.RelPath, Doc: doc})
+	case "opensspm.connector_manifest":
+		var doc types.ConnectorManifestDoc
+		if d := decodeDoc(f.RelPath, f.Bytes, "connector_manifest", &doc); d != nil {
+			markIncomplete(b, hdr.Kind)
+			return diag.List{d}
+		}
+		normalize.ConnectorManifestDoc(&doc)
+		b.Connectors = append(b.Connectors, struct {
+			Path string
+			Doc  types.ConnectorManifestDoc
+		}{Path: f.RelPath, Doc: doc})
+	case "opensspm.profile":
+		var doc types.ProfileDoc
+		if d := decodeDoc(f.RelPath, f.Bytes, "profile", &doc); d != nil {
+			markIncomplete(b, hdr.Kind)
+			return diag.List{d}
+		}
+		normalize.ProfileDoc(&doc)
+		b.Profiles = append(b.Profiles, struct {
+			Path string
+			Doc  types.ProfileDoc
+		}{Path: f.RelPath, Doc: doc})
+	case "opensspm.conformance_suite":
+		var doc types.ConformanceSuiteDoc
+		if d := decodeDoc(f.RelPath, f.Bytes, "conformance_suite", &doc); d != nil {
+			markIncomplete(b, hdr.Kind)
+			return diag.List{d}
+		}
+		normalize.ConformanceSuiteDoc(&doc)
+		b.Conformance = append(b.Conformance, struct {
+			Path string
+			Doc  types.ConformanceSuiteDoc
+		}{Path: f.RelPath, Doc: doc})
+	default:
+		// Kinds without a registered schema are rejected by ValidateKindJSON.
+		d := diag.New(diag.CodeUnknownKind, f.RelPath, "/kind", "unknown kind %q", hdr.Kind)
+		d.Locate(f.Bytes)
+		return diag.List{d}
+	}
+	return nil
+}
+
+// markIncomplete records that a document of kind failed to load. An empty kind (the
+// document's kind is unknown) marks every kind.
+func markIncomplete(b *schemasem.Bundle, kind string) {
+	if kind != "" {
+		b.Incomplete[kind] = true
+		return
+	}
+	for _, ks := range schemasem.KnownSchemas {
+		b.Incomplete[ks.Kind] = true
+	}
+}
+
+// decodeDoc unmarshals src into v, reporting failures as a positioned diagnostic.
+func decodeDoc(file string, src []byte, what string, v any) *diag.Diagnostic {
+	err := json.Unmarshal(src, v)
+	if err == nil {
+		return nil
+	}
+	d := diag.New(diag.CodeInvalidJSON, file, "", "parse %s: %v", what, err)
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		d.Line, d.Column = diag.OffsetPosition(src, int(se.Offset))
+	case errors.As(err, &te):
+		d.Line, d.Column = diag.OffsetPosition(src, int(te.Offset))
+	}
+	return d
+}
+
+// inFile attributes schema diagnostics to file and positions them in src.
+func inFile(err error, file string, src []byte) error {
+	var l diag.List
+	if errors.As(err, &l) {
+		return l.InFile(file, src)
 	}
-	return errors.New(strings.TrimSpace(b.String()))
+	return fmt.Errorf("%s: %w", file, err)
 }
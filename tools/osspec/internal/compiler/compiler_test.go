@@ -2,8 +2,14 @@ package compiler
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/buildinfo"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
 )
 
@@ -14,3 +20,85 @@ func TestCompile_RepoExamples(t *testing.T) {
 	}
 }
 
+func TestCompile_ConformanceFixtureOrder(t *testing.T) {
+	const (
+		src    = "specs/conformance/join_field_compare.json"
+		vector = "cross_connector.deprovisioned"
+	)
+	suiteHash := func(repo string) string {
+		t.Helper()
+		res, err := Compile(context.Background(), Options{RepoRoot: repo})
+		if err != nil {
+			t.Fatalf("Compile() error: %v", err)
+		}
+		for _, a := range res.Artifacts.Artifacts {
+			if a.SourcePath == src {
+				return a.Hash
+			}
+		}
+		t.Fatalf("no artifact for %s", src)
+		return ""
+	}
+	want := suiteHash(testutil.RepoRoot(t))
+
+	// The vector's okta and slack fixtures share a dataset and version; reversing them
+	// must not change the compiled suite.
+	repo := copyRepo(t)
+	p := filepath.Join(repo, filepath.FromSlash(src))
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, v := range doc["suite"].(map[string]any)["vectors"].([]any) {
+		v := v.(map[string]any)
+		if v["key"] == vector {
+			slices.Reverse(v["datasets"].([]any))
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("%s: vector %q not found", src, vector)
+	}
+	if b, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := suiteHash(repo); got != want {
+		t.Fatalf("compiled suite hash = %s, want %s", got, want)
+	}
+}
+
+func TestCompile_GeneratorMinVersion(t *testing.T) {
+	repo := copyRepo(t)
+	replaceFirst(t, repo, "version.json", `"generator_min_version": "0.1.0"`, `"generator_min_version": "99.0.0"`)
+	_, err := Compile(context.Background(), Options{RepoRoot: repo})
+	if err == nil || !strings.Contains(err.Error(), "this repo requires osspec 99.0.0 or newer (version.json generator_min_version), but this is osspec "+buildinfo.Version) {
+		t.Fatalf("Compile() error = %v, want generator_min_version error", err)
+	}
+}
+
+func TestCheckGeneratorVersion(t *testing.T) {
+	for _, tt := range []struct {
+		minimum, current string
+		ok               bool
+	}{
+		{"", "0.1.0", true},
+		{"0.2.0", "0.2.0", true},
+		{"0.2.0", "0.10.0", true},
+		{"0.2.0", "0.2.0-rc.1", false},
+		{"1.0.0", "0.9.9", false},
+		{"latest", "0.2.0", false},
+	} {
+		if err := checkGeneratorVersion(tt.minimum, tt.current); (err == nil) != tt.ok {
+			t.Errorf("checkGeneratorVersion(%q, %q) = %v, want ok=%v", tt.minimum, tt.current, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
+)
+
+// copyRepo copies the inputs Compile reads into a temporary repo root.
+func copyRepo(t *testing.T) string {
+	t.Helper()
+	root := testutil.RepoRoot(t)
+	dst := t.TempDir()
+	for _, name := range []string{"version.json", "dictionary.json"} {
+		b, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, name), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"metaschema", "specs"} {
+		if err := os.CopyFS(filepath.Join(dst, dir), os.DirFS(filepath.Join(root, dir))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dst
+}
+
+// replaceFirst replaces the first occurrence of old in the repo file and returns its
+// 1-based line.
+func replaceFirst(t *testing.T, repo, rel, old, new string) int {
+	t.Helper()
+	p := filepath.Join(repo, filepath.FromSlash(rel))
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	off := strings.Index(s, old)
+	if off < 0 {
+		t.Fatalf("%s: %q not found", rel, old)
+	}
+	if err := os.WriteFile(p, []byte(strings.Replace(s, old, new, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(s[:off], "\n") + 1
+}
+
+func TestCompile_DiagnosticsHaveSourcePositions(t *testing.T) {
+	const rel = "specs/rulesets/cis/okta/cis.okta.idaas_stig.v1.json"
+
+	cases := []struct {
+		name     string
+		old, new string
+		code     string
+		pointer  string
+	}{
+		{
+			name:    "schema",
+			old:     `{ "path": "/priority", "op": "eq"`,
+			new:     `{ "path": "/priority", "op": "equals"`,
+			code:    diag.CodeSchema,
+			pointer: "/ruleset/rules/0/check/where/1/op",
+		},
+		{
+			name:    "semantic",
+			old:     `{ "path": "/priority", "op": "eq"`,
+			new:     `{ "path": "/priorty", "op": "eq"`,
+			code:    diag.CodeUnknownPath,
+			pointer: "/ruleset/rules/0/check/where/1/path",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := copyRepo(t)
+			line := replaceFirst(t, repo, rel, tc.old, tc.new)
+
+			_, err := Compile(context.Background(), Options{RepoRoot: repo})
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			diags := diag.FromError(err)
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %d: %v", len(diags), err)
+			}
+			d := diags[0]
+			if d.Code != tc.code || d.File != rel || d.Pointer != tc.pointer {
+				t.Fatalf("got code=%s file=%s pointer=%s, want code=%s file=%s pointer=%s", d.Code, d.File, d.Pointer, tc.code, rel, tc.pointer)
+			}
+			if d.Line != line || d.Column == 0 {
+				t.Fatalf("got position %d:%d, want line %d", d.Line, d.Column, line)
+			}
+		})
+	}
+}
+
+func TestCompile_ReportsErrorsAcrossFiles(t *testing.T) {
+	repo := copyRepo(t)
+	const (
+		ruleset  = "specs/rulesets/cis/okta/cis.okta.idaas_stig.v1.json"
+		contract = "specs/datasets/okta/policies.sign-on/v1.json"
+		broken   = "specs/broken.json"
+		unknown  = "specs/unknown.json"
+	)
+	replaceFirst(t, repo, ruleset, `"op": "eq"`, `"op": "equals"`)
+	// The contract fails schema validation; references to it must not also be reported.
+	replaceFirst(t, repo, contract, `"version": 1`, `"version": "1"`)
+	for rel, content := range map[string]string{
+		broken:  "{\n  \"schema_version\": 1,\n",
+		unknown: `{ "schema_version": 1, "kind": "opensspm.widget" }`,
+	} {
+		if err := os.WriteFile(filepath.Join(repo, filepath.FromSlash(rel)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := Compile(context.Background(), Options{RepoRoot: repo})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	byFile := map[string][]string{}
+	for _, d := range diag.FromError(err) {
+		if d.Code == diag.CodeUnresolvedReference {
+			t.Errorf("unexpected unresolved reference: %v", d)
+		}
+		byFile[d.File] = append(byFile[d.File], d.Code)
+	}
+	want := map[string]string{
+		ruleset:  diag.CodeSchema,
+		contract: diag.CodeSchema,
+		broken:   diag.CodeInvalidJSON,
+		unknown:  diag.CodeUnknownKind,
+	}
+	for file, code := range want {
+		if !slices.Contains(byFile[file], code) {
+			t.Errorf("%s: want %s, got %v", file, code, byFile[file])
+		}
+	}
+	if len(byFile) != len(want) {
+		t.Errorf("diagnostics for unexpected files: %v", byFile)
+	}
+}
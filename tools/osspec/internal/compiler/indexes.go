@@ -1,12 +1,12 @@
 package compiler
 
 import (
-	"fmt"
 	"slices"
 	"strings"
 
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/normalize"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/schemasem"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
 
@@ -19,6 +19,7 @@ func buildRequirements(b *schemasem.Bundle) types.RequirementsIndex {
 	for _, rs := range b.Rulesets {
 		req := types.RulesetRequirement{
 			RulesetKey: rs.Doc.Ruleset.Key,
+			RulesetVersion: rs.Doc.Ruleset.Version,
 			Status:     rs.Doc.Ruleset.Status,
 			Scope:      rs.Doc.Ruleset.Scope,
 		}
@@ -34,21 +35,12 @@ func buildRequirements(b *schemasem.Bundle) types.RequirementsIndex {
 			if r.Check != nil {
 				ct := r.Check.Type
 				checkTypePtr = &ct
-				checkTypes[ct] = struct{}{}
 			}
-
 			rDatasets := datasetsForRuleCheck(rs.Doc.Ruleset, r.Check)
 			rDatasets = normalize.DatasetRefs(rDatasets)
-			for _, d := range rDatasets {
-				datasets[fmt.Sprintf("%s@%d", d.Dataset, d.Version)] = d
-			}
-
 			rValueParams := valueParamsForRuleCheck(r.Check)
-			for _, vp := range rValueParams {
-				valueParams[vp] = struct{}{}
-			}
 
-			req.Rules = append(req.Rules, types.RuleRequirement{
+			rr := types.RuleRequirement{
 				RuleKey:    r.Key,
 				IsManual:   isManualRule(r),
 				Datasets:   rDatasets,
@@ -57,7 +49,29 @@ func buildRequirements(b *schemasem.Bundle) types.RequirementsIndex {
 				Monitoring: struct {
 					Status types.MonitoringStatus `json:"status"`
 				}{Status: r.Monitoring.Status},
-			})
+			}
+			if r.Lifecycle != nil {
+				rr.ReplacedBy = r.Lifecycle.ReplacedBy
+			}
+			// Inactive rules are not evaluated, so they add no requirements of the ruleset.
+			if !r.Active() {
+				req.InactiveRules = append(req.InactiveRules, rr)
+				continue
+			}
+
+			if checkTypePtr != nil {
+				checkTypes[*checkTypePtr] = struct{}{}
+			}
+			for _, d := range rDatasets {
+				datasets[d.String()] = d
+			}
+			for _, vp := range rValueParams {
+				valueParams[vp] = struct{}{}
+			}
+			req.Rules = append(req.Rules, rr)
+		}
+		if req.Rules == nil {
+			req.Rules = []types.RuleRequirement{}
 		}
 
 		req.Datasets = setToSortedDatasetRefs(datasets)
@@ -68,7 +82,10 @@ func buildRequirements(b *schemasem.Bundle) types.RequirementsIndex {
 	}
 
 	slices.SortFunc(out.Rulesets, func(a, b types.RulesetRequirement) int {
-		return strings.Compare(a.RulesetKey, b.RulesetKey)
+		if c := strings.Compare(a.RulesetKey, b.RulesetKey); c != 0 {
+			return c
+		}
+		return semver.CompareStrings(a.RulesetVersion, b.RulesetVersion)
 	})
 	return out
 }
@@ -87,30 +104,15 @@ func isManualRule(r *types.Rule) bool {
 }
 
 func datasetsForRuleCheck(rs types.Ruleset, c *types.Check) []types.DatasetRefSpec {
+	out := []types.DatasetRefSpec{}
 	if c == nil {
-		return []types.DatasetRefSpec{}
-	}
-	switch c.Type {
-	case types.CheckTypeDatasetFieldCompare, types.CheckTypeDatasetCountCompare:
-		if strings.TrimSpace(c.Dataset) == "" {
-			return []types.DatasetRefSpec{}
-		}
-		v := types.EffectiveDatasetVersion(c.Dataset, rs.DataContracts, c.DatasetVersion)
-		return []types.DatasetRefSpec{{Dataset: c.Dataset, Version: v}}
-	case types.CheckTypeDatasetJoinCountCompare:
-		var out []types.DatasetRefSpec
-		if c.Left != nil && strings.TrimSpace(c.Left.Dataset) != "" {
-			v := types.EffectiveDatasetVersion(c.Left.Dataset, rs.DataContracts, c.DatasetVersion)
-			out = append(out, types.DatasetRefSpec{Dataset: c.Left.Dataset, Version: v})
-		}
-		if c.Right != nil && strings.TrimSpace(c.Right.Dataset) != "" {
-			v := types.EffectiveDatasetVersion(c.Right.Dataset, rs.DataContracts, c.DatasetVersion)
-			out = append(out, types.DatasetRefSpec{Dataset: c.Right.Dataset, Version: v})
-		}
 		return out
-	default:
-		return []types.DatasetRefSpec{}
 	}
+	for _, d := range c.Datasets() {
+		v := types.EffectiveDatasetVersion(d.Dataset, rs.DataContracts, c.DatasetVersion)
+		out = append(out, types.DatasetRefSpec{Dataset: d.Dataset, Version: v, ConnectorKind: d.ConnectorKind})
+	}
+	return out
 }
 
 func valueParamsForRuleCheck(c *types.Check) []string {
@@ -119,15 +121,16 @@ func valueParamsForRuleCheck(c *types.Check) []string {
 	}
 	set := map[string]struct{}{}
 
-	for i := range c.Where {
-		if vp := strings.TrimSpace(c.Where[i].ValueParam); vp != "" {
+	add := func(_ string, p *types.Predicate) {
+		if vp := strings.TrimSpace(p.ValueParam); vp != "" {
 			set[vp] = struct{}{}
 		}
 	}
+	for i := range c.Where {
+		c.Where[i].Walk(add)
+	}
 	if c.Assert != nil {
-		if vp := strings.TrimSpace(c.Assert.ValueParam); vp != "" {
-			set[vp] = struct{}{}
-		}
+		c.Assert.Walk(add)
 	}
 	if c.Compare != nil {
 		if vp := strings.TrimSpace(c.Compare.ValueParam); vp != "" {
@@ -180,4 +183,3 @@ func setToSortedStringSlice(m map[string]struct{}) []string {
 	slices.Sort(out)
 	return out
 }
-
@@ -0,0 +1,64 @@
+package compiler
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/hash"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/schemasem"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// buildEffectiveRulesets returns, by profile key, the rulesets each flattened profile
+// (with resolved ruleset versions) includes with the profile's rule overrides applied: excluded rules are dropped,
+// severities replaced and parameter defaults overridden. Rulesets are in profile order.
+func buildEffectiveRulesets(b *schemasem.Bundle, profiles []types.Compiled[types.ProfileDoc]) (map[string][]types.Compiled[types.RulesetDoc], error) {
+	out := map[string][]types.Compiled[types.RulesetDoc]{}
+	for _, p := range profiles {
+		for _, ref := range p.Object.Profile.Rulesets {
+			rs := schemasem.ResolveRuleset(b, ref.Key, ref.Version)
+			if rs == nil {
+				return nil, fmt.Errorf("%s: ruleset %q version %q not found", p.SourcePath, ref.Key, ref.Version)
+			}
+			doc := applyOverrides(rs, ref.Overrides)
+			h, _, err := hash.HashObjectJCS(doc)
+			if err != nil {
+				return nil, fmt.Errorf("%s: hash effective ruleset %q: %w", p.SourcePath, ref.Key, err)
+			}
+			key := p.Object.Profile.Key
+			out[key] = append(out[key], types.Compiled[types.RulesetDoc]{SourcePath: p.SourcePath, Hash: h, Object: doc})
+		}
+	}
+	return out, nil
+}
+
+// applyOverrides returns a copy of rs with overrides applied. rs is not modified.
+func applyOverrides(rs *types.RulesetDoc, overrides []types.RuleOverride) types.RulesetDoc {
+	doc := *rs
+	byRule := map[string]types.RuleOverride{}
+	for _, o := range overrides {
+		byRule[o.RuleKey] = o
+	}
+	doc.Ruleset.Rules = make([]types.Rule, 0, len(rs.Ruleset.Rules))
+	for _, r := range rs.Ruleset.Rules {
+		o, ok := byRule[r.Key]
+		if !ok {
+			doc.Ruleset.Rules = append(doc.Ruleset.Rules, r)
+			continue
+		}
+		if o.Exclude {
+			continue
+		}
+		if o.Severity != "" {
+			r.Severity = o.Severity
+		}
+		if len(o.Parameters) > 0 && r.Parameters != nil {
+			params := *r.Parameters
+			params.Defaults = maps.Clone(r.Parameters.Defaults)
+			maps.Copy(params.Defaults, o.Parameters)
+			r.Parameters = &params
+		}
+		doc.Ruleset.Rules = append(doc.Ruleset.Rules, r)
+	}
+	return doc
+}
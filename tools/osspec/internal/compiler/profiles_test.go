@@ -0,0 +1,124 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	rs := &types.RulesetDoc{Ruleset: types.Ruleset{
+		Key: "example.v1",
+		Rules: []types.Rule{
+			{Key: "R1", Severity: types.SeverityLow},
+			{Key: "R2", Severity: types.SeverityLow, Parameters: &types.Parameters{Defaults: map[string]any{"max_age": 90.0, "mode": "strict"}}},
+			{Key: "R3", Severity: types.SeverityMedium},
+		},
+	}}
+	got := applyOverrides(rs, []types.RuleOverride{
+		{RuleKey: "R1", Exclude: true},
+		{RuleKey: "R2", Severity: types.SeverityHigh, Parameters: map[string]any{"max_age": 30.0}},
+	})
+
+	want := []types.Rule{
+		{Key: "R2", Severity: types.SeverityHigh, Parameters: &types.Parameters{Defaults: map[string]any{"max_age": 30.0, "mode": "strict"}}},
+		{Key: "R3", Severity: types.SeverityMedium},
+	}
+	if diff := cmp.Diff(want, got.Ruleset.Rules); diff != "" {
+		t.Fatalf("effective rules mismatch (-want +got):\n%s", diff)
+	}
+	// The source ruleset is not modified.
+	if len(rs.Ruleset.Rules) != 3 || rs.Ruleset.Rules[1].Severity != types.SeverityLow || rs.Ruleset.Rules[1].Parameters.Defaults["max_age"] != 90.0 {
+		t.Fatalf("source ruleset was modified: %+v", rs.Ruleset.Rules)
+	}
+}
+
+func TestBuild_WritesEffectiveRulesets(t *testing.T) {
+	const (
+		profileKey = "cis.okta.idaas_stig.profile.v1"
+		rulesetKey = "cis.okta.idaas_stig.v1"
+	)
+	repo := copyRepo(t)
+	replaceFirst(t, repo, "specs/profiles/"+profileKey+".json",
+		`{ "key": "`+rulesetKey+`", "version": "v1.0.0" }`,
+		`{ "key": "`+rulesetKey+`", "version": "v1.0.0", "overrides": [
+        { "rule_key": "OKTA-APP-000025", "severity": "low", "rationale": "Compensating control" },
+        { "rule_key": "OKTA-APP-000020", "exclude": true }
+      ] }`)
+
+	res, err := Build(context.Background(), Options{RepoRoot: repo})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if n := len(res.EffectiveRulesets[profileKey]); n != 1 {
+		t.Fatalf("got %d effective rulesets for %s, want 1", n, profileKey)
+	}
+
+	b, err := os.ReadFile(filepath.Join(repo, "dist", "compiled", "profiles", profileKey, rulesetKey+".json"))
+	if err != nil {
+		t.Fatalf("read effective ruleset: %v", err)
+	}
+	var doc types.RulesetDoc
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("decode effective ruleset: %v", err)
+	}
+	rules := doc.Ruleset.Rules
+	if rules[0].Key != "OKTA-APP-000025" || rules[0].Severity != types.SeverityLow {
+		t.Fatalf("first effective rule = %s (%s), want OKTA-APP-000025 (low)", rules[0].Key, rules[0].Severity)
+	}
+	for _, r := range rules {
+		if r.Key == "OKTA-APP-000020" {
+			t.Fatalf("excluded rule %s is in the effective ruleset", r.Key)
+		}
+	}
+}
+
+func TestCompile_FlattensExtendedProfiles(t *testing.T) {
+	repo := copyRepo(t)
+	const strict = `{
+  "schema_version": 1,
+  "kind": "opensspm.profile",
+  "profile": {
+    "key": "cis.okta.idaas_stig.strict.v1",
+    "name": "Strict",
+    "extends": ["cis.okta.idaas_stig.profile.v1"],
+    "rulesets": [
+      { "key": "cis.okta.idaas_stig.v1", "overrides": [{ "rule_key": "OKTA-APP-000020", "severity": "critical" }] }
+    ]
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(repo, "specs", "profiles", "strict.json"), []byte(strict), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Compile(context.Background(), Options{RepoRoot: repo})
+	if err != nil {
+		t.Fatalf("Compile() error: %v", err)
+	}
+	var got *types.Profile
+	for i := range res.Descriptor.Profiles {
+		if p := &res.Descriptor.Profiles[i].Object.Profile; p.Key == "cis.okta.idaas_stig.strict.v1" {
+			got = p
+		}
+	}
+	if got == nil {
+		t.Fatal("strict profile not in descriptor")
+	}
+	want := []types.ProfileRulesetRef{{
+		Key:       "cis.okta.idaas_stig.v1",
+		Version:   "1.0.0",
+		Overrides: []types.RuleOverride{{RuleKey: "OKTA-APP-000020", Severity: types.SeverityCritical}},
+	}}
+	if diff := cmp.Diff(want, got.Rulesets); diff != "" {
+		t.Fatalf("flattened rulesets mismatch (-want +got):\n%s", diff)
+	}
+	if rs := res.EffectiveRulesets[got.Key]; len(rs) != 1 || rs[0].Object.Ruleset.Rules[0].Severity != types.SeverityCritical {
+		t.Fatalf("effective ruleset does not apply the override: %+v", rs)
+	}
+}
@@ -111,3 +111,104 @@ func TestBuildRequirements_CapturesRulesetAndRuleDetails(t *testing.T) {
 	}
 }
 
+
+func TestBuildRequirements_ListsInactiveRulesSeparately(t *testing.T) {
+	inactive, one := false, 1.0
+	rule := func(key, dataset string) types.Rule {
+		return types.Rule{
+			Key:          key,
+			Title:        key,
+			Severity:     types.SeverityLow,
+			Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+			RequiredData: []string{dataset},
+			Check: &types.Check{
+				Type:    types.CheckTypeDatasetCountCompare,
+				Dataset: dataset,
+				Compare: &types.Compare{Op: types.CompareOpGte, Value: &one},
+			},
+		}
+	}
+	old := rule("R1", "okta:users")
+	old.Lifecycle = &types.Lifecycle{IsActive: &inactive, ReplacedBy: "R2"}
+	b := &schemasem.Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{{Path: "specs/rulesets/example.json", Doc: types.RulesetDoc{
+			SchemaVersion: 1,
+			Kind:          "opensspm.ruleset",
+			Ruleset: types.Ruleset{
+				Key:   "example.ruleset.v1",
+				Scope: types.Scope{Kind: types.ScopeKindGlobal},
+				DataContracts: []types.DatasetContractRef{
+					{Dataset: "okta:users", Version: 1},
+					{Dataset: "okta:groups", Version: 1},
+				},
+				Rules: []types.Rule{old, rule("R2", "okta:groups")},
+			},
+		}}},
+	}
+
+	req := buildRequirements(b).Rulesets[0]
+	if len(req.Rules) != 1 || req.Rules[0].RuleKey != "R2" {
+		t.Fatalf("Rules = %+v, want only R2", req.Rules)
+	}
+	if len(req.InactiveRules) != 1 || req.InactiveRules[0].RuleKey != "R1" || req.InactiveRules[0].ReplacedBy != "R2" {
+		t.Fatalf("InactiveRules = %+v, want R1 replaced by R2", req.InactiveRules)
+	}
+	if diff := cmp.Diff([]types.DatasetRefSpec{{Dataset: "okta:groups", Version: 1}}, req.Datasets); diff != "" {
+		t.Fatalf("Datasets mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValueParamsForRuleCheck_NestedGroups(t *testing.T) {
+	c := &types.Check{
+		Type: types.CheckTypeDatasetFieldCompare,
+		Where: []types.Predicate{
+			{AnyOf: []types.Predicate{
+				{Path: "/a", Op: types.OperatorEq, ValueParam: "b"},
+				{Not: &types.Predicate{Path: "/c", Op: types.OperatorIn, ValueParam: "c"}},
+			}},
+		},
+		Assert: &types.Predicate{AllOf: []types.Predicate{{Path: "/d", Op: types.OperatorGte, ValueParam: "a"}}},
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, valueParamsForRuleCheck(c)); diff != "" {
+		t.Fatalf("value params mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDatasetsForRuleCheck_JoinFieldCompare(t *testing.T) {
+	rs := types.Ruleset{DataContracts: []types.DatasetContractRef{
+		{Dataset: "okta:users", Version: 1},
+		{Dataset: "okta:factors", Version: 2},
+	}}
+	c := &types.Check{
+		Type:   types.CheckTypeDatasetJoinFieldCompare,
+		Left:   &types.JoinSide{Dataset: "okta:users", KeyPath: "/id"},
+		Right:  &types.JoinSide{Dataset: "okta:factors", KeyPath: "/user_id"},
+		Assert: &types.Predicate{RightPath: "/type", Op: types.OperatorEq, ValueParam: "factor_type"},
+	}
+	want := []types.DatasetRefSpec{{Dataset: "okta:users", Version: 1}, {Dataset: "okta:factors", Version: 2}}
+	if diff := cmp.Diff(want, datasetsForRuleCheck(rs, c)); diff != "" {
+		t.Fatalf("datasets mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"factor_type"}, valueParamsForRuleCheck(c)); diff != "" {
+		t.Fatalf("value params mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDatasetsForRuleCheck_ConnectorQualified(t *testing.T) {
+	rs := types.Ruleset{Scope: types.Scope{Kind: types.ScopeKindGlobal}}
+	c := &types.Check{
+		Type:  types.CheckTypeDatasetJoinCountCompare,
+		Left:  &types.JoinSide{Dataset: "core:users", ConnectorKind: "okta", KeyPath: "/email"},
+		Right: &types.JoinSide{Dataset: "core:users", ConnectorKind: "slack", KeyPath: "/email"},
+	}
+	want := []types.DatasetRefSpec{
+		{Dataset: "core:users", Version: 1, ConnectorKind: "okta"},
+		{Dataset: "core:users", Version: 1, ConnectorKind: "slack"},
+	}
+	if diff := cmp.Diff(want, datasetsForRuleCheck(rs, c)); diff != "" {
+		t.Fatalf("datasets mismatch (-want +got):\n%s", diff)
+	}
+}
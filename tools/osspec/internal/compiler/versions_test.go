@@ -0,0 +1,63 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild_RulesetVersionsCoexist(t *testing.T) {
+	const src = "specs/rulesets/cis/okta/cis.okta.idaas_stig.v1.json"
+	repo := copyRepo(t)
+	b, err := os.ReadFile(filepath.Join(repo, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 := strings.Replace(string(b), `"version": "1.0.0"`, `"version": "2.0.0"`, 1)
+	if err := os.WriteFile(filepath.Join(repo, "specs/rulesets/cis/okta/cis.okta.idaas_stig.v2.json"), []byte(v2), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Build(context.Background(), Options{RepoRoot: repo})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	var ids []string
+	for _, a := range res.Artifacts.Artifacts {
+		if a.Kind == "opensspm.ruleset" {
+			ids = append(ids, a.Key)
+		}
+	}
+	if want := "cis.okta.idaas_stig.v1@1.0.0,cis.okta.idaas_stig.v1@2.0.0"; strings.Join(ids, ",") != want {
+		t.Fatalf("ruleset artifacts = %v, want %s", ids, want)
+	}
+	var reqs []string
+	for _, r := range res.Requirements.Rulesets {
+		reqs = append(reqs, r.RulesetKey+"@"+r.RulesetVersion)
+	}
+	if want := "cis.okta.idaas_stig.v1@1.0.0,cis.okta.idaas_stig.v1@2.0.0"; strings.Join(reqs, ",") != want {
+		t.Fatalf("requirements rulesets = %v, want %s", reqs, want)
+	}
+	for _, name := range []string{"cis.okta.idaas_stig.v1.v1.0.0.json", "cis.okta.idaas_stig.v1.v2.0.0.json"} {
+		if _, err := os.Stat(filepath.Join(repo, "dist", "compiled", "rulesets", name)); err != nil {
+			t.Errorf("compiled ruleset: %v", err)
+		}
+	}
+	// The unqualified path holds the highest version.
+	b, err = os.ReadFile(filepath.Join(repo, "dist", "compiled", "rulesets", "cis.okta.idaas_stig.v1.json"))
+	if err != nil {
+		t.Fatalf("compiled ruleset: %v", err)
+	}
+	if !strings.Contains(string(b), `"version":"2.0.0"`) {
+		t.Errorf("cis.okta.idaas_stig.v1.json is not version 2.0.0")
+	}
+
+	// The profile pins v1.0.0, so it keeps selecting it.
+	p := res.Descriptor.Profiles[0].Object.Profile
+	if got := p.Rulesets[0].Version; got != "1.0.0" {
+		t.Fatalf("profile ruleset version = %q, want 1.0.0", got)
+	}
+}
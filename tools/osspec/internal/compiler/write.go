@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/hash"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
 
 func Build(ctx context.Context, opts Options) (*Result, error) {
@@ -29,41 +31,42 @@ func writeDist(repoRoot, distDir string, res *Result) error {
 	if err != nil {
 		return err
 	}
-	distAbs := filepath.Join(repoRootAbs, distDir)
 	docsAbs := filepath.Join(repoRootAbs, "docs")
 
-	if err := os.MkdirAll(filepath.Join(distAbs, "index"), 0o755); err != nil {
+	if err := WriteDist(filepath.Join(repoRootAbs, distDir), res); err != nil {
 		return err
 	}
-	_ = os.RemoveAll(filepath.Join(distAbs, "compiled"))
-	if err := os.MkdirAll(filepath.Join(distAbs, "compiled"), 0o755); err != nil {
+
+	if err := os.MkdirAll(docsAbs, 0o755); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Join(distAbs, "compiled", "rulesets"), 0o755); err != nil {
+	if err := writeCanonicalJSON(filepath.Join(docsAbs, "descriptor.v1.json"), res.Descriptor); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Join(distAbs, "compiled", "datasets"), 0o755); err != nil {
+	return copyMetaschemaToDocs(repoRootAbs, docsAbs)
+}
+
+// WriteDist writes the compiled artifacts (descriptor, indexes, compiled documents and
+// conformance suites) to distDir. Unlike Build it does not update the docs site.
+func WriteDist(distDir string, res *Result) error {
+	distAbs, err := filepath.Abs(distDir)
+	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Join(distAbs, "compiled", "connectors"), 0o755); err != nil {
+
+	if err := os.MkdirAll(filepath.Join(distAbs, "index"), 0o755); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Join(distAbs, "compiled", "profiles"), 0o755); err != nil {
-		return err
+	_ = os.RemoveAll(filepath.Join(distAbs, "compiled"))
+	for _, dir := range []string{"rulesets", "datasets", "connectors", "profiles"} {
+		if err := os.MkdirAll(filepath.Join(distAbs, "compiled", dir), 0o755); err != nil {
+			return err
+		}
 	}
 
 	if err := writeCanonicalJSON(filepath.Join(distAbs, "descriptor.v1.json"), res.Descriptor); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(docsAbs, 0o755); err != nil {
-		return err
-	}
-	if err := writeCanonicalJSON(filepath.Join(docsAbs, "descriptor.v1.json"), res.Descriptor); err != nil {
-		return err
-	}
-	if err := copyMetaschemaToDocs(repoRootAbs, docsAbs); err != nil {
-		return err
-	}
 	if err := writeCanonicalJSON(filepath.Join(distAbs, "index", "artifacts.json"), res.Artifacts); err != nil {
 		return err
 	}
@@ -77,7 +80,7 @@ func writeDist(repoRoot, distDir string, res *Result) error {
 	if err := writeCompiled(distAbs, res); err != nil {
 		return err
 	}
-	return nil
+	return writeConformance(distAbs, res)
 }
 
 func copyMetaschemaToDocs(repoRootAbs, docsAbs string) error {
@@ -124,11 +127,20 @@ func writeCanonicalJSON(path string, v any) error {
 }
 
 func writeCompiled(distAbs string, res *Result) error {
-	// Rulesets
-	for _, rs := range res.Descriptor.Rulesets {
-		name := sanitizeFilename(rs.Object.Ruleset.Key) + ".json"
-		if err := writeCanonicalJSON(filepath.Join(distAbs, "compiled", "rulesets", name), rs.Object); err != nil {
-			return fmt.Errorf("write compiled ruleset %s: %w", rs.Object.Ruleset.Key, err)
+	// Rulesets: <key>.v<version>.json per version, and <key>.json for the highest version
+	latest := latestRulesets(res.Descriptor.Rulesets)
+	for i, rs := range res.Descriptor.Rulesets {
+		var names []string
+		if rs.Object.Ruleset.Version != "" {
+			names = append(names, sanitizeFilename(rs.Object.Ruleset.Key)+".v"+sanitizeFilename(rs.Object.Ruleset.Version)+".json")
+		}
+		if latest[rs.Object.Ruleset.Key] == i {
+			names = append(names, sanitizeFilename(rs.Object.Ruleset.Key)+".json")
+		}
+		for _, name := range names {
+			if err := writeCanonicalJSON(filepath.Join(distAbs, "compiled", "rulesets", name), rs.Object); err != nil {
+				return fmt.Errorf("write compiled ruleset %s: %w", rs.Object.Ruleset.ID(), err)
+			}
 		}
 	}
 	// Dataset contracts
@@ -152,6 +164,19 @@ func writeCompiled(distAbs string, res *Result) error {
 			return fmt.Errorf("write compiled profile %s: %w", p.Object.Profile.Key, err)
 		}
 	}
+	// Effective rulesets: dist/compiled/profiles/<profile>/<ruleset>.json
+	for _, p := range res.Descriptor.Profiles {
+		dir := filepath.Join(distAbs, "compiled", "profiles", sanitizeFilename(p.Object.Profile.Key))
+		for _, rs := range res.EffectiveRulesets[p.Object.Profile.Key] {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+			name := sanitizeFilename(rs.Object.Ruleset.Key) + ".json"
+			if err := writeCanonicalJSON(filepath.Join(dir, name), rs.Object); err != nil {
+				return fmt.Errorf("write effective ruleset %s for profile %s: %w", rs.Object.Ruleset.Key, p.Object.Profile.Key, err)
+			}
+		}
+	}
 	// Dictionary
 	if err := writeCanonicalJSON(filepath.Join(distAbs, "compiled", "dictionary.json"), res.Descriptor.Dictionary.Object); err != nil {
 		return fmt.Errorf("write compiled dictionary: %w", err)
@@ -160,6 +185,37 @@ func writeCompiled(distAbs string, res *Result) error {
 	return nil
 }
 
+func writeConformance(distAbs string, res *Result) error {
+	dir := filepath.Join(distAbs, "conformance")
+	_ = os.RemoveAll(dir)
+	if len(res.Conformance) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, s := range res.Conformance {
+		name := sanitizeFilename(s.Object.Suite.Key) + ".json"
+		if err := writeCanonicalJSON(filepath.Join(dir, name), s.Object); err != nil {
+			return fmt.Errorf("write conformance suite %s: %w", s.Object.Suite.Key, err)
+		}
+	}
+	return nil
+}
+
+// latestRulesets returns the index in rulesets of the highest version of each ruleset key.
+// An unversioned ruleset cannot share its key with another ruleset.
+func latestRulesets(rulesets []types.Compiled[types.RulesetDoc]) map[string]int {
+	out := map[string]int{}
+	for i, rs := range rulesets {
+		j, ok := out[rs.Object.Ruleset.Key]
+		if !ok || semver.CompareStrings(rulesets[j].Object.Ruleset.Version, rs.Object.Ruleset.Version) < 0 {
+			out[rs.Object.Ruleset.Key] = i
+		}
+	}
+	return out
+}
+
 func sanitizeFilename(s string) string {
 	if s == "" {
 		return "unnamed"
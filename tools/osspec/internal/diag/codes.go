@@ -0,0 +1,38 @@
+package diag
+
+// Diagnostic codes. Codes are stable identifiers that tools match on, so existing codes
+// must not be renamed.
+const (
+	CodeInternal = "internal"
+
+	// Loading and parsing.
+	CodeLoad                     = "load-error"
+	CodeSymlink                  = "symlink-not-allowed"
+	CodeFileTooLarge             = "file-too-large"
+	CodeInvalidJSON              = "invalid-json"
+	CodeUnsupportedSchemaVersion = "unsupported-schema-version"
+	CodeUnknownKind              = "unknown-kind"
+	CodeSchema                   = "schema-violation"
+
+	// Semantic validation (section numbers refer to the spec's validation rules).
+	CodeDuplicateKey         = "duplicate-key"
+	CodeScope                = "invalid-scope"
+	CodeParametersSchema     = "parameters-schema"      // 3.3
+	CodeMonitoringCheck      = "monitoring-check"       // 6.3
+	CodeCheckType            = "check-type"             // 6.4
+	CodeCheckFields          = "check-fields"           // 6.4
+	CodeRequiredDataCoverage = "required-data-coverage" // 6.5
+	CodeDatasetVersion       = "dataset-version"        // 6.6
+	CodeValueParam           = "value-param"            // 6.7
+	CodePredicate            = "predicate-structure"    // 6.8
+	CodeCompare              = "compare-structure"
+	CodeUnresolvedReference  = "unresolved-reference"
+	CodeConnectorCoverage    = "connector-coverage"
+	CodeUnknownPath          = "unknown-path"
+	CodeTypeMismatch         = "type-mismatch"
+	CodeConformanceVector    = "conformance-vector"
+	CodeContractCompat       = "contract-compat"
+	CodeProfileOverride      = "profile-override"
+	CodeProfileExtends       = "profile-extends"
+	CodeRuleLifecycle        = "rule-lifecycle"
+)
@@ -0,0 +1,129 @@
+// Package diag defines the structured diagnostics osspec reports for spec files.
+//
+// A Diagnostic names a file, a JSON pointer into that file and, when the source bytes are
+// known, the 1-based line and column of the pointed-to value.
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+type Location struct {
+	File    string `json:"file,omitempty"`
+	Pointer string `json:"pointer,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// String renders the location as file[:line[:column]].
+func (l Location) String() string {
+	switch {
+	case l.Line > 0 && l.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+	case l.Line > 0:
+		return fmt.Sprintf("%s:%d", l.File, l.Line)
+	default:
+		return l.File
+	}
+}
+
+// Locate sets Line and Column from src. Pointers that do not fully resolve are located at
+// their deepest existing ancestor.
+func (l *Location) Locate(src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	l.Line, l.Column = Position(src, l.Pointer)
+}
+
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Location
+	Message string     `json:"message"`
+	Related []Location `json:"related,omitempty"`
+}
+
+// New returns an error-severity diagnostic.
+func New(code, file, pointer, format string, args ...any) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Location: Location{File: file, Pointer: pointer},
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// WithRelated appends a related location and returns d.
+func (d *Diagnostic) WithRelated(file, pointer string) *Diagnostic {
+	d.Related = append(d.Related, Location{File: file, Pointer: pointer})
+	return d
+}
+
+func (d *Diagnostic) Error() string {
+	if d.File == "" {
+		return d.Message
+	}
+	return d.Location.String() + ": " + d.Message
+}
+
+// List is a set of diagnostics. It implements error so it can flow through APIs that
+// return a single error.
+type List []*Diagnostic
+
+func (l List) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed:\n")
+	for _, d := range l {
+		b.WriteString(" - ")
+		b.WriteString(d.Error())
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Err returns l as an error, or nil if l is empty.
+func (l List) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// InFile sets File on diagnostics that have none and locates them in src.
+func (l List) InFile(file string, src []byte) List {
+	for _, d := range l {
+		if d.File != "" {
+			continue
+		}
+		d.File = file
+		d.Locate(src)
+	}
+	return l
+}
+
+// FromError converts err into diagnostics. Errors that are not diagnostics become a single
+// CodeInternal diagnostic without a location.
+func FromError(err error) List {
+	if err == nil {
+		return nil
+	}
+	var l List
+	if errors.As(err, &l) {
+		return l
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return List{d}
+	}
+	return List{New(CodeInternal, "", "", "%s", err.Error())}
+}
@@ -0,0 +1,223 @@
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const doc = `{
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "k",
+    "rules": [
+      { "key": "B", "title": "é \"x\"", "check": { "where": [ { "path": "/b", "op": "eq" }, { "path": "/a", "op": "eq" } ] } },
+      { "key": "A" }
+    ]
+  }
+}`
+
+func TestPosition(t *testing.T) {
+	cases := []struct {
+		pointer   string
+		line, col int
+	}{
+		{"", 1, 1},
+		{"/kind", 2, 11},
+		{"/ruleset/key", 4, 12},
+		{"/ruleset/rules/1", 7, 7},
+		{"/ruleset/rules/0/check", 6, 50},
+		{"/ruleset/rules/0/check/where/1/path", 6, 103},
+		// Unresolved pointers fall back to the deepest existing ancestor.
+		{"/ruleset/rules/1/check", 7, 7},
+		{"/ruleset/rules/9", 5, 14},
+	}
+	for _, tc := range cases {
+		line, col := Position([]byte(doc), tc.pointer)
+		if line != tc.line || col != tc.col {
+			t.Errorf("Position(%q) = %d:%d, want %d:%d", tc.pointer, line, col, tc.line, tc.col)
+		}
+	}
+	if line, col := Position([]byte("  "), "/a"); line != 0 || col != 0 {
+		t.Errorf("Position on empty document = %d:%d, want 0:0", line, col)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	source := Decode([]byte(doc))
+	// The normalized form sorts rules by key and predicates by path.
+	normalized := Decode([]byte(`{
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "k",
+    "rules": [
+      { "key": "A" },
+      { "key": "B", "title": "é \"x\"", "check": { "where": [ { "path": "/a", "op": "eq" }, { "path": "/b", "op": "eq" } ] } }
+    ]
+  }
+}`))
+	cases := []struct{ in, want string }{
+		{"/ruleset/key", "/ruleset/key"},
+		{"/ruleset/rules/0", "/ruleset/rules/1"},
+		{"/ruleset/rules/1/check/where/0/path", "/ruleset/rules/0/check/where/1/path"},
+		{"/ruleset/rules/1/check/dataset_version", "/ruleset/rules/0/check/dataset_version"},
+		{"/ruleset/rules/7", "/ruleset/rules/7"},
+	}
+	for _, tc := range cases {
+		if got := Remap(normalized, source, tc.in); got != tc.want {
+			t.Errorf("Remap(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRemap_PredicateGroups(t *testing.T) {
+	// Groups sort before comparisons, and their members are sorted too.
+	source := Decode([]byte(`{ "where": [
+  { "path": "/a", "op": "eq" },
+  { "any_of": [ { "path": "/c", "op": "eq" }, { "not": { "path": "/b", "op": "exists" } } ] }
+] }`))
+	normalized := Decode([]byte(`{ "where": [
+  { "any_of": [ { "not": { "path": "/b", "op": "exists" } }, { "path": "/c", "op": "eq" } ] },
+  { "path": "/a", "op": "eq" }
+] }`))
+	cases := []struct{ in, want string }{
+		{"/where/1/path", "/where/0/path"},
+		{"/where/0/any_of/0/not/op", "/where/1/any_of/1/not/op"},
+		{"/where/0/any_of/1", "/where/1/any_of/0"},
+	}
+	for _, tc := range cases {
+		if got := Remap(normalized, source, tc.in); got != tc.want {
+			t.Errorf("Remap(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFromError(t *testing.T) {
+	d := New(CodeUnknownPath, "a.json", "/x", "bad %s", "path")
+	d.Line, d.Column = 3, 4
+	l := List{d}
+
+	if got := l.Error(); got != "validation failed:\n - a.json:3:4: bad path" {
+		t.Fatalf("List.Error() = %q", got)
+	}
+	if got := FromError(fmt.Errorf("wrapped: %w", l)); len(got) != 1 || got[0] != d {
+		t.Fatalf("FromError(wrapped list) = %v", got)
+	}
+	if got := FromError(d); len(got) != 1 || got[0] != d {
+		t.Fatalf("FromError(diagnostic) = %v", got)
+	}
+	got := FromError(errors.New("boom"))
+	if len(got) != 1 || got[0].Code != CodeInternal || got[0].Message != "boom" {
+		t.Fatalf("FromError(plain) = %v", got)
+	}
+	if FromError(nil) != nil || (List{}).Err() != nil {
+		t.Fatalf("expected nil for no errors")
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	seen := map[string]bool{}
+	for _, r := range Rules {
+		if r.ID == "" || r.Name == "" || r.Description == "" || seen[r.ID] {
+			t.Fatalf("invalid or duplicate rule %+v", r)
+		}
+		seen[r.ID] = true
+	}
+
+	d := New(CodeRequiredDataCoverage, "specs/r.json", "/ruleset/rules/0/required_data", "missing").WithRelated("specs/d.json", "/dataset/key")
+	d.Line, d.Column = 12, 9
+	log := SARIF(List{d, New("no-such-code", "", "", "boom")}, "")
+
+	b, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				RelatedLocations []json.RawMessage `json:"relatedLocations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 || len(got.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected log: %s", b)
+	}
+	run := got.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules) {
+		t.Fatalf("driver lists %d rules, want %d", len(run.Tool.Driver.Rules), len(Rules))
+	}
+
+	r := run.Results[0]
+	if r.RuleID != CodeRequiredDataCoverage || run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+		t.Fatalf("result rule = %s (index %d)", r.RuleID, r.RuleIndex)
+	}
+	if len(r.Locations) != 1 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "specs/r.json" ||
+		r.Locations[0].PhysicalLocation.Region.StartLine != 12 || r.Locations[0].PhysicalLocation.Region.StartColumn != 9 {
+		t.Fatalf("unexpected location: %s", b)
+	}
+	if len(r.RelatedLocations) != 1 {
+		t.Fatalf("expected 1 related location: %s", b)
+	}
+
+	// Unknown codes are reported under the internal rule, without a location.
+	if r := run.Results[1]; r.RuleID != CodeInternal || len(r.Locations) != 0 {
+		t.Fatalf("unexpected result for unknown code: %+v", r)
+	}
+}
+
+func TestRules_CoverEveryCode(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "codes.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parse codes.go: %v", err)
+	}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for i, name := range spec.(*ast.ValueSpec).Names {
+				if !strings.HasPrefix(name.Name, "Code") {
+					continue
+				}
+				code, err := strconv.Unquote(spec.(*ast.ValueSpec).Values[i].(*ast.BasicLit).Value)
+				if err != nil {
+					t.Fatalf("%s: %v", name.Name, err)
+				}
+				if RuleIndex(code) < 0 {
+					t.Errorf("%s (%q) has no entry in Rules", name.Name, code)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position returns the 1-based line and column (in runes) of the value at pointer in src.
+// If pointer does not fully resolve, the position of its deepest existing ancestor is
+// returned. It returns 0, 0 if src is not a JSON document.
+func Position(src []byte, pointer string) (line, col int) {
+	s := &scanner{src: src}
+	off, ok := s.find(splitPointer(pointer))
+	if !ok {
+		return 0, 0
+	}
+	return lineCol(src, off)
+}
+
+// OffsetPosition returns the 1-based line and column (in runes) of byte offset off in src.
+func OffsetPosition(src []byte, off int) (line, col int) {
+	if off < 0 {
+		off = 0
+	}
+	if off > len(src) {
+		off = len(src)
+	}
+	return lineCol(src, off)
+}
+
+func lineCol(src []byte, off int) (int, int) {
+	line, start := 1, 0
+	for i := 0; i < off && i < len(src); i++ {
+		if src[i] == '\n' {
+			line++
+			start = i + 1
+		}
+	}
+	return line, utf8.RuneCount(src[start:off]) + 1
+}
+
+func splitPointer(pointer string) []string {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil
+	}
+	segs := strings.Split(pointer[1:], "/")
+	for i, s := range segs {
+		segs[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return segs
+}
+
+func joinPointer(segs []string) string {
+	var b strings.Builder
+	for _, s := range segs {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// scanner is a minimal JSON scanner that tracks byte offsets.
+type scanner struct {
+	src []byte
+	pos int
+}
+
+// find returns the offset of the value at segs (or of its deepest existing ancestor).
+func (s *scanner) find(segs []string) (int, bool) {
+	s.skipWS()
+	if s.pos >= len(s.src) {
+		return 0, false
+	}
+	start := s.pos
+	if len(segs) == 0 {
+		return start, true
+	}
+	switch s.src[s.pos] {
+	case '{':
+		s.pos++
+		for {
+			s.skipWS()
+			if s.pos >= len(s.src) || s.src[s.pos] == '}' {
+				return start, true
+			}
+			key, ok := s.readString()
+			if !ok {
+				return start, true
+			}
+			s.skipWS()
+			if s.pos >= len(s.src) || s.src[s.pos] != ':' {
+				return start, true
+			}
+			s.pos++
+			if key == segs[0] {
+				if off, ok := s.find(segs[1:]); ok {
+					return off, true
+				}
+				return start, true
+			}
+			if !s.skipValue() {
+				return start, true
+			}
+			s.skipWS()
+			if s.pos < len(s.src) && s.src[s.pos] == ',' {
+				s.pos++
+			}
+		}
+	case '[':
+		idx, err := strconv.Atoi(segs[0])
+		if err != nil {
+			return start, true
+		}
+		s.pos++
+		for i := 0; ; i++ {
+			s.skipWS()
+			if s.pos >= len(s.src) || s.src[s.pos] == ']' {
+				return start, true
+			}
+			if i == idx {
+				if off, ok := s.find(segs[1:]); ok {
+					return off, true
+				}
+				return start, true
+			}
+			if !s.skipValue() {
+				return start, true
+			}
+			s.skipWS()
+			if s.pos < len(s.src) && s.src[s.pos] == ',' {
+				s.pos++
+			}
+		}
+	default:
+		return start, true
+	}
+}
+
+func (s *scanner) skipWS() {
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *scanner) readString() (string, bool) {
+	if s.pos >= len(s.src) || s.src[s.pos] != '"' {
+		return "", false
+	}
+	start := s.pos
+	if !s.skipString() {
+		return "", false
+	}
+	var out string
+	if err := json.Unmarshal(s.src[start:s.pos], &out); err != nil {
+		return "", false
+	}
+	return out, true
+}
+
+func (s *scanner) skipString() bool {
+	s.pos++ // opening quote
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return true
+		default:
+			s.pos++
+		}
+	}
+	return false
+}
+
+func (s *scanner) skipValue() bool {
+	s.skipWS()
+	if s.pos >= len(s.src) {
+		return false
+	}
+	switch s.src[s.pos] {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.src) {
+			switch s.src[s.pos] {
+			case '"':
+				if !s.skipString() {
+					return false
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					s.pos++
+					return true
+				}
+			}
+			s.pos++
+		}
+		return false
+	default:
+		end := bytes.IndexAny(s.src[s.pos:], ",}] \t\r\n")
+		if end < 0 {
+			s.pos = len(s.src)
+		} else {
+			s.pos += end
+		}
+		return true
+	}
+}
@@ -0,0 +1,140 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// identityFields are the object members that identify an element of an array osspec sorts
+// during normalization (rules, vectors, data contracts, predicates, references, ...).
+var identityFields = [][]string{
+	{"key"},
+	{"rule_key"},
+	{"dataset", "version"},
+	{"url"},
+	{"framework", "control", "enhancement"},
+	{"path", "left_path", "right_path", "op", "value_param"},
+}
+
+// Remap translates pointer from the normalized form of a document into its source form.
+// Normalization sorts arrays, so array indices are matched by identity (see identityFields)
+// rather than position. Segments that have no counterpart in the source (for example
+// defaults added during normalization) are kept as is.
+func Remap(normalized, source any, pointer string) string {
+	segs := splitPointer(pointer)
+	if len(segs) == 0 {
+		return pointer
+	}
+	out := make([]string, 0, len(segs))
+	n, s := normalized, source
+	for i, seg := range segs {
+		switch nv := n.(type) {
+		case map[string]any:
+			sv, ok := s.(map[string]any)
+			if !ok {
+				return joinPointer(append(out, segs[i:]...))
+			}
+			out = append(out, seg)
+			n, s = nv[seg], sv[seg]
+		case []any:
+			sv, ok := s.([]any)
+			idx, err := strconv.Atoi(seg)
+			if !ok || err != nil || idx < 0 || idx >= len(nv) {
+				return joinPointer(append(out, segs[i:]...))
+			}
+			j := matchElement(nv, sv, idx)
+			if j < 0 {
+				return joinPointer(append(out, segs[i:]...))
+			}
+			out = append(out, strconv.Itoa(j))
+			n, s = nv[idx], sv[j]
+		default:
+			return joinPointer(append(out, segs[i:]...))
+		}
+	}
+	return joinPointer(out)
+}
+
+// matchElement returns the index in src of the element that corresponds to norm[idx].
+// Among elements with equal identity, the k-th normalized occurrence maps to the k-th
+// source occurrence.
+func matchElement(norm, src []any, idx int) int {
+	id := identity(norm[idx])
+	if id == nil {
+		if idx < len(src) {
+			return idx
+		}
+		return -1
+	}
+	occurrence := 0
+	for i := 0; i < idx; i++ {
+		if reflect.DeepEqual(identity(norm[i]), id) {
+			occurrence++
+		}
+	}
+	for j := range src {
+		if reflect.DeepEqual(identity(src[j]), id) {
+			if occurrence == 0 {
+				return j
+			}
+			occurrence--
+		}
+	}
+	return -1
+}
+
+func identity(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	for _, fields := range identityFields {
+		id := map[string]any{}
+		for _, f := range fields {
+			if fv, ok := m[f]; ok {
+				id[f] = fv
+			}
+		}
+		if len(id) > 0 {
+			return id
+		}
+	}
+	return groupIdentity(m)
+}
+
+// groupIdentity identifies a boolean predicate group by the identities of its members.
+// Normalization sorts the members, so their order is ignored.
+func groupIdentity(m map[string]any) any {
+	for _, g := range []string{"all_of", "any_of"} {
+		members, ok := m[g].([]any)
+		if !ok {
+			continue
+		}
+		ids := make([]string, 0, len(members))
+		for _, v := range members {
+			b, _ := json.Marshal(identity(v))
+			ids = append(ids, string(b))
+		}
+		slices.Sort(ids)
+		return map[string]any{g: ids}
+	}
+	if n, ok := m["not"]; ok {
+		return map[string]any{"not": identity(n)}
+	}
+	return nil
+}
+
+// Decode decodes b for use with Remap. Numbers are decoded as json.Number so that values
+// compare exactly.
+func Decode(b []byte) any {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package diag
+
+// Rule describes a diagnostic code. Rules are published as SARIF reporting descriptors, so
+// their order and IDs are stable: new rules are appended.
+type Rule struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+var Rules = []Rule{
+	{CodeInternal, "Internal", "osspec failed for a reason that is not tied to a spec document."},
+	{CodeLoad, "LoadError", "A spec file could not be read."},
+	{CodeSymlink, "SymlinkNotAllowed", "Symlinks are not allowed in the specs tree."},
+	{CodeFileTooLarge, "FileTooLarge", "Spec files must not exceed 2 MiB."},
+	{CodeInvalidJSON, "InvalidJSON", "The document is not valid JSON."},
+	{CodeUnsupportedSchemaVersion, "UnsupportedSchemaVersion", "The document's schema_version is not supported."},
+	{CodeUnknownKind, "UnknownKind", "The document's kind is unknown or not allowed under specs."},
+	{CodeSchema, "SchemaViolation", "The document does not conform to the metaschema for its kind."},
+	{CodeDuplicateKey, "DuplicateKey", "Keys must be unique (ruleset, rule, dataset contract, connector, profile, suite, vector)."},
+	{CodeScope, "InvalidScope", "scope.connector_kind is required for connector_instance scopes and forbidden for global scopes."},
+	{CodeParametersSchema, "ParametersSchema", "3.3: parameters.defaults and parameters.schema must declare the same keys, and each default must match its schema (type, minimum, maximum, enum)."},
+	{CodeMonitoringCheck, "MonitoringCheck", "6.3: monitoring.status must be consistent with the presence and type of the check."},
+	{CodeCheckType, "CheckType", "6.4: check.type must be a known check type."},
+	{CodeCheckFields, "CheckFields", "6.4: the check must set exactly the fields its type requires."},
+	{CodeRequiredDataCoverage, "RequiredDataCoverage", "6.5: required_data must include every dataset the check reads."},
+	{CodeDatasetVersion, "DatasetVersion", "6.6: dataset versions must be declared and unambiguous."},
+	{CodeValueParam, "ValueParam", "6.7: value_param must name a parameter with a default whose type the operator accepts (in: array; lt/lte/gt/gte: number; matches/starts_with/ends_with/eq_ignore_case: string, a valid RE2 regular expression for matches; older_than/newer_than: an ISO-8601 duration string; check.compare: integer, or any number for dataset.aggregate_compare)."},
+	{CodePredicate, "PredicateStructure", "6.8: predicates must be well formed for their operator."},
+	{CodeCompare, "CompareStructure", "check.compare must set an operator and exactly one of value or value_param; value must be an integer except for dataset.aggregate_compare."},
+	{CodeUnresolvedReference, "UnresolvedReference", "References to other documents must resolve."},
+	{CodeConnectorCoverage, "ConnectorCoverage", "Connectors must provide the datasets read from them: in connector_instance rulesets, the scope.connector_kind connector every dataset the ruleset reads; in global rulesets, the connector of each connector_kind qualifier the dataset it qualifies."},
+	{CodeUnknownPath, "UnknownPath", "JSON pointers must resolve in the dataset contract schema."},
+	{CodeTypeMismatch, "TypeMismatch", "Operators and literal values must match the type of the field they apply to."},
+	{CodeConformanceVector, "ConformanceVector", "Conformance vectors must reference a rule and provide fixtures for the datasets it reads."},
+	{CodeContractCompat, "ContractCompat", "Published dataset contract versions must stay backward compatible with the baseline descriptor."},
+	{CodeProfileOverride, "ProfileOverride", "Profile rule overrides must name a rule of the ruleset and only override parameters it declares, with values that match its parameters.schema."},
+	{CodeProfileExtends, "ProfileExtends", "Profiles must not extend themselves, directly or through other profiles, and the profiles one extends must not include a ruleset at different versions or override a rule differently unless the extending profile pins the version or overrides the rule itself."},
+	{CodeRuleLifecycle, "RuleLifecycle", "lifecycle.replaced_by must name an existing rule, and replacement chains must not loop back on themselves."},
+}
+
+// RuleIndex returns the index of code in Rules, or -1.
+func RuleIndex(code string) int {
+	for i, r := range Rules {
+		if r.ID == code {
+			return i
+		}
+	}
+	return -1
+}
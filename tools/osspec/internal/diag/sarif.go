@@ -0,0 +1,162 @@
+package diag
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// srcRoot is the uriBaseId file locations are relative to (the repo root).
+	srcRoot = "%SRCROOT%"
+)
+
+// SARIFLog is a SARIF 2.1.0 log with a single osspec run. Only the properties osspec
+// populates are modelled.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	Results    []sarifResult `json:"results"`
+	ColumnKind string        `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	LogicalLocations []sarifLogical        `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogical struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIF converts l into a SARIF log. Every rule in Rules is listed so rule indices are
+// stable across runs; file locations are relative to the repo root (%SRCROOT%) and carry
+// the JSON pointer as a logical location.
+func SARIF(l List, toolVersion string) SARIFLog {
+	rules := make([]sarifRule, 0, len(Rules))
+	for _, r := range Rules {
+		rules = append(rules, sarifRule{
+			ID:               r.ID,
+			Name:             r.Name,
+			ShortDescription: sarifMessage{Text: r.Description},
+			DefaultConfig:    sarifConfig{Level: "error"},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(l))
+	for _, d := range l {
+		idx := RuleIndex(d.Code)
+		if idx < 0 {
+			idx = RuleIndex(CodeInternal)
+		}
+		res := sarifResult{
+			RuleID:    Rules[idx].ID,
+			RuleIndex: idx,
+			Level:     sarifLevel(d.Severity),
+			Message:   sarifMessage{Text: d.Message},
+		}
+		if loc, ok := sarifLocationOf(d.Location); ok {
+			res.Locations = []sarifLocation{loc}
+		}
+		for i, rel := range d.Related {
+			if loc, ok := sarifLocationOf(rel); ok {
+				id := i
+				loc.ID = &id
+				res.RelatedLocations = append(res.RelatedLocations, loc)
+			}
+		}
+		results = append(results, res)
+	}
+
+	return SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "osspec",
+				InformationURI: "https://github.com/open-sspm/open-sspm-spec",
+				Version:        toolVersion,
+				Rules:          rules,
+			}},
+			Results:    results,
+			ColumnKind: "unicodeCodePoints",
+		}},
+	}
+}
+
+func sarifLocationOf(l Location) (sarifLocation, bool) {
+	if l.File == "" {
+		return sarifLocation{}, false
+	}
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: l.File, URIBaseID: srcRoot},
+	}}
+	if l.Line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: l.Line, StartColumn: l.Column}
+	}
+	if l.Pointer != "" {
+		loc.LogicalLocations = []sarifLogical{{FullyQualifiedName: l.Pointer, Kind: "object"}}
+	}
+	return loc, true
+}
+
+func sarifLevel(s Severity) string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
@@ -0,0 +1,724 @@
+// Package diff compares two compiled descriptors and reports a semantic changelog: which
+// documents, rules and fields were added, removed or changed, and whether each change is
+// breaking for evaluators and connectors built against the old descriptor.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/schemasem"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is a single entry of the changelog. Rule is set for changes inside a ruleset rule;
+// Field names the changed top-level field of the document or rule, if any.
+type Change struct {
+	Kind     string          `json:"kind"`
+	Key      string          `json:"key"`
+	Rule     string          `json:"rule,omitempty"`
+	Field    string          `json:"field,omitempty"`
+	Change   string          `json:"change"`
+	Breaking bool            `json:"breaking"`
+	Old      json.RawMessage `json:"old,omitempty"`
+	New      json.RawMessage `json:"new,omitempty"`
+	// Reasons explains why a change is breaking when that depends on its content, such as
+	// the incompatibilities found in a changed dataset contract schema.
+	Reasons []string `json:"reasons,omitempty"`
+	// Previous is set on an added dataset contract version to the previous version of the
+	// same dataset ("key@version"). Reasons then lists the incompatibilities of the added
+	// version with it; without Reasons the added version is backward compatible.
+	Previous string `json:"previous,omitempty"`
+}
+
+type Report struct {
+	OldSpecVersion string   `json:"old_spec_version"`
+	NewSpecVersion string   `json:"new_spec_version"`
+	Breaking       int      `json:"breaking"`
+	Changes        []Change `json:"changes"`
+}
+
+// Rule fields whose changes alter what a rule evaluates or what data it needs. Other rule
+// fields (title, severity, references, ...) are reported as non-breaking.
+var breakingRuleFields = map[string]bool{
+	"check":         true,
+	"parameters":    true,
+	"required_data": true,
+}
+
+// Ruleset fields whose changes are breaking. Rules are compared individually.
+var breakingRulesetFields = map[string]bool{
+	"key":            true,
+	"scope":          true,
+	"data_contracts": true,
+}
+
+// Profile rule override fields whose changes alter which rules run or how they evaluate.
+// Severity and rationale changes only affect reporting.
+var breakingOverrideFields = map[string]bool{
+	"exclude":    true,
+	"parameters": true,
+}
+
+// Descriptors compares old and new. Documents whose artifact hashes are equal in both
+// artifact indexes are skipped without being compared field by field.
+func Descriptors(old, new *types.DescriptorV1) (*Report, error) {
+	d := &differ{}
+	oldHashes := artifactHashes(old)
+	newHashes := artifactHashes(new)
+	// A ruleset whose version changed is compared with its previous version under the new
+	// ruleset ID.
+	pairs := pairRulesetVersions(old, new)
+	for newID, oldID := range pairs {
+		ok := artifactKey{"opensspm.ruleset", oldID}
+		if h, found := oldHashes[ok]; found {
+			delete(oldHashes, ok)
+			oldHashes[artifactKey{"opensspm.ruleset", newID}] = h
+		}
+	}
+
+	for _, k := range unionKeys(oldHashes, newHashes) {
+		oh, inOld := oldHashes[k]
+		nh, inNew := newHashes[k]
+		if inOld && inNew && oh == nh {
+			continue
+		}
+		switch {
+		case !inNew:
+			d.add(Change{Kind: k.kind, Key: k.key, Change: Removed, Breaking: k.kind != "opensspm.conformance_suite"})
+			continue
+		case !inOld && k.kind == "opensspm.dataset_contract":
+			d.addedContract(k.key, old, new)
+			continue
+		case !inOld:
+			d.add(Change{Kind: k.kind, Key: k.key, Change: Added})
+			continue
+		}
+		var err error
+		switch k.kind {
+		case "opensspm.version":
+			err = d.version(&old.Version, &new.Version)
+		case "opensspm.dictionary":
+			err = d.dictionary(&old.Dictionary.Object, &new.Dictionary.Object)
+		case "opensspm.ruleset":
+			oldID := k.key
+			if id, ok := pairs[k.key]; ok {
+				oldID = id
+			}
+			err = d.ruleset(oldID, k.key, old, new)
+		case "opensspm.dataset_contract":
+			err = d.contract(k.key, old, new)
+		case "opensspm.connector_manifest":
+			err = d.connector(k.key, old, new)
+		case "opensspm.profile":
+			err = d.profile(k.key, old, new)
+		default:
+			// Conformance suites are not part of the descriptor; only the hash is known.
+			d.add(Change{Kind: k.kind, Key: k.key, Change: Changed})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := &Report{
+		OldSpecVersion: old.Version.SpecVersion,
+		NewSpecVersion: new.Version.SpecVersion,
+		Changes:        d.changes,
+	}
+	if r.Changes == nil {
+		r.Changes = []Change{}
+	}
+	for _, c := range r.Changes {
+		if c.Breaking {
+			r.Breaking++
+		}
+	}
+	return r, nil
+}
+
+type artifactKey struct {
+	kind, key string
+}
+
+func artifactHashes(desc *types.DescriptorV1) map[artifactKey]string {
+	out := map[artifactKey]string{}
+	for _, a := range desc.Index.Artifacts.Artifacts {
+		out[artifactKey{a.Kind, a.Key}] = a.Hash
+	}
+	return out
+}
+
+func unionKeys[V any](a, b map[artifactKey]V) []artifactKey {
+	var keys []artifactKey
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(x, y artifactKey) int {
+		if c := strings.Compare(x.kind, y.kind); c != 0 {
+			return c
+		}
+		return strings.Compare(x.key, y.key)
+	})
+	return keys
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+// fields compares the top-level JSON fields of old and new, skipping the fields in skip.
+// Changes to the fields in breaking are breaking.
+func (d *differ) fields(base Change, old, new any, breaking map[string]bool, skip ...string) error {
+	om, err := jsonFields(old)
+	if err != nil {
+		return err
+	}
+	nm, err := jsonFields(new)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(om)+len(nm))
+	for name := range om {
+		names = append(names, name)
+	}
+	for name := range nm {
+		if _, ok := om[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if slices.Contains(skip, name) {
+			continue
+		}
+		ov, nv := om[name], nm[name]
+		if bytes.Equal(ov, nv) {
+			continue
+		}
+		c := base
+		c.Field = name
+		c.Old = ov
+		c.New = nv
+		c.Breaking = breaking[name]
+		switch {
+		case ov == nil:
+			c.Change = Added
+		case nv == nil:
+			c.Change = Removed
+		default:
+			c.Change = Changed
+		}
+		d.add(c)
+	}
+	return nil
+}
+
+func jsonFields(v any) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("diff: encode: %w", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("diff: decode: %w", err)
+	}
+	return m, nil
+}
+
+func (d *differ) version(old, new *types.Version) error {
+	return d.fields(Change{Kind: "opensspm.version", Key: "version"}, old, new, map[string]bool{"schema_version": true})
+}
+
+// dictionary reports enum values added to (non-breaking) and removed from (breaking) each
+// dictionary enum.
+func (d *differ) dictionary(old, new *types.DictionaryDoc) error {
+	oe, ne := old.Dictionary.Enums, new.Dictionary.Enums
+	names := make([]string, 0, len(oe)+len(ne))
+	for name := range oe {
+		names = append(names, name)
+	}
+	for name := range ne {
+		if _, ok := oe[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		d.set(Change{Kind: "opensspm.dictionary", Key: "dictionary", Field: "enums." + name}, oe[name], ne[name], true)
+	}
+	return nil
+}
+
+// set reports the values removed from and added to a set-valued field. Removals are
+// breaking if removalBreaks, additions if not.
+func (d *differ) set(base Change, old, new []string, removalBreaks bool) {
+	var removed, added []string
+	for _, v := range old {
+		if !slices.Contains(new, v) {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range new {
+		if !slices.Contains(old, v) {
+			added = append(added, v)
+		}
+	}
+	if len(removed) > 0 {
+		c := base
+		c.Change = Removed
+		c.Breaking = removalBreaks
+		c.Old = mustJSON(removed)
+		d.add(c)
+	}
+	if len(added) > 0 {
+		c := base
+		c.Change = Added
+		c.Breaking = !removalBreaks
+		c.New = mustJSON(added)
+		d.add(c)
+	}
+}
+
+// ruleset compares ruleset oldID of old with ruleset newID of new. The IDs differ when the
+// ruleset version changed (see pairRulesetVersions); changes are keyed by newID.
+func (d *differ) ruleset(oldID, newID string, old, new *types.DescriptorV1) error {
+	o, n := findRuleset(old, oldID), findRuleset(new, newID)
+	if o == nil || n == nil {
+		return fmt.Errorf("diff: ruleset %q is in the artifacts index but not in the descriptor", newID)
+	}
+	base := Change{Kind: "opensspm.ruleset", Key: newID}
+	if err := d.fields(base, o, n, breakingRulesetFields, "rules"); err != nil {
+		return err
+	}
+
+	oldRules := map[string]*types.Rule{}
+	var order []string
+	for i := range o.Rules {
+		oldRules[o.Rules[i].Key] = &o.Rules[i]
+		order = append(order, o.Rules[i].Key)
+	}
+	newRules := map[string]*types.Rule{}
+	for i := range n.Rules {
+		newRules[n.Rules[i].Key] = &n.Rules[i]
+		if _, ok := oldRules[n.Rules[i].Key]; !ok {
+			order = append(order, n.Rules[i].Key)
+		}
+	}
+	slices.Sort(order)
+
+	oldReqs, newReqs := ruleDatasets(old, oldID), ruleDatasets(new, newID)
+	for _, rk := range order {
+		c := base
+		c.Rule = rk
+		or, nr := oldRules[rk], newRules[rk]
+		switch {
+		case nr == nil:
+			c.Change = Removed
+			c.Breaking = true
+			d.add(c)
+		case or == nil:
+			c.Change = Added
+			d.add(c)
+		default:
+			if err := d.fields(c, or, nr, breakingRuleFields, "monitoring"); err != nil {
+				return err
+			}
+			if or.Monitoring != nr.Monitoring {
+				c.Field = "monitoring"
+				c.Change = Changed
+				// Moving between automated and manual changes how the rule is evaluated.
+				c.Breaking = or.Monitoring.Status != nr.Monitoring.Status
+				c.Old, c.New = mustJSON(or.Monitoring), mustJSON(nr.Monitoring)
+				d.add(c)
+			}
+			c.Field = "datasets"
+			d.set(c, oldReqs[rk], newReqs[rk], false)
+		}
+	}
+	return nil
+}
+
+func findRuleset(desc *types.DescriptorV1, key string) *types.Ruleset {
+	for i := range desc.Rulesets {
+		if desc.Rulesets[i].Object.Ruleset.ID() == key {
+			return &desc.Rulesets[i].Object.Ruleset
+		}
+	}
+	return nil
+}
+
+// pairRulesetVersions pairs each ruleset version that is only in new with a version of the
+// same ruleset key that is only in old, so that a version bump is compared rule by rule
+// instead of being reported as a removal and an addition. Versions are paired from the
+// highest down; unversioned rulesets rank lowest. It returns the old ruleset ID (see
+// Ruleset.ID) of each paired new ruleset ID.
+func pairRulesetVersions(old, new *types.DescriptorV1) map[string]string {
+	oldOnly, newOnly := unmatchedRulesets(old, new), unmatchedRulesets(new, old)
+	pairs := map[string]string{}
+	for key, nrs := range newOnly {
+		ors := oldOnly[key]
+		for i := 0; i < len(nrs) && i < len(ors); i++ {
+			pairs[nrs[i].ID()] = ors[i].ID()
+		}
+	}
+	return pairs
+}
+
+// unmatchedRulesets returns the rulesets of desc whose ID is not in other, by key, in
+// descending version order.
+func unmatchedRulesets(desc, other *types.DescriptorV1) map[string][]*types.Ruleset {
+	out := map[string][]*types.Ruleset{}
+	for i := range desc.Rulesets {
+		rs := &desc.Rulesets[i].Object.Ruleset
+		if findRuleset(other, rs.ID()) == nil {
+			out[rs.Key] = append(out[rs.Key], rs)
+		}
+	}
+	for _, rs := range out {
+		slices.SortFunc(rs, func(a, b *types.Ruleset) int {
+			return semver.CompareStrings(b.Version, a.Version)
+		})
+	}
+	return out
+}
+
+// ruleDatasets returns the dataset references (DatasetRefSpec.String) each rule of the
+// ruleset (by Ruleset.ID) reads, from the descriptor's requirements index.
+func ruleDatasets(desc *types.DescriptorV1, rulesetID string) map[string][]string {
+	out := map[string][]string{}
+	for _, rs := range desc.Index.Requirements.Rulesets {
+		if id := (&types.Ruleset{Key: rs.RulesetKey, Version: rs.RulesetVersion}).ID(); id != rulesetID {
+			continue
+		}
+		for _, r := range rs.Rules {
+			for _, ds := range r.Datasets {
+				out[r.RuleKey] = append(out[r.RuleKey], ds.String())
+			}
+		}
+	}
+	return out
+}
+
+func (d *differ) contract(key string, old, new *types.DescriptorV1) error {
+	o, n := findContract(old, key), findContract(new, key)
+	if o == nil || n == nil {
+		return fmt.Errorf("diff: dataset contract %q is in the artifacts index but not in the descriptor", key)
+	}
+	// Schema changes are breaking only if they are incompatible (see
+	// schemasem.CompareContracts); primary_key changes always are.
+	var reasons []string
+	for _, inc := range schemasem.CompareContracts(o, n) {
+		if strings.HasPrefix(inc.Pointer, "/dataset/schema") {
+			reasons = append(reasons, inc.Message)
+		}
+	}
+	start := len(d.changes)
+	breaking := map[string]bool{"primary_key": true, "schema": len(reasons) > 0}
+	if err := d.fields(Change{Kind: "opensspm.dataset_contract", Key: key}, o, n, breaking); err != nil {
+		return err
+	}
+	for i := start; i < len(d.changes); i++ {
+		if d.changes[i].Field == "schema" {
+			d.changes[i].Reasons = reasons
+		}
+	}
+	return nil
+}
+
+// addedContract reports a new dataset contract version, compared with the previous version
+// of the same dataset in new (or, if new no longer has one, in old). Incompatibilities are
+// not breaking: rules and connectors keep using the previous version until they move.
+func (d *differ) addedContract(key string, old, new *types.DescriptorV1) {
+	c := Change{Kind: "opensspm.dataset_contract", Key: key, Change: Added}
+	n := findContract(new, key)
+	if n == nil {
+		d.add(c)
+		return
+	}
+	prev := previousContract(new, n)
+	if prev == nil {
+		prev = previousContract(old, n)
+	}
+	if prev != nil {
+		c.Previous = fmt.Sprintf("%s@%d", prev.Key, prev.Version)
+		for _, inc := range schemasem.CompareContracts(prev, n) {
+			c.Reasons = append(c.Reasons, inc.Message)
+		}
+	}
+	d.add(c)
+}
+
+// previousContract returns the highest version of dc's dataset in desc that is lower than
+// dc's, or nil.
+func previousContract(desc *types.DescriptorV1, dc *types.DatasetContract) *types.DatasetContract {
+	var prev *types.DatasetContract
+	for i := range desc.DatasetContracts {
+		c := &desc.DatasetContracts[i].Object.Dataset
+		if c.Key == dc.Key && c.Version < dc.Version && (prev == nil || c.Version > prev.Version) {
+			prev = c
+		}
+	}
+	return prev
+}
+
+func findContract(desc *types.DescriptorV1, key string) *types.DatasetContract {
+	for i := range desc.DatasetContracts {
+		dc := &desc.DatasetContracts[i].Object.Dataset
+		if fmt.Sprintf("%s@%d", dc.Key, dc.Version) == key {
+			return dc
+		}
+	}
+	return nil
+}
+
+func (d *differ) connector(key string, old, new *types.DescriptorV1) error {
+	o, n := findConnector(old, key), findConnector(new, key)
+	if o == nil || n == nil {
+		return fmt.Errorf("diff: connector manifest %q is in the artifacts index but not in the descriptor", key)
+	}
+	base := Change{Kind: "opensspm.connector_manifest", Key: key}
+	if err := d.fields(base, o, n, nil, "provides"); err != nil {
+		return err
+	}
+	base.Field = "provides"
+	d.set(base, datasetRefs(o.Provides), datasetRefs(n.Provides), true)
+	return nil
+}
+
+func findConnector(desc *types.DescriptorV1, key string) *types.ConnectorManifest {
+	for i := range desc.Connectors {
+		if desc.Connectors[i].Object.Connector.Kind == key {
+			return &desc.Connectors[i].Object.Connector
+		}
+	}
+	return nil
+}
+
+func datasetRefs(refs []types.DatasetRefSpec) []string {
+	out := make([]string, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, fmt.Sprintf("%s@%d", r.Dataset, r.Version))
+	}
+	return out
+}
+
+func (d *differ) profile(key string, old, new *types.DescriptorV1) error {
+	o, n := findProfile(old, key), findProfile(new, key)
+	if o == nil || n == nil {
+		return fmt.Errorf("diff: profile %q is in the artifacts index but not in the descriptor", key)
+	}
+	base := Change{Kind: "opensspm.profile", Key: key}
+	if err := d.fields(base, o, n, nil, "rulesets"); err != nil {
+		return err
+	}
+	base.Field = "rulesets"
+	d.profileRulesets(base, o.Rulesets, n.Rulesets)
+	return d.overrides(Change{Kind: "opensspm.profile", Key: key}, o, n)
+}
+
+// profileRulesets reports the rulesets a profile stopped including (breaking), started
+// including, and those whose version constraint changed. A profile that moves to another
+// version of a ruleset is reported as changed; the ruleset changes themselves are reported
+// on the ruleset.
+func (d *differ) profileRulesets(base Change, old, new []types.ProfileRulesetRef) {
+	oldRefs := map[string]*types.ProfileRulesetRef{}
+	for i := range old {
+		oldRefs[old[i].Key] = &old[i]
+	}
+	newRefs := map[string]*types.ProfileRulesetRef{}
+	for i := range new {
+		newRefs[new[i].Key] = &new[i]
+	}
+	var removed, added []types.ProfileRulesetRef
+	for _, r := range old {
+		if newRefs[r.Key] == nil {
+			removed = append(removed, r)
+		}
+	}
+	for _, r := range new {
+		if oldRefs[r.Key] == nil {
+			added = append(added, r)
+		}
+	}
+	d.set(base, profileRefs(removed), profileRefs(added), true)
+	for _, r := range new {
+		if o := oldRefs[r.Key]; o != nil && o.Version != r.Version {
+			c := base
+			c.Change = Changed
+			c.Old = mustJSON(profileRefs([]types.ProfileRulesetRef{*o})[0])
+			c.New = mustJSON(profileRefs([]types.ProfileRulesetRef{r})[0])
+			d.add(c)
+		}
+	}
+}
+
+// overrides reports rule overrides added, removed or changed for rulesets both profiles
+// include. Changes are keyed by rule as "<ruleset key>#<rule key>".
+func (d *differ) overrides(base Change, old, new *types.Profile) error {
+	oldRefs := map[string]*types.ProfileRulesetRef{}
+	for i := range old.Rulesets {
+		oldRefs[old.Rulesets[i].Key] = &old.Rulesets[i]
+	}
+	for _, nref := range new.Rulesets {
+		oref := oldRefs[nref.Key]
+		if oref == nil {
+			continue
+		}
+		oldOverrides := map[string]*types.RuleOverride{}
+		var order []string
+		for i := range oref.Overrides {
+			oldOverrides[oref.Overrides[i].RuleKey] = &oref.Overrides[i]
+			order = append(order, oref.Overrides[i].RuleKey)
+		}
+		newOverrides := map[string]*types.RuleOverride{}
+		for i := range nref.Overrides {
+			newOverrides[nref.Overrides[i].RuleKey] = &nref.Overrides[i]
+			if _, ok := oldOverrides[nref.Overrides[i].RuleKey]; !ok {
+				order = append(order, nref.Overrides[i].RuleKey)
+			}
+		}
+		slices.Sort(order)
+		for _, rk := range order {
+			c := base
+			c.Rule = nref.Key + "#" + rk
+			oo, no := oldOverrides[rk], newOverrides[rk]
+			switch {
+			case no == nil:
+				c.Field = "overrides"
+				c.Change = Removed
+				c.Breaking = oo.Exclude || len(oo.Parameters) > 0
+				c.Old = mustJSON(oo)
+				d.add(c)
+			case oo == nil:
+				c.Field = "overrides"
+				c.Change = Added
+				c.Breaking = no.Exclude || len(no.Parameters) > 0
+				c.New = mustJSON(no)
+				d.add(c)
+			default:
+				if err := d.fields(c, oo, no, breakingOverrideFields); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func findProfile(desc *types.DescriptorV1, key string) *types.Profile {
+	for i := range desc.Profiles {
+		if desc.Profiles[i].Object.Profile.Key == key {
+			return &desc.Profiles[i].Object.Profile
+		}
+	}
+	return nil
+}
+
+func profileRefs(refs []types.ProfileRulesetRef) []string {
+	out := make([]string, 0, len(refs))
+	for _, r := range refs {
+		s := r.Key
+		if r.Version != "" {
+			s += "@" + r.Version
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func mustJSON(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// WriteText writes the report as a human-readable changelog, one line per change, followed
+// by a summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	var buf bytes.Buffer
+	for _, c := range r.Changes {
+		mark := "          "
+		if c.Breaking {
+			mark = "BREAKING  "
+		}
+		buf.WriteString(mark)
+		buf.WriteString(c.Kind)
+		buf.WriteString(" ")
+		buf.WriteString(c.Key)
+		if c.Rule != "" {
+			buf.WriteString(" rule ")
+			buf.WriteString(c.Rule)
+		}
+		if c.Field != "" {
+			buf.WriteString(": ")
+			buf.WriteString(c.Field)
+		}
+		buf.WriteString(" ")
+		buf.WriteString(c.Change)
+		switch {
+		case c.Previous != "" && len(c.Reasons) > 0:
+			buf.WriteString(" (incompatible with " + c.Previous + ")")
+		case c.Previous != "":
+			buf.WriteString(" (backward compatible with " + c.Previous + ")")
+		}
+		if v := shortValue(c); v != "" {
+			buf.WriteString(": ")
+			buf.WriteString(v)
+		}
+		buf.WriteString("\n")
+		for _, r := range c.Reasons {
+			buf.WriteString("            - ")
+			buf.WriteString(r)
+			buf.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&buf, "%d changes (%d breaking)", len(r.Changes), r.Breaking)
+	if r.OldSpecVersion != r.NewSpecVersion {
+		fmt.Fprintf(&buf, ", spec_version %s -> %s", r.OldSpecVersion, r.NewSpecVersion)
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// shortValue renders the old and new values of a change if they fit on a line. Large values
+// (checks, schemas) are left to the JSON output.
+func shortValue(c Change) string {
+	const maxLen = 80
+	var s string
+	switch {
+	case c.Old != nil && c.New != nil:
+		s = string(c.Old) + " -> " + string(c.New)
+	case c.New != nil:
+		s = string(c.New)
+	case c.Old != nil:
+		s = string(c.Old)
+	}
+	if len(s) > maxLen {
+		return ""
+	}
+	return s
+}
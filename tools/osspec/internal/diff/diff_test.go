@@ -0,0 +1,342 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/hash"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+const rulesetKey = "cis.okta.idaas_stig.v1@1.0.0"
+
+func loadDistDescriptor(t *testing.T) *types.DescriptorV1 {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(testutil.RepoRoot(t), "dist", "descriptor.v1.json"))
+	if err != nil {
+		t.Fatalf("read descriptor: %v", err)
+	}
+	var d types.DescriptorV1
+	if err := json.Unmarshal(b, &d); err != nil {
+		t.Fatalf("decode descriptor: %v", err)
+	}
+	return &d
+}
+
+// rehash updates the artifacts index entry of the ruleset after its document was edited.
+func rehash(t *testing.T, d *types.DescriptorV1, key string) {
+	t.Helper()
+	for _, rs := range d.Rulesets {
+		if rs.Object.Ruleset.ID() != key {
+			continue
+		}
+		h, _, err := hash.HashObjectJCS(rs.Object)
+		if err != nil {
+			t.Fatalf("hash: %v", err)
+		}
+		for i := range d.Index.Artifacts.Artifacts {
+			a := &d.Index.Artifacts.Artifacts[i]
+			if a.Kind == "opensspm.ruleset" && a.Key == key {
+				a.Hash = h
+			}
+		}
+	}
+}
+
+func summarize(r *Report) []string {
+	var out []string
+	for _, c := range r.Changes {
+		s := c.Kind + " " + c.Key
+		if c.Rule != "" {
+			s += " " + c.Rule
+		}
+		if c.Field != "" {
+			s += " " + c.Field
+		}
+		s += " " + c.Change
+		if c.Breaking {
+			s += " breaking"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestDescriptors_Identical(t *testing.T) {
+	r, err := Descriptors(loadDistDescriptor(t), loadDistDescriptor(t))
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	if len(r.Changes) != 0 || r.Breaking != 0 {
+		t.Fatalf("expected no changes, got %v", summarize(r))
+	}
+}
+
+func TestDescriptors_RuleChanges(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	rs := findRuleset(cur, rulesetKey)
+	if rs == nil || len(rs.Rules) < 3 {
+		t.Fatalf("ruleset %q not found or too small", rulesetKey)
+	}
+	removed := rs.Rules[0].Key
+	rs.Rules = rs.Rules[1:]
+	rs.Rules[0].Severity = types.SeverityCritical
+	if rs.Rules[0].Severity == findRuleset(old, rulesetKey).Rules[1].Severity {
+		rs.Rules[0].Severity = types.SeverityLow
+	}
+	rs.Rules[0].Title += " (updated)"
+	rs.Rules[1].RequiredData = append(rs.Rules[1].RequiredData, "okta:new-dataset")
+	rs.Rules = append(rs.Rules, types.Rule{Key: "NEW-RULE", Title: "New", Severity: types.SeverityLow, Monitoring: types.Monitoring{Status: types.MonitoringStatusManual}})
+	rehash(t, cur, rulesetKey)
+
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	prefix := "opensspm.ruleset " + rulesetKey + " "
+	want := []string{
+		prefix + "NEW-RULE added",
+		prefix + removed + " removed breaking",
+		prefix + rs.Rules[0].Key + " severity changed",
+		prefix + rs.Rules[0].Key + " title changed",
+		prefix + rs.Rules[1].Key + " required_data changed breaking",
+	}
+	got := summarize(r)
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == w
+		}
+		if !found {
+			t.Errorf("missing change %q; got:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d changes, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	if r.Breaking != 2 {
+		t.Errorf("Breaking = %d, want 2", r.Breaking)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if !strings.Contains(buf.String(), "BREAKING  "+prefix+"rule "+removed+" removed\n") || !strings.HasSuffix(buf.String(), "5 changes (2 breaking)\n") {
+		t.Errorf("unexpected text output:\n%s", buf.String())
+	}
+}
+
+func TestDescriptors_SkipsUnchangedHashes(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	// The document changes but its artifact hash does not, so it is not compared.
+	findRuleset(cur, rulesetKey).Rules[0].Title = "edited"
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	if len(r.Changes) != 0 {
+		t.Fatalf("expected no changes, got %v", summarize(r))
+	}
+}
+
+func TestDescriptors_ConnectorAndArtifacts(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	conn := &cur.Connectors[0].Object.Connector
+	conn.Provides = conn.Provides[1:]
+	conn.Provides = append(conn.Provides, types.DatasetRefSpec{Dataset: "okta:users", Version: 1})
+	for i := range cur.Index.Artifacts.Artifacts {
+		a := &cur.Index.Artifacts.Artifacts[i]
+		if a.Kind == "opensspm.connector_manifest" {
+			a.Hash = "changed"
+		}
+	}
+	cur.Index.Artifacts.Artifacts = append(cur.Index.Artifacts.Artifacts, types.Artifact{Kind: "opensspm.conformance_suite", Key: "conformance.new", Hash: "x"})
+
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	key := conn.Kind
+	want := []string{
+		"opensspm.conformance_suite conformance.new added",
+		"opensspm.connector_manifest " + key + " provides removed breaking",
+		"opensspm.connector_manifest " + key + " provides added",
+	}
+	if diff := cmp.Diff(want, summarize(r)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+	if got := string(r.Changes[2].New); got != `["okta:users@1"]` {
+		t.Errorf("provides added = %s", got)
+	}
+}
+
+func TestDescriptors_ContractSchemaCompatibility(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	dc := &cur.DatasetContracts[0].Object.Dataset
+	key := fmt.Sprintf("%s@%d", dc.Key, dc.Version)
+	for i := range cur.Index.Artifacts.Artifacts {
+		a := &cur.Index.Artifacts.Artifacts[i]
+		if a.Kind == "opensspm.dataset_contract" && a.Key == key {
+			a.Hash = "changed"
+		}
+	}
+
+	// Adding an optional property is compatible.
+	var schema map[string]any
+	if err := json.Unmarshal(dc.Schema, &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+	schema["properties"].(map[string]any)["new_field"] = map[string]any{"type": "string"}
+	dc.Schema, _ = json.Marshal(schema)
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	if diff := cmp.Diff([]string{"opensspm.dataset_contract " + key + " schema changed"}, summarize(r)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+
+	// Requiring it is not.
+	schema["required"] = append(schema["required"].([]any), "new_field")
+	dc.Schema, _ = json.Marshal(schema)
+	if r, err = Descriptors(old, cur); err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	if diff := cmp.Diff([]string{"opensspm.dataset_contract " + key + " schema changed breaking"}, summarize(r)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+	if want := []string{`field "/": property "new_field" is newly required`}; !cmp.Equal(want, r.Changes[0].Reasons) {
+		t.Errorf("Reasons = %q, want %q", r.Changes[0].Reasons, want)
+	}
+}
+
+func TestDescriptors_ProfileOverrides(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	p := &cur.Profiles[0].Object.Profile
+	for i := range cur.Index.Artifacts.Artifacts {
+		a := &cur.Index.Artifacts.Artifacts[i]
+		if a.Kind == "opensspm.profile" && a.Key == p.Key {
+			a.Hash = "changed"
+		}
+	}
+	ref := &p.Rulesets[0]
+	ref.Overrides = []types.RuleOverride{{RuleKey: "R1", Severity: types.SeverityLow}, {RuleKey: "R2", Exclude: true}}
+	old.Profiles[0].Object.Profile.Rulesets[0].Overrides = []types.RuleOverride{{RuleKey: "R1", Severity: types.SeverityHigh}}
+
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	prefix := "opensspm.profile " + p.Key + " " + ref.Key
+	want := []string{
+		prefix + "#R1 severity changed",
+		prefix + "#R2 overrides added breaking",
+	}
+	if diff := cmp.Diff(want, summarize(r)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDescriptors_RulesetVersionBump(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	rs := findRuleset(cur, rulesetKey)
+	rs.Version = "1.1.0"
+	if rs.Rules[0].Severity == types.SeverityLow {
+		rs.Rules[0].Severity = types.SeverityHigh
+	} else {
+		rs.Rules[0].Severity = types.SeverityLow
+	}
+	for i := range cur.Index.Artifacts.Artifacts {
+		a := &cur.Index.Artifacts.Artifacts[i]
+		if a.Kind == "opensspm.ruleset" && a.Key == rulesetKey {
+			a.Key = rs.ID()
+		}
+	}
+	rehash(t, cur, rs.ID())
+	for i := range cur.Index.Requirements.Rulesets {
+		if r := &cur.Index.Requirements.Rulesets[i]; r.RulesetKey == rs.Key {
+			r.RulesetVersion = rs.Version
+		}
+	}
+	p := &cur.Profiles[0].Object.Profile
+	oldRef := p.Rulesets[0].Key + "@" + p.Rulesets[0].Version
+	if p.Rulesets[0].Version == "" {
+		oldRef = p.Rulesets[0].Key
+	}
+	p.Rulesets[0].Version = "^1.1.0"
+	for i := range cur.Index.Artifacts.Artifacts {
+		a := &cur.Index.Artifacts.Artifacts[i]
+		if a.Kind == "opensspm.profile" && a.Key == p.Key {
+			a.Hash = "changed"
+		}
+	}
+
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	want := []string{
+		"opensspm.profile " + p.Key + " rulesets changed",
+		"opensspm.ruleset cis.okta.idaas_stig.v1@1.1.0 version changed",
+		"opensspm.ruleset cis.okta.idaas_stig.v1@1.1.0 " + rs.Rules[0].Key + " severity changed",
+	}
+	if diff := cmp.Diff(want, summarize(r)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := string(r.Changes[0].Old)+" -> "+string(r.Changes[0].New), `"`+oldRef+`" -> "`+p.Rulesets[0].Key+`@^1.1.0"`; got != want {
+		t.Errorf("profile rulesets change = %s, want %s", got, want)
+	}
+}
+
+func TestDescriptors_NewContractVersion(t *testing.T) {
+	old, cur := loadDistDescriptor(t), loadDistDescriptor(t)
+	v2 := cur.DatasetContracts[0]
+	dc := &v2.Object.Dataset
+	dc.Version++
+	key := fmt.Sprintf("%s@%d", dc.Key, dc.Version)
+	prev := fmt.Sprintf("%s@%d", dc.Key, dc.Version-1)
+	cur.DatasetContracts = append(cur.DatasetContracts, v2)
+	cur.Index.Artifacts.Artifacts = append(cur.Index.Artifacts.Artifacts, types.Artifact{Kind: "opensspm.dataset_contract", Key: key, Hash: "new"})
+
+	r, err := Descriptors(old, cur)
+	if err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	if diff := cmp.Diff([]string{"opensspm.dataset_contract " + key + " added"}, summarize(r)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+	if c := r.Changes[0]; c.Previous != prev || len(c.Reasons) != 0 {
+		t.Errorf("Previous = %q, Reasons = %q; want %q and no reasons", c.Previous, c.Reasons, prev)
+	}
+
+	// Requiring a new property is incompatible with the previous version, but adding a
+	// version does not break anything.
+	var schema map[string]any
+	if err := json.Unmarshal(dc.Schema, &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+	schema["required"] = append(schema["required"].([]any), "new_field")
+	dc.Schema, _ = json.Marshal(schema)
+	cur.DatasetContracts[len(cur.DatasetContracts)-1] = v2
+	if r, err = Descriptors(old, cur); err != nil {
+		t.Fatalf("Descriptors: %v", err)
+	}
+	if want := []string{`field "/": property "new_field" is newly required`}; !cmp.Equal(want, r.Changes[0].Reasons) || r.Breaking != 0 {
+		t.Errorf("Reasons = %q, Breaking = %d; want %q and 0", r.Changes[0].Reasons, r.Breaking, want)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if !strings.Contains(buf.String(), key+" added (incompatible with "+prev+")\n") {
+		t.Errorf("unexpected text output:\n%s", buf.String())
+	}
+}
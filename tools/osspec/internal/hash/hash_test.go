@@ -8,7 +8,7 @@ import (
 )
 
 func TestHashObjectJCS_NormalizedRulesetStableAcrossOrdering(t *testing.T) {
-	zero := 0
+	zero := 0.0
 
 	doc1 := types.RulesetDoc{
 		SchemaVersion: 1,
@@ -141,7 +141,7 @@ func TestHashObjectJCS_NormalizedRulesetStableAcrossOrdering(t *testing.T) {
 }
 
 func TestHashObjectJCS_NormalizedRulesetStableAcrossJoinWhereOrdering(t *testing.T) {
-	zero := 0
+	zero := 0.0
 
 	doc1 := types.RulesetDoc{
 		SchemaVersion: 1,
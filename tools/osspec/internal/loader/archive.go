@@ -0,0 +1,216 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Open returns the spec tree at p: a directory, a gzipped tarball (.tar.gz, .tgz) or a zip
+// archive. Archives are read into memory and never extracted to disk. If an archive holds a
+// single top-level directory (as source tarballs do), the tree is rooted there.
+func Open(p string) (fs.FS, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(p), nil
+	}
+	name := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadTarGz(f)
+	case strings.HasSuffix(name, ".zip"):
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		b, err := io.ReadAll(io.LimitReader(f, MaxArchiveSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > MaxArchiveSize {
+			return nil, fmt.Errorf("loader: %s: archive is larger than %d bytes", p, MaxArchiveSize)
+		}
+		return ReadZip(bytes.NewReader(b), int64(len(b)))
+	default:
+		return nil, fmt.Errorf("loader: %s: expected a directory, .tar.gz, .tgz or .zip", p)
+	}
+}
+
+// Limits on the archives Open, ReadTarGz and ReadZip accept, so that an uploaded pack
+// cannot make the loader buffer an unbounded amount of data. MaxArchiveSize bounds both the
+// file data read from an archive and the size of a .zip file opened by Open.
+const (
+	MaxArchiveEntries = 10000
+	MaxArchiveSize    = 64 * 1024 * 1024 // 64 MiB
+)
+
+// archiveBudget counts the entries and file data read from an archive against
+// MaxArchiveEntries and MaxArchiveSize. Sizes are counted as data is read, not taken from
+// archive headers.
+type archiveBudget struct {
+	entries, size int
+}
+
+func (b *archiveBudget) entry() error {
+	if b.entries++; b.entries > MaxArchiveEntries {
+		return fmt.Errorf("archive has more than %d entries", MaxArchiveEntries)
+	}
+	return nil
+}
+
+// read reads at most MaxSpecFileSize+1 bytes of r, so oversized files are reported by
+// LoadSpecFiles rather than held in memory.
+func (b *archiveBudget) read(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSpecFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if b.size += len(data); b.size > MaxArchiveSize {
+		return nil, fmt.Errorf("archive holds more than %d bytes of files", MaxArchiveSize)
+	}
+	return data, nil
+}
+
+// ReadTarGz reads a gzipped tarball into an in-memory file system. Symlinks and hard links
+// are kept as symlinks so LoadSpecFiles rejects them, and each file is truncated just past
+// MaxSpecFileSize so oversized files are reported rather than held in memory. Archives with
+// more than MaxArchiveEntries entries or MaxArchiveSize bytes of buffered file data are
+// rejected.
+func ReadTarGz(r io.Reader) (fs.FS, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("loader: read tar.gz: %w", err)
+	}
+	defer zr.Close()
+
+	m := memFS{}
+	tr := tar.NewReader(zr)
+	var budget archiveBudget
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loader: read tar.gz: %w", err)
+		}
+		if err := budget.entry(); err != nil {
+			return nil, fmt.Errorf("loader: read tar.gz: %w", err)
+		}
+		name, err := archivePath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if name == "." {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			m[name] = &memFile{mode: fs.ModeDir | 0o755}
+		case tar.TypeReg:
+			b, err := budget.read(tr)
+			if err != nil {
+				return nil, fmt.Errorf("loader: read tar.gz: %s: %w", name, err)
+			}
+			m[name] = &memFile{data: b, mode: 0o644}
+		case tar.TypeSymlink, tar.TypeLink:
+			m[name] = &memFile{data: []byte(hdr.Linkname), mode: fs.ModeSymlink | 0o777}
+		default:
+			// Devices, FIFOs and other special files are not spec files.
+		}
+	}
+	return archiveRoot(m)
+}
+
+// ReadZip reads a zip archive into an in-memory file system. Entry names must be valid,
+// relative paths. Symlinks are kept so LoadSpecFiles rejects them, files are truncated
+// just past MaxSpecFileSize, and archives with more than MaxArchiveEntries entries or
+// MaxArchiveSize bytes of decompressed file data are rejected.
+func ReadZip(r io.ReaderAt, size int64) (fs.FS, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("loader: read zip: %w", err)
+	}
+	m := memFS{}
+	var budget archiveBudget
+	for _, f := range zr.File {
+		name, err := archivePath(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := budget.entry(); err != nil {
+			return nil, fmt.Errorf("loader: read zip: %w", err)
+		}
+		if name == "." {
+			continue
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			m[name] = &memFile{mode: fs.ModeDir | 0o755}
+		case mode.IsRegular(), mode&fs.ModeSymlink != 0:
+			b, err := readZipFile(f, &budget)
+			if err != nil {
+				return nil, fmt.Errorf("loader: read zip: %s: %w", name, err)
+			}
+			if mode.IsRegular() {
+				m[name] = &memFile{data: b, mode: 0o644}
+			} else {
+				m[name] = &memFile{data: b, mode: fs.ModeSymlink | 0o777}
+			}
+		default:
+			// Devices, FIFOs and other special files are not spec files.
+		}
+	}
+	return archiveRoot(m)
+}
+
+func readZipFile(f *zip.File, budget *archiveBudget) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return budget.read(rc)
+}
+
+func archivePath(name string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(name, "./"))
+	if clean == "" || !fs.ValidPath(clean) {
+		return "", fmt.Errorf("loader: archive entry escapes the archive root: %q", name)
+	}
+	return clean, nil
+}
+
+// archiveRoot descends into the single top-level directory of an archive that has no
+// version.json at its root.
+func archiveRoot(fsys fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(fsys, "version.json"); err == nil {
+		return fsys, nil
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("loader: read archive: %w", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return fsys, nil
+	}
+	return fs.Sub(fsys, entries[0].Name())
+}
@@ -4,38 +4,60 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
 )
 
 const MaxSpecFileSize = 2 * 1024 * 1024 // 2 MiB
 
 type LoadedFile struct {
+	// AbsPath is set only when loading from RepoRoot.
 	AbsPath string
 	RelPath string
 	Bytes   []byte
 }
 
 type Options struct {
+	// FS is the source tree. If nil, os.DirFS(RepoRoot) is used.
+	FS       fs.FS
 	RepoRoot string
 	SpecsDir string
 }
 
+// LoadSpecFiles loads the .json files under SpecsDir, ordered by relative path. Files that
+// are rejected (symlinks, files over MaxSpecFileSize) are skipped and reported together as a
+// diag.List, returned alongside the files that were loaded.
 func LoadSpecFiles(ctx context.Context, opts Options) ([]LoadedFile, error) {
-	if opts.RepoRoot == "" {
-		return nil, errors.New("loader: RepoRoot is required")
+	fsys := opts.FS
+	if fsys == nil {
+		if opts.RepoRoot == "" {
+			return nil, errors.New("loader: FS or RepoRoot is required")
+		}
+		fsys = os.DirFS(filepath.Clean(opts.RepoRoot))
 	}
 	if opts.SpecsDir == "" {
 		return nil, errors.New("loader: SpecsDir is required")
 	}
+	specsDir := path.Clean(filepath.ToSlash(opts.SpecsDir))
+	if !fs.ValidPath(specsDir) {
+		return nil, fmt.Errorf("loader: invalid SpecsDir %q", opts.SpecsDir)
+	}
 
-	root := filepath.Clean(opts.RepoRoot)
-	specsAbs := filepath.Join(root, opts.SpecsDir)
+	// fs.WalkDir follows a symlinked root, so check it explicitly where the FS can tell.
+	if lfs, ok := fsys.(fs.ReadLinkFS); ok {
+		if info, err := lfs.Lstat(specsDir); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return nil, diag.List{diag.New(diag.CodeSymlink, specsDir, "", "symlink not allowed")}
+		}
+	}
 
 	var out []LoadedFile
-	err := filepath.WalkDir(specsAbs, func(path string, d fs.DirEntry, walkErr error) error {
+	var rejected diag.List
+	err := fs.WalkDir(fsys, specsDir, func(p string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -46,16 +68,16 @@ func LoadSpecFiles(ctx context.Context, opts Options) ([]LoadedFile, error) {
 		}
 
 		// Disallow symlinks anywhere in specs tree.
-		if d.Type()&os.ModeSymlink != 0 {
-			rel, _ := filepath.Rel(root, path)
-			return fmt.Errorf("loader: symlink not allowed: %s", filepath.ToSlash(rel))
+		if d.Type()&fs.ModeSymlink != 0 {
+			rejected = append(rejected, diag.New(diag.CodeSymlink, p, "", "symlink not allowed"))
+			return nil
 		}
 
 		if d.IsDir() {
 			return nil
 		}
 
-		if filepath.Ext(d.Name()) != ".json" {
+		if path.Ext(d.Name()) != ".json" {
 			return nil
 		}
 
@@ -67,28 +89,25 @@ func LoadSpecFiles(ctx context.Context, opts Options) ([]LoadedFile, error) {
 			return nil
 		}
 		if info.Size() > MaxSpecFileSize {
-			rel, _ := filepath.Rel(root, path)
-			return fmt.Errorf("loader: file too large (>2MiB): %s", filepath.ToSlash(rel))
+			rejected = append(rejected, diag.New(diag.CodeFileTooLarge, p, "", "file too large (>2MiB)"))
+			return nil
 		}
 
-		b, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		rel, err := filepath.Rel(root, path)
+		b, err := readFile(fsys, p)
 		if err != nil {
 			return err
 		}
-		rel = filepath.ToSlash(rel)
-		if strings.HasPrefix(rel, "../") {
-			return fmt.Errorf("loader: path escapes repo root: %s", rel)
+		if len(b) > MaxSpecFileSize {
+			// The reported size may be unreliable for some file systems.
+			rejected = append(rejected, diag.New(diag.CodeFileTooLarge, p, "", "file too large (>2MiB)"))
+			return nil
 		}
 
-		out = append(out, LoadedFile{
-			AbsPath: path,
-			RelPath: rel,
-			Bytes:   b,
-		})
+		f := LoadedFile{RelPath: p, Bytes: b}
+		if opts.FS == nil {
+			f.AbsPath = filepath.Join(filepath.Clean(opts.RepoRoot), filepath.FromSlash(p))
+		}
+		out = append(out, f)
 		return nil
 	})
 	if err != nil {
@@ -97,7 +116,17 @@ func LoadSpecFiles(ctx context.Context, opts Options) ([]LoadedFile, error) {
 
 	// Ensure stable ordering for determinism (by rel path).
 	sortLoaded(out)
-	return out, nil
+	return out, rejected.Err()
+}
+
+// readFile reads at most MaxSpecFileSize+1 bytes of name.
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, MaxSpecFileSize+1))
 }
 
 func sortLoaded(files []LoadedFile) {
@@ -110,4 +139,3 @@ func sortLoaded(files []LoadedFile) {
 		}
 	}
 }
-
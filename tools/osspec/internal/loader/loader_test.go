@@ -0,0 +1,239 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+)
+
+type entry struct {
+	name string
+	data string
+	link string // symlink target
+}
+
+var bundle = []entry{
+	{name: "version.json", data: `{}`},
+	{name: "specs/b.json", data: `{"b":1}`},
+	{name: "specs/a/z.json", data: `{"z":1}`},
+	{name: "specs/a/readme.md", data: "not a spec"},
+	{name: "specs/link.json", link: "b.json"},
+	{name: "specs/big.json", data: strings.Repeat(" ", MaxSpecFileSize+1)},
+}
+
+// wantLoaded and wantRejected describe LoadSpecFiles on bundle.
+var (
+	wantLoaded   = []string{"specs/a/z.json", "specs/b.json"}
+	wantRejected = map[string]string{"specs/big.json": diag.CodeFileTooLarge, "specs/link.json": diag.CodeSymlink}
+)
+
+func checkLoad(t *testing.T, fsys fs.FS) {
+	t.Helper()
+	files, err := LoadSpecFiles(context.Background(), Options{FS: fsys, SpecsDir: "specs"})
+	var got []string
+	for _, f := range files {
+		got = append(got, f.RelPath)
+		if f.AbsPath != "" {
+			t.Errorf("%s: AbsPath set for an fs.FS source", f.RelPath)
+		}
+	}
+	if !slices.Equal(got, wantLoaded) {
+		t.Errorf("loaded %v, want %v", got, wantLoaded)
+	}
+
+	var rejected diag.List
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected diagnostics for rejected files, got %v", err)
+	}
+	gotRejected := map[string]string{}
+	for _, d := range rejected {
+		gotRejected[d.File] = d.Code
+	}
+	if len(gotRejected) != len(wantRejected) {
+		t.Errorf("rejected %v, want %v", gotRejected, wantRejected)
+	}
+	for file, code := range wantRejected {
+		if gotRejected[file] != code {
+			t.Errorf("%s: got %q, want %q", file, gotRejected[file], code)
+		}
+	}
+}
+
+func TestLoadSpecFiles_MapFS(t *testing.T) {
+	m := fstest.MapFS{}
+	for _, e := range bundle {
+		if e.link != "" {
+			m[e.name] = &fstest.MapFile{Data: []byte(e.link), Mode: fs.ModeSymlink | 0o777}
+			continue
+		}
+		m[e.name] = &fstest.MapFile{Data: []byte(e.data), Mode: 0o644}
+	}
+	checkLoad(t, m)
+}
+
+func TestReadTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	// Source tarballs nest everything under one top-level directory.
+	if err := tw.WriteHeader(&tar.Header{Name: "bundle-1.0/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range bundle {
+		hdr := &tar.Header{Name: "bundle-1.0/" + e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.data))}
+		if e.link != "" {
+			hdr = &tar.Header{Name: "bundle-1.0/" + e.name, Typeflag: tar.TypeSymlink, Linkname: e.link, Mode: 0o777}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := ReadTarGz(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadTarGz() error: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "version.json", "specs/b.json", "specs/a/z.json", "specs/a/readme.md"); err != nil {
+		t.Fatal(err)
+	}
+	checkLoad(t, fsys)
+}
+
+func TestReadZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range bundle {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		data := e.data
+		if e.link != "" {
+			hdr.SetMode(fs.ModeSymlink | 0o777)
+			data = e.link
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadZip() error: %v", err)
+	}
+	checkLoad(t, fsys)
+}
+
+func TestReadArchives_RejectEscapingPaths(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if _, err := zw.Create("../evil.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Fatalf("expected error for zip entry outside the archive root")
+	}
+
+	buf.Reset()
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "/etc/evil.json", Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	if _, err := ReadTarGz(&buf); err == nil {
+		t.Fatalf("expected error for absolute tar entry")
+	}
+}
+
+func TestReadTarGz_Limits(t *testing.T) {
+	tarGz := func(entries int, size int64) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		data := make([]byte, size)
+		for i := range entries {
+			if err := tw.WriteHeader(&tar.Header{Name: fmt.Sprintf("f%d.json", i), Typeflag: tar.TypeReg, Mode: 0o644, Size: size}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	if _, err := ReadTarGz(bytes.NewReader(tarGz(MaxArchiveEntries+1, 0))); err == nil || !strings.Contains(err.Error(), "entries") {
+		t.Errorf("ReadTarGz(too many entries) error = %v", err)
+	}
+	// Each file is truncated past MaxSpecFileSize, but together they exceed MaxArchiveSize.
+	n := MaxArchiveSize/MaxSpecFileSize + 1
+	if _, err := ReadTarGz(bytes.NewReader(tarGz(n, MaxSpecFileSize+1))); err == nil || !strings.Contains(err.Error(), "bytes") {
+		t.Errorf("ReadTarGz(too much data) error = %v", err)
+	}
+}
+
+func TestReadZip_Limits(t *testing.T) {
+	zipped := func(entries int, size int64) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		data := make([]byte, size)
+		for i := range entries {
+			w, err := zw.Create(fmt.Sprintf("f%d.json", i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	b := zipped(MaxArchiveEntries+1, 0)
+	if _, err := ReadZip(bytes.NewReader(b), int64(len(b))); err == nil || !strings.Contains(err.Error(), "entries") {
+		t.Errorf("ReadZip(too many entries) error = %v", err)
+	}
+	// The files compress well, so only their decompressed size exceeds MaxArchiveSize.
+	b = zipped(MaxArchiveSize/MaxSpecFileSize+1, MaxSpecFileSize+1)
+	if _, err := ReadZip(bytes.NewReader(b), int64(len(b))); err == nil || !strings.Contains(err.Error(), "bytes") {
+		t.Errorf("ReadZip(too much data) error = %v", err)
+	}
+}
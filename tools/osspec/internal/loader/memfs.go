@@ -0,0 +1,130 @@
+package loader
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// memFS is a read-only in-memory file system for archive contents. Directories are implied
+// by the paths of the files they contain. Symlinks are stored with fs.ModeSymlink and are
+// never followed.
+type memFS map[string]*memFile
+
+type memFile struct {
+	data []byte
+	mode fs.FileMode
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if f, ok := m[name]; ok && !f.mode.IsDir() {
+		return &memHandle{info: memInfo{name: path.Base(name), f: f}, r: bytes.NewReader(f.data)}, nil
+	}
+	entries, ok := m.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memHandle{info: memInfo{name: path.Base(name), f: &memFile{mode: fs.ModeDir | 0o755}}, entries: entries}, nil
+}
+
+func (m memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, ok := m.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// readDir lists the direct children of dir, sorted by name. ok is false if dir is neither
+// a stored directory nor the parent of any stored path.
+func (m memFS) readDir(dir string) (entries []fs.DirEntry, ok bool) {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+		ok = true
+	}
+	if f, found := m[dir]; found {
+		if !f.mode.IsDir() {
+			return nil, false
+		}
+		ok = true
+	}
+	seen := map[string]bool{}
+	for p, f := range m {
+		rest, found := strings.CutPrefix(p, prefix)
+		if !found || rest == "" || p == dir {
+			continue
+		}
+		ok = true
+		child, _, nested := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		if nested {
+			f = &memFile{mode: fs.ModeDir | 0o755}
+			if d, found := m[prefix+child]; found {
+				f = d
+			}
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: child, f: f}))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, ok
+}
+
+type memInfo struct {
+	name string
+	f    *memFile
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.f.data)) }
+func (i memInfo) Mode() fs.FileMode  { return i.f.mode }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.f.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
+
+type memHandle struct {
+	info    memInfo
+	r       *bytes.Reader
+	entries []fs.DirEntry
+}
+
+func (h *memHandle) Stat() (fs.FileInfo, error) { return h.info, nil }
+func (h *memHandle) Close() error               { return nil }
+
+func (h *memHandle) Read(b []byte) (int, error) {
+	if h.r == nil {
+		return 0, &fs.PathError{Op: "read", Path: h.info.name, Err: fs.ErrInvalid}
+	}
+	return h.r.Read(b)
+}
+
+func (h *memHandle) ReadDir(n int) ([]fs.DirEntry, error) {
+	if h.r != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: h.info.name, Err: fs.ErrInvalid}
+	}
+	if n <= 0 {
+		out := h.entries
+		h.entries = nil
+		return out, nil
+	}
+	if len(h.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(h.entries))
+	out := h.entries[:n]
+	h.entries = h.entries[n:]
+	return out, nil
+}
@@ -42,7 +42,7 @@ func DatasetRefs(v []types.DatasetRefSpec) []types.DatasetRefSpec {
 		if a.Version > b.Version {
 			return 1
 		}
-		return 0
+		return strings.Compare(a.ConnectorKind, b.ConnectorKind)
 	})
 	return out
 }
@@ -92,6 +92,9 @@ func ProfileDoc(doc *types.ProfileDoc) {
 	if doc == nil {
 		return
 	}
+	if len(doc.Profile.Extends) > 0 {
+		doc.Profile.Extends = Strings(doc.Profile.Extends)
+	}
 	if len(doc.Profile.Rulesets) == 0 {
 		return
 	}
@@ -102,9 +105,45 @@ func ProfileDoc(doc *types.ProfileDoc) {
 		}
 		return strings.Compare(a.Version, b.Version)
 	})
+	for i := range out {
+		if len(out[i].Overrides) == 0 {
+			continue
+		}
+		overrides := append([]types.RuleOverride(nil), out[i].Overrides...)
+		slices.SortStableFunc(overrides, func(a, b types.RuleOverride) int {
+			return strings.Compare(a.RuleKey, b.RuleKey)
+		})
+		out[i].Overrides = overrides
+	}
 	doc.Profile.Rulesets = out
 }
 
+func ConformanceSuiteDoc(doc *types.ConformanceSuiteDoc) {
+	if doc == nil {
+		return
+	}
+	slices.SortFunc(doc.Suite.Vectors, func(a, b types.ConformanceVector) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	for i := range doc.Suite.Vectors {
+		v := &doc.Suite.Vectors[i]
+		normalizeCheckDefaults(v.Check)
+		slices.SortStableFunc(v.Datasets, func(a, b types.ConformanceDataset) int {
+			if c := strings.Compare(a.Dataset, b.Dataset); c != 0 {
+				return c
+			}
+			if c := a.Version - b.Version; c != 0 {
+				return c
+			}
+			return strings.Compare(a.ConnectorKind, b.ConnectorKind)
+		})
+		if v.Expect.AffectedResourceIDs != nil {
+			ids := Strings(*v.Expect.AffectedResourceIDs)
+			v.Expect.AffectedResourceIDs = &ids
+		}
+	}
+}
+
 func DictionaryDoc(doc *types.DictionaryDoc) {
 	if doc == nil {
 		return
@@ -207,12 +246,27 @@ func normalizeCheckDefaults(c *types.Check) {
 		c.OnSyncError = types.ErrorPolicyError
 	}
 
+	defaultQuantifier := func(_ string, p *types.Predicate) {
+		if !p.IsGroup() && p.Quantifier == "" && types.HasWildcard(p.ComparedPath()) {
+			p.Quantifier = types.QuantifierAny
+		}
+	}
+	for i := range c.Where {
+		c.Where[i].Walk(defaultQuantifier)
+	}
+	if c.Assert != nil {
+		c.Assert.Walk(defaultQuantifier)
+	}
+
 	if len(c.Where) > 0 {
 		sortPredicates(c.Type, c.Where)
 	}
+	if c.Assert != nil {
+		sortPredicateGroups(c.Type, c.Assert)
+	}
 
 	switch c.Type {
-	case types.CheckTypeDatasetFieldCompare:
+	case types.CheckTypeDatasetFieldCompare, types.CheckTypeDatasetJoinFieldCompare:
 		if c.Expect == nil {
 			c.Expect = &types.FieldCompareExpect{}
 		}
@@ -222,17 +276,25 @@ func normalizeCheckDefaults(c *types.Check) {
 		if c.Expect.OnEmpty == "" {
 			c.Expect.OnEmpty = types.FieldCompareOnEmptyUnknown
 		}
-	case types.CheckTypeDatasetJoinCountCompare:
-		if c.OnUnmatchedLeft == "" {
-			c.OnUnmatchedLeft = types.OnUnmatchedLeftIgnore
+	case types.CheckTypeDatasetAggregateCompare:
+		if c.Aggregate != nil && c.Aggregate.OnEmpty == "" {
+			c.Aggregate.OnEmpty = types.FieldCompareOnEmptyUnknown
 		}
 	}
+	if c.Type.IsJoin() && c.OnUnmatchedLeft == "" {
+		c.OnUnmatchedLeft = types.OnUnmatchedLeftIgnore
+	}
 }
 
 func sortPredicates(checkType types.CheckType, preds []types.Predicate) {
-	// Sorting is stable/deterministic per newspec.md section 7.1.
+	// Nested groups are sorted first, so that equal groups compare equal below.
+	for i := range preds {
+		sortPredicateGroups(checkType, &preds[i])
+	}
+	// Sorting is stable/deterministic per newspec.md section 7.1. Groups have no path or
+	// op, so they sort before comparisons and among themselves by their canonical JSON.
 	slices.SortFunc(preds, func(a, b types.Predicate) int {
-		if checkType == types.CheckTypeDatasetJoinCountCompare {
+		if checkType.IsJoin() {
 			if c := strings.Compare(a.LeftPath, b.LeftPath); c != 0 {
 				return c
 			}
@@ -251,10 +313,28 @@ func sortPredicates(checkType types.CheckType, preds []types.Predicate) {
 		if c := strings.Compare(a.ValueParam, b.ValueParam); c != 0 {
 			return c
 		}
-		return strings.Compare(canonicalValue(a.Value), canonicalValue(b.Value))
+		if c := strings.Compare(canonicalValue(a.Value), canonicalValue(b.Value)); c != 0 {
+			return c
+		}
+		// Groups, and comparisons that differ only in quantifier.
+		return strings.Compare(canonicalValue(a), canonicalValue(b))
 	})
 }
 
+// sortPredicateGroups sorts the predicates nested in p. The members of all_of and any_of
+// are unordered, so they are sorted like check.where.
+func sortPredicateGroups(checkType types.CheckType, p *types.Predicate) {
+	if len(p.AllOf) > 0 {
+		sortPredicates(checkType, p.AllOf)
+	}
+	if len(p.AnyOf) > 0 {
+		sortPredicates(checkType, p.AnyOf)
+	}
+	if p.Not != nil {
+		sortPredicateGroups(checkType, p.Not)
+	}
+}
+
 func canonicalValue(v any) string {
 	if v == nil {
 		return "null"
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
 
@@ -48,9 +49,37 @@ func (r Runner) Run(ctx context.Context, language string, req types.CodegenReque
 	if resp.SchemaVersion != 1 || resp.Kind != "opensspm.codegen_response" {
 		return types.CodegenResponse{}, fmt.Errorf("plugin: invalid response header: schema_version=%d kind=%q", resp.SchemaVersion, resp.Kind)
 	}
+	if err := checkSpecVersion(pluginName, req, resp); err != nil {
+		return types.CodegenResponse{}, err
+	}
 	return resp, nil
 }
 
+// checkSpecVersion returns an error unless the plugin declares that it supports the spec
+// version of the request's descriptor.
+func checkSpecVersion(pluginName string, req types.CodegenRequest, resp types.CodegenResponse) error {
+	if resp.SupportedSpecVersions == "" {
+		return fmt.Errorf("plugin: %s does not declare supported_spec_versions; upgrade the plugin", pluginName)
+	}
+	c, err := semver.ParseConstraint(resp.SupportedSpecVersions)
+	if err != nil {
+		return fmt.Errorf("plugin: %s declares invalid supported_spec_versions: %w", pluginName, err)
+	}
+	specVersion := req.Descriptor.Version.SpecVersion
+	v, err := semver.Parse(specVersion)
+	if err != nil {
+		return fmt.Errorf("plugin: invalid spec_version %q: %w", specVersion, err)
+	}
+	if !c.Check(v) {
+		name := pluginName
+		if resp.PluginVersion != "" {
+			name += " " + resp.PluginVersion
+		}
+		return fmt.Errorf("plugin: %s supports spec versions %s, not spec_version %s", name, resp.SupportedSpecVersions, specVersion)
+	}
+	return nil
+}
+
 func (r Runner) commandForLanguage(ctx context.Context, pluginName, language string) (*exec.Cmd, error) {
 	if path, err := exec.LookPath(pluginName); err == nil {
 		return exec.CommandContext(ctx, path), nil
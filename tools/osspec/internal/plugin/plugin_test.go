@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestCheckSpecVersion(t *testing.T) {
+	req := types.CodegenRequest{Descriptor: types.DescriptorV1{Version: types.Version{SpecVersion: "1.2.0"}}}
+	tests := []struct {
+		name    string
+		resp    types.CodegenResponse
+		wantErr string
+	}{
+		{name: "supported", resp: types.CodegenResponse{SupportedSpecVersions: "1.x"}},
+		{name: "range", resp: types.CodegenResponse{SupportedSpecVersions: ">=1.0.0 <1.2.0 || ^2"}, wantErr: "osspec-gen-x supports spec versions >=1.0.0 <1.2.0 || ^2, not spec_version 1.2.0"},
+		{name: "with plugin version", resp: types.CodegenResponse{PluginVersion: "0.3.0", SupportedSpecVersions: "2.x"}, wantErr: "osspec-gen-x 0.3.0 supports spec versions 2.x, not spec_version 1.2.0"},
+		{name: "undeclared", resp: types.CodegenResponse{}, wantErr: "osspec-gen-x does not declare supported_spec_versions"},
+		{name: "invalid", resp: types.CodegenResponse{SupportedSpecVersions: "one"}, wantErr: "osspec-gen-x declares invalid supported_spec_versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSpecVersion("osspec-gen-x", req, tt.resp)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
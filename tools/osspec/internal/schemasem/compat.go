@@ -0,0 +1,245 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// ContractIncompatibility is a change between two versions of a dataset contract that can
+// break rules reading the dataset or connectors producing it.
+type ContractIncompatibility struct {
+	// Pointer locates the change in the new contract document.
+	Pointer string
+	Message string
+}
+
+// CompareContracts reports the backward-incompatible changes from old to new: properties
+// that were removed, types and enums that were narrowed, properties that became required,
+// additionalProperties that became false, and a changed primary_key. Local $refs are
+// followed and allOf/anyOf/oneOf members are merged. Relaxing changes (new optional
+// properties, widened types) are compatible.
+func CompareContracts(old, new *types.DatasetContract) []ContractIncompatibility {
+	var out []ContractIncompatibility
+	if old.PrimaryKey != new.PrimaryKey {
+		out = append(out, ContractIncompatibility{
+			Pointer: "/dataset/primary_key",
+			Message: fmt.Sprintf("primary_key changed from %q to %q", old.PrimaryKey, new.PrimaryKey),
+		})
+	}
+	var oldRoot, newRoot map[string]any
+	if json.Unmarshal(old.Schema, &oldRoot) != nil || json.Unmarshal(new.Schema, &newRoot) != nil {
+		return out
+	}
+	c := &schemaComparer{
+		old:  &contractSchema{root: oldRoot},
+		new:  &contractSchema{root: newRoot},
+		seen: map[[2]string]bool{},
+	}
+	c.compare(oldRoot, newRoot, "/dataset/schema", "", 0)
+	return append(out, c.out...)
+}
+
+type schemaComparer struct {
+	old, new *contractSchema
+	seen     map[[2]string]bool
+	out      []ContractIncompatibility
+}
+
+// schemaView is a schema node with its $refs followed and combinator members merged.
+type schemaView struct {
+	ptr        string // pointer of the node in the new contract document
+	types      []string
+	enum       []any
+	required   []string
+	properties map[string]map[string]any
+	propOrder  []string
+	// closed is set when additionalProperties is false.
+	closed bool
+	// additional and items are the additionalProperties and items schemas, if any.
+	additional map[string]any
+	items      map[string]any
+}
+
+func (c *schemaComparer) view(cs *contractSchema, n map[string]any, ptr string) schemaView {
+	// Follow a top-level $ref chain so the pointer names the node that is compared.
+	for depth := 0; depth < 32; depth++ {
+		ref, ok := n["$ref"].(string)
+		if !ok {
+			break
+		}
+		target, ok := cs.lookupRef(ref)
+		if !ok {
+			break
+		}
+		n = target
+		if ref == "#" {
+			ptr = "/dataset/schema"
+		} else {
+			ptr = "/dataset/schema" + strings.TrimPrefix(ref, "#")
+		}
+	}
+	v := schemaView{ptr: ptr, properties: map[string]map[string]any{}}
+	for _, m := range cs.expand(n, 0) {
+		v.types = append(v.types, schemaTypes(m)...)
+		if e, ok := m["enum"].([]any); ok && v.enum == nil {
+			v.enum = e
+		}
+		if req, ok := m["required"].([]any); ok {
+			for _, r := range req {
+				if s, ok := r.(string); ok && !slices.Contains(v.required, s) {
+					v.required = append(v.required, s)
+				}
+			}
+		}
+		if props, ok := m["properties"].(map[string]any); ok {
+			for _, name := range sortedKeys(props) {
+				if _, dup := v.properties[name]; dup {
+					continue
+				}
+				if p, ok := props[name].(map[string]any); ok {
+					v.properties[name] = p
+					v.propOrder = append(v.propOrder, name)
+				}
+			}
+		}
+		switch ap := m["additionalProperties"].(type) {
+		case bool:
+			v.closed = v.closed || !ap
+		case map[string]any:
+			if v.additional == nil {
+				v.additional = ap
+			}
+		}
+		if items, ok := m["items"].(map[string]any); ok && v.items == nil {
+			v.items = items
+		}
+	}
+	slices.Sort(v.types)
+	v.types = slices.Compact(v.types)
+	return v
+}
+
+func (c *schemaComparer) add(ptr, field, format string, args ...any) {
+	c.out = append(c.out, ContractIncompatibility{
+		Pointer: ptr,
+		Message: fmt.Sprintf("field %q: ", or(field, "/")) + fmt.Sprintf(format, args...),
+	})
+}
+
+// compare compares the old and new schema nodes for the data field at field (a JSON pointer
+// into dataset rows; "*" stands for array items and additional properties).
+func (c *schemaComparer) compare(oldNode, newNode map[string]any, ptr, field string, depth int) {
+	if depth > 32 {
+		return
+	}
+	o := c.view(c.old, oldNode, "")
+	n := c.view(c.new, newNode, ptr)
+	key := [2]string{fmt.Sprintf("%p", oldNode), n.ptr}
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+
+	if len(n.types) > 0 {
+		if len(o.types) == 0 {
+			c.add(n.ptr+"/type", field, "type narrowed from any to %s", strings.Join(n.types, "|"))
+		} else if !typesCovered(o.types, n.types) {
+			c.add(n.ptr+"/type", field, "type narrowed from %s to %s", strings.Join(o.types, "|"), strings.Join(n.types, "|"))
+		}
+	}
+
+	if n.enum != nil {
+		if o.enum == nil {
+			c.add(n.ptr+"/enum", field, "values restricted to an enum")
+		} else {
+			var removed []string
+			for _, v := range o.enum {
+				if !slices.ContainsFunc(n.enum, func(w any) bool { return jsonString(v) == jsonString(w) }) {
+					removed = append(removed, jsonString(v))
+				}
+			}
+			if len(removed) > 0 {
+				c.add(n.ptr+"/enum", field, "enum values removed: %s", strings.Join(removed, ", "))
+			}
+		}
+	}
+
+	for _, r := range n.required {
+		if !slices.Contains(o.required, r) {
+			c.add(n.ptr+"/required", field, "property %q is newly required", r)
+		}
+	}
+
+	if n.closed && !o.closed {
+		c.add(n.ptr+"/additionalProperties", field, "additional properties are no longer allowed")
+	}
+
+	for _, name := range o.propOrder {
+		sub := field + "/" + escapePointer(name)
+		np, ok := n.properties[name]
+		if !ok {
+			c.add(n.ptr+"/properties", field, "property %q removed", name)
+			continue
+		}
+		c.compare(o.properties[name], np, n.ptr+"/properties/"+escapePointer(name), sub, depth+1)
+	}
+
+	if o.additional != nil && n.additional != nil {
+		c.compare(o.additional, n.additional, n.ptr+"/additionalProperties", field+"/*", depth+1)
+	}
+	if o.items != nil && n.items != nil {
+		c.compare(o.items, n.items, n.ptr+"/items", field+"/*", depth+1)
+	}
+}
+
+// typesCovered reports whether every old type is still accepted by the new types. An
+// integer field stays compatible when widened to number.
+func typesCovered(old, new []string) bool {
+	for _, t := range old {
+		if slices.Contains(new, t) || (t == "integer" && slices.Contains(new, "number")) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// validateContractCompat compares each dataset contract with the same key and version in
+// the baseline descriptor. A published version must not change incompatibly; incompatible
+// changes need a new contract version.
+func validateContractCompat(b *Bundle) diag.List {
+	if b.Baseline == nil {
+		return nil
+	}
+	baseline := map[string]*types.DatasetContract{}
+	for i := range b.Baseline.DatasetContracts {
+		dc := &b.Baseline.DatasetContracts[i].Object.Dataset
+		baseline[fmt.Sprintf("%s@%d", dc.Key, dc.Version)] = dc
+	}
+	var errs diag.List
+	for _, dc := range b.DatasetContracts {
+		ref := fmt.Sprintf("%s@%d", dc.Doc.Dataset.Key, dc.Doc.Dataset.Version)
+		old, ok := baseline[ref]
+		if !ok {
+			continue
+		}
+		for _, inc := range CompareContracts(old, &dc.Doc.Dataset) {
+			errs = append(errs, diag.New(diag.CodeContractCompat, dc.Path, inc.Pointer, "dataset contract %q changed incompatibly since the baseline: %s (publish a new version instead)", ref, inc.Message))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,120 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+const compatBaseSchema = `{
+  "type": "object",
+  "required": ["id"],
+  "properties": {
+    "id": {"type": "string"},
+    "count": {"type": "integer"},
+    "status": {"type": "string", "enum": ["ACTIVE", "INACTIVE"]},
+    "settings": {"$ref": "#/definitions/settings"},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "definitions": {
+    "settings": {"type": "object", "properties": {"minLength": {"type": "integer"}}}
+  }
+}`
+
+func TestCompareContracts(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		pk     string
+		want   []ContractIncompatibility
+	}{
+		{
+			name:   "unchanged",
+			schema: compatBaseSchema,
+			pk:     "/id",
+		},
+		{
+			name: "compatible additions",
+			schema: strings.NewReplacer(
+				`"count": {"type": "integer"}`, `"count": {"type": "number"}, "name": {"type": "string"}`,
+				`"enum": ["ACTIVE", "INACTIVE"]`, `"enum": ["ACTIVE", "INACTIVE", "SUSPENDED"]`,
+			).Replace(compatBaseSchema),
+			pk: "/id",
+		},
+		{
+			name: "incompatible",
+			schema: strings.NewReplacer(
+				`"count": {"type": "integer"},`, ``,
+				`"required": ["id"]`, `"required": ["id", "status"]`,
+				`"enum": ["ACTIVE", "INACTIVE"]`, `"enum": ["ACTIVE"]`,
+				`"minLength": {"type": "integer"}`, `"minLength": {"type": "string"}`,
+				`"items": {"type": "string"}`, `"items": {"type": "integer"}`,
+			).Replace(compatBaseSchema),
+			pk: "/name",
+			want: []ContractIncompatibility{
+				{Pointer: "/dataset/primary_key", Message: `primary_key changed from "/id" to "/name"`},
+				{Pointer: "/dataset/schema/required", Message: `field "/": property "status" is newly required`},
+				{Pointer: "/dataset/schema/properties", Message: `field "/": property "count" removed`},
+				{Pointer: "/dataset/schema/definitions/settings/properties/minLength/type", Message: `field "/settings/minLength": type narrowed from integer to string`},
+				{Pointer: "/dataset/schema/properties/status/enum", Message: `field "/status": enum values removed: "INACTIVE"`},
+				{Pointer: "/dataset/schema/properties/tags/items/type", Message: `field "/tags/*": type narrowed from string to integer`},
+			},
+		},
+		{
+			name:   "closed object",
+			schema: strings.Replace(compatBaseSchema, `"type": "object",`, `"type": "object", "additionalProperties": false,`, 1),
+			pk:     "/id",
+			want: []ContractIncompatibility{
+				{Pointer: "/dataset/schema/additionalProperties", Message: `field "/": additional properties are no longer allowed`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := &types.DatasetContract{Key: "okta:users", Version: 1, PrimaryKey: "/id", Schema: json.RawMessage(compatBaseSchema)}
+			cur := &types.DatasetContract{Key: "okta:users", Version: 1, PrimaryKey: tt.pk, Schema: json.RawMessage(tt.schema)}
+			if diff := cmp.Diff(tt.want, CompareContracts(old, cur)); diff != "" {
+				t.Fatalf("incompatibilities mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_ContractBaseline(t *testing.T) {
+	contract := func(version int, schema string) types.DatasetContractDoc {
+		return types.DatasetContractDoc{SchemaVersion: 1, Kind: "opensspm.dataset_contract", Dataset: types.DatasetContract{Key: "okta:users", Version: version, PrimaryKey: "/id", Schema: json.RawMessage(schema)}}
+	}
+	narrowed := strings.Replace(compatBaseSchema, `"count": {"type": "integer"}`, `"count": {"type": "boolean"}`, 1)
+
+	baseline := &types.DescriptorV1{}
+	for _, v := range []int{1, 2} {
+		baseline.DatasetContracts = append(baseline.DatasetContracts, types.Compiled[types.DatasetContractDoc]{Object: contract(v, compatBaseSchema)})
+	}
+	b := &Bundle{
+		DatasetContracts: []struct {
+			Path string
+			Doc  types.DatasetContractDoc
+		}{
+			{Path: "specs/datasets/okta/users/v1.json", Doc: contract(1, narrowed)},
+			// v2 is unchanged and v3 is new, so neither is reported.
+			{Path: "specs/datasets/okta/users/v2.json", Doc: contract(2, compatBaseSchema)},
+			{Path: "specs/datasets/okta/users/v3.json", Doc: contract(3, narrowed)},
+		},
+		Baseline: baseline,
+	}
+
+	var got []string
+	for _, d := range ValidateSemantic(b) {
+		if d.Code == diag.CodeContractCompat {
+			got = append(got, d.File+"#"+d.Pointer+": "+d.Message)
+		}
+	}
+	want := []string{`specs/datasets/okta/users/v1.json#/dataset/schema/properties/count/type: dataset contract "okta:users@1" changed incompatibly since the baseline: field "/count": type narrowed from integer to boolean (publish a new version instead)`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
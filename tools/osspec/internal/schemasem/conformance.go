@@ -0,0 +1,206 @@
+package schemasem
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func validateConformance(b *Bundle) diag.List {
+	var errs diag.List
+
+	// Vectors reference the highest version of a ruleset.
+	rulesets := map[string]*types.Ruleset{}
+	for key, versions := range indexRulesetVersions(b) {
+		rulesets[key] = &b.Rulesets[versions[0].index].Doc.Ruleset
+	}
+
+	seenSuiteKeys := map[string]string{}
+	for _, s := range b.Conformance {
+		key := s.Doc.Suite.Key
+		if prev, ok := seenSuiteKeys[key]; ok {
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, s.Path, "/suite/key", "duplicate suite.key %q (also in %s)", key, prev).WithRelated(prev, "/suite/key"))
+		} else {
+			seenSuiteKeys[key] = s.Path
+		}
+
+		seenVectorKeys := map[string]struct{}{}
+		for i := range s.Doc.Suite.Vectors {
+			v := &s.Doc.Suite.Vectors[i]
+			ptr := fmt.Sprintf("/suite/vectors/%d", i)
+			if _, ok := seenVectorKeys[v.Key]; ok {
+				errs = append(errs, diag.New(diag.CodeDuplicateKey, s.Path, ptr+"/key", "duplicate vector.key %q", v.Key))
+			} else {
+				seenVectorKeys[v.Key] = struct{}{}
+			}
+			if v.Rule != nil && rulesets[v.Rule.RulesetKey] == nil && b.Incomplete["opensspm.ruleset"] {
+				// The referenced ruleset may be one that failed to load.
+				continue
+			}
+			errs = append(errs, validateVector(s.Path, ptr, v, rulesets)...)
+		}
+	}
+	return errs
+}
+
+func validateVector(path, ptr string, v *types.ConformanceVector, rulesets map[string]*types.Ruleset) diag.List {
+	var errs diag.List
+	errorf := func(field, format string, args ...any) {
+		errs = append(errs, diag.New(diag.CodeConformanceVector, path, ptr+field, "vector %q: %s", v.Key, fmt.Sprintf(format, args...)))
+	}
+
+	if (v.Rule == nil) == (v.Check == nil) {
+		errorf("", "must set exactly one of rule or check")
+		return errs
+	}
+
+	var (
+		rs   *types.Ruleset
+		rule *types.Rule
+	)
+	if v.Rule != nil {
+		if v.Evidence != nil {
+			errorf("/evidence", "evidence is only allowed with an inline check")
+		}
+		rs = rulesets[v.Rule.RulesetKey]
+		if rs == nil {
+			errs = append(errs, diag.New(diag.CodeUnresolvedReference, path, ptr+"/rule/ruleset_key", "vector %q: rule.ruleset_key %q does not match any ruleset", v.Key, v.Rule.RulesetKey))
+			return errs
+		}
+		for i := range rs.Rules {
+			if rs.Rules[i].Key == v.Rule.RuleKey {
+				rule = &rs.Rules[i]
+				break
+			}
+		}
+		if rule == nil {
+			errs = append(errs, diag.New(diag.CodeUnresolvedReference, path, ptr+"/rule/rule_key", "vector %q: rule %q not found in ruleset %q", v.Key, v.Rule.RuleKey, v.Rule.RulesetKey))
+			return errs
+		}
+		for k := range v.Parameters {
+			if rule.Parameters == nil || rule.Parameters.Defaults == nil {
+				errorf("/parameters/"+escapePointer(k), "parameter %q overrides a rule without parameters.defaults", k)
+				continue
+			}
+			if _, ok := rule.Parameters.Defaults[k]; !ok {
+				errorf("/parameters/"+escapePointer(k), "parameter %q not found in rule parameters.defaults", k)
+				continue
+			}
+			if ps, ok := rule.Parameters.Schema[k]; ok {
+				if msg := checkParameterValue(ps, v.Parameters[k]); msg != "" {
+					errorf("/parameters/"+escapePointer(k), "parameter %q: %s", k, msg)
+					continue
+				}
+			}
+			if msg := checkParameterOperand(rule.Check, k, v.Parameters[k]); msg != "" {
+				errorf("/parameters/"+escapePointer(k), "parameter %q: %s", k, msg)
+			}
+		}
+	} else {
+		// Inline checks behave like the only rule of a global ruleset without data_contracts,
+		// so the effective dataset version is check.dataset_version (or 1).
+		rs, rule = inlineVectorRule(v)
+		for _, e := range validateRule(path, ptr, rs, rule, indexDatasetContracts(rs.DataContracts)) {
+			e.Message += fmt.Sprintf(" (inline check of vector %q)", v.Key)
+			errs = append(errs, e)
+		}
+	}
+
+	if v.ReferenceTime != "" {
+		if _, err := time.Parse(time.RFC3339, v.ReferenceTime); err != nil {
+			errorf("/reference_time", "reference_time %q is not an RFC 3339 timestamp", v.ReferenceTime)
+		}
+	} else if usesRelativeTime(rule.Check) {
+		errorf("", "reference_time is required because the check uses older_than or newer_than")
+	}
+
+	// Fixtures are matched on the dataset reference and, for qualified datasets, the
+	// connector kind.
+	referenced := map[string]struct{}{}
+	if rule.Check != nil {
+		for _, d := range rule.Check.Datasets() {
+			referenced[types.DatasetRefString(d.Dataset, types.EffectiveDatasetVersion(d.Dataset, rs.DataContracts, rule.Check.DatasetVersion), d.ConnectorKind)] = struct{}{}
+		}
+	}
+
+	seenFixtures := map[string]struct{}{}
+	for i, d := range v.Datasets {
+		k := types.DatasetRefString(d.Dataset, d.Version, d.ConnectorKind)
+		field := fmt.Sprintf("/datasets/%d", i)
+		if _, ok := seenFixtures[k]; ok {
+			errorf(field, "duplicate fixture dataset %q", k)
+			continue
+		}
+		seenFixtures[k] = struct{}{}
+		if len(d.Rows) > 0 && d.Error != nil {
+			errorf(field, "fixture dataset %q must not set both rows and error", k)
+		}
+		if _, ok := referenced[k]; !ok {
+			errorf(field, "fixture dataset %q is not referenced by the check", k)
+		}
+	}
+	missing := make([]string, 0, len(referenced))
+	for k := range referenced {
+		if _, ok := seenFixtures[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	slices.Sort(missing)
+	for _, k := range missing {
+		errorf("/datasets", "missing fixture for dataset %q (use error.kind=missing_dataset to model an absent dataset)", k)
+	}
+
+	return errs
+}
+
+// usesRelativeTime reports whether any predicate of c uses older_than or newer_than, whose
+// results depend on the reference time.
+func usesRelativeTime(c *types.Check) bool {
+	if c == nil {
+		return false
+	}
+	found := false
+	visit := func(_ string, p *types.Predicate) {
+		found = found || p.Op.IsRelativeTime()
+	}
+	for i := range c.Where {
+		c.Where[i].Walk(visit)
+	}
+	if c.Assert != nil {
+		c.Assert.Walk(visit)
+	}
+	return found
+}
+
+func inlineVectorRule(v *types.ConformanceVector) (*types.Ruleset, *types.Rule) {
+	rs := &types.Ruleset{
+		Key:   "conformance.inline",
+		Scope: types.Scope{Kind: types.ScopeKindGlobal},
+	}
+	rule := &types.Rule{
+		Key:        v.Key,
+		Monitoring: types.Monitoring{Status: types.MonitoringStatusAutomated},
+		Check:      v.Check,
+		Evidence:   v.Evidence,
+	}
+	if v.Parameters != nil {
+		rule.Parameters = &types.Parameters{Defaults: v.Parameters, Schema: inferParameterSchema(v.Parameters)}
+	}
+	for _, d := range datasetsReferencedByCheck(v.Check) {
+		if strings.TrimSpace(d) == "" {
+			continue
+		}
+		rule.RequiredData = append(rule.RequiredData, d)
+		version := v.Check.DatasetVersion
+		if version == 0 {
+			version = 1
+		}
+		rs.DataContracts = append(rs.DataContracts, types.DatasetContractRef{Dataset: d, Version: version})
+	}
+	rs.Rules = []types.Rule{*rule}
+	return rs, rule
+}
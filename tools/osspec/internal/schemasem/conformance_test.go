@@ -0,0 +1,139 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestValidateSemantic_ConformanceFixtures(t *testing.T) {
+	var suite types.ConformanceSuiteDoc
+	if err := json.Unmarshal([]byte(`{
+  "schema_version": 1,
+  "kind": "opensspm.conformance_suite",
+  "suite": {
+    "key": "example",
+    "vectors": [
+      {
+        "key": "missing-fixture",
+        "check": { "type": "dataset.count_compare", "dataset": "core:users", "compare": { "op": "eq", "value": 0 } },
+        "datasets": [{ "dataset": "core:groups", "version": 1 }],
+        "expect": { "status": "pass" }
+      },
+      {
+        "key": "rule-and-check",
+        "rule": { "ruleset_key": "r1", "rule_key": "R1" },
+        "check": { "type": "manual.attestation" },
+        "datasets": [],
+        "expect": { "status": "unknown" }
+      },
+      {
+        "key": "unknown-rule",
+        "rule": { "ruleset_key": "r1", "rule_key": "R9" },
+        "datasets": [],
+        "expect": { "status": "unknown" }
+      },
+      {
+        "key": "no-reference-time",
+        "check": { "type": "dataset.field_compare", "dataset": "core:users", "assert": { "path": "/last_login", "op": "newer_than", "value": "P90D" } },
+        "datasets": [{ "dataset": "core:users", "version": 1, "rows": [] }],
+        "expect": { "status": "unknown" }
+      },
+      {
+        "key": "bad-reference-time",
+        "check": { "type": "dataset.count_compare", "dataset": "core:users", "compare": { "op": "eq", "value": 0 } },
+        "reference_time": "2026-04-01",
+        "datasets": [{ "dataset": "core:users", "version": 1, "rows": [] }],
+        "expect": { "status": "pass" }
+      }
+    ]
+  }
+}`), &suite); err != nil {
+		t.Fatalf("unmarshal suite: %v", err)
+	}
+
+	b := &Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{{Path: "specs/rulesets/r1.json", Doc: minimalRulesetDoc("r1", types.Scope{Kind: types.ScopeKindGlobal})}},
+		Conformance: []struct {
+			Path string
+			Doc  types.ConformanceSuiteDoc
+		}{{Path: "specs/conformance/example.json", Doc: suite}},
+	}
+	errs := ValidateSemantic(b)
+	for _, want := range []string{
+		`missing fixture for dataset "core:users@1"`,
+		`fixture dataset "core:groups@1" is not referenced by the check`,
+		`vector "rule-and-check": must set exactly one of rule or check`,
+		`rule "R9" not found in ruleset "r1"`,
+		`vector "no-reference-time": reference_time is required because the check uses older_than or newer_than`,
+		`vector "bad-reference-time": reference_time "2026-04-01" is not an RFC 3339 timestamp`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+}
+
+func TestValidateSemantic_ConformanceVectorOperands(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    types.Operator
+		value any
+		want  string
+	}{
+		{name: "invalid regex", op: types.OperatorMatches, value: "([", want: `vector "v": parameter "p": op="matches": "([" is not a valid RE2 regular expression`},
+		{name: "valid regex", op: types.OperatorMatches, value: "^splunk"},
+		{name: "malformed duration", op: types.OperatorOlderThan, value: "90 days", want: `vector "v": parameter "p": op="older_than": "90 days" is not an ISO-8601 duration`},
+		{name: "out of range duration", op: types.OperatorNewerThan, value: "P99999Y", want: `vector "v": parameter "p": op="newer_than": "P99999Y" is out of range`},
+		{name: "valid duration", op: types.OperatorOlderThan, value: "P1Y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := minimalRulesetDoc("r1", types.Scope{Kind: types.ScopeKindGlobal})
+			rs.Ruleset.Rules[0].Parameters = &types.Parameters{Defaults: map[string]any{"p": "x"}}
+			rs.Ruleset.Rules[0].Check = &types.Check{
+				Type:    types.CheckTypeDatasetFieldCompare,
+				Dataset: "core:users",
+				Assert:  &types.Predicate{Path: "/name", Op: tt.op, ValueParam: "p"},
+			}
+			b := &Bundle{
+				Rulesets: []struct {
+					Path string
+					Doc  types.RulesetDoc
+				}{{Path: "specs/rulesets/r1.json", Doc: rs}},
+				Conformance: []struct {
+					Path string
+					Doc  types.ConformanceSuiteDoc
+				}{{Path: "specs/conformance/example.json", Doc: types.ConformanceSuiteDoc{
+					SchemaVersion: 1,
+					Kind:          "opensspm.conformance_suite",
+					Suite: types.ConformanceSuite{Key: "example", Vectors: []types.ConformanceVector{{
+						Key:           "v",
+						Rule:          &types.ConformanceRuleRef{RulesetKey: "r1", RuleKey: "R1"},
+						Parameters:    map[string]any{"p": tt.value},
+						ReferenceTime: "2026-04-01T00:00:00Z",
+						Datasets:      []types.ConformanceDataset{{Dataset: "core:users", Version: 1}},
+						Expect:        types.ConformanceExpect{Status: types.ResultStatusPass},
+					}}},
+				}}},
+			}
+			var errs diag.List
+			for _, e := range ValidateSemantic(b) {
+				if e.Pointer == "/suite/vectors/0/parameters/p" {
+					errs = append(errs, e)
+				}
+			}
+			switch {
+			case tt.want == "" && len(errs) > 0:
+				t.Fatalf("unexpected errors:\n%s", joinErrs(errs))
+			case tt.want != "" && !containsErr(errs, tt.want):
+				t.Fatalf("expected error containing %q, got:\n%s", tt.want, joinErrs(errs))
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package schemasem
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// ruleRef identifies a rule of the bundle: the index of its ruleset in Bundle.Rulesets and
+// the rule key.
+type ruleRef struct {
+	ruleset int
+	rule    string
+}
+
+// validateLifecycle checks rule lifecycles: replaced_by must name an existing rule, either
+// a rule key of the same ruleset or "ruleset_key#rule_key" for the highest version of
+// another ruleset, and replacement chains must not loop back on themselves.
+func validateLifecycle(b *Bundle) diag.List {
+	versions := indexRulesetVersions(b)
+	rules := map[ruleRef]*types.Rule{}
+	for i := range b.Rulesets {
+		for j := range b.Rulesets[i].Doc.Ruleset.Rules {
+			r := &b.Rulesets[i].Doc.Ruleset.Rules[j]
+			if _, ok := rules[ruleRef{i, r.Key}]; !ok {
+				rules[ruleRef{i, r.Key}] = r
+			}
+		}
+	}
+
+	// replacements maps each rule with a resolvable replaced_by to its replacement.
+	replacements := map[ruleRef]ruleRef{}
+	var errs diag.List
+	for i, rs := range b.Rulesets {
+		for j := range rs.Doc.Ruleset.Rules {
+			r := &rs.Doc.Ruleset.Rules[j]
+			if r.Lifecycle == nil || r.Lifecycle.ReplacedBy == "" {
+				continue
+			}
+			ptr := fmt.Sprintf("/ruleset/rules/%d/lifecycle/replaced_by", j)
+			rulesetKey, ruleKey, cross := strings.Cut(r.Lifecycle.ReplacedBy, "#")
+			if !cross {
+				rulesetKey, ruleKey = rs.Doc.Ruleset.Key, r.Lifecycle.ReplacedBy
+			}
+			target := ruleRef{ruleset: i, rule: ruleKey}
+			if rulesetKey != rs.Doc.Ruleset.Key {
+				target.ruleset, _ = versions.resolve(rulesetKey, "")
+			}
+			switch {
+			case target.ruleset < 0:
+				if !b.Incomplete["opensspm.ruleset"] {
+					errs = append(errs, ruleErrorf(diag.CodeRuleLifecycle, rs.Path, ptr, r, "lifecycle.replaced_by %q: ruleset %q not found", r.Lifecycle.ReplacedBy, rulesetKey))
+				}
+			case rules[target] == nil:
+				errs = append(errs, ruleErrorf(diag.CodeRuleLifecycle, rs.Path, ptr, r, "lifecycle.replaced_by %q: rule %q not found in ruleset %q", r.Lifecycle.ReplacedBy, ruleKey, rulesetKey))
+			default:
+				replacements[ruleRef{i, r.Key}] = target
+			}
+		}
+	}
+
+	name := func(ref ruleRef) string {
+		return b.Rulesets[ref.ruleset].Doc.Ruleset.Key + "#" + ref.rule
+	}
+	for i, rs := range b.Rulesets {
+		for j := range rs.Doc.Ruleset.Rules {
+			r := &rs.Doc.Ruleset.Rules[j]
+			start := ruleRef{i, r.Key}
+			if rules[start] != r {
+				continue // duplicate rule key, reported by validateRulesetRules
+			}
+			chain := []ruleRef{start}
+			for next, ok := replacements[start]; ok; next, ok = replacements[next] {
+				if slices.Contains(chain, next) {
+					if next == start {
+						names := make([]string, 0, len(chain)+1)
+						for _, ref := range append(chain, start) {
+							names = append(names, name(ref))
+						}
+						errs = append(errs, ruleErrorf(diag.CodeRuleLifecycle, rs.Path, fmt.Sprintf("/ruleset/rules/%d/lifecycle/replaced_by", j), r, "lifecycle.replaced_by cycle: %s", strings.Join(names, " -> ")))
+					}
+					break
+				}
+				chain = append(chain, next)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,112 @@
+package schemasem
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestValidateSemantic_RuleLifecycle(t *testing.T) {
+	// Rulesets "a" and "b" each have rules R1 and R2; R1 is inactive where it is replaced.
+	inactive := false
+	tests := []struct {
+		name     string
+		replaced []string // lifecycle.replaced_by of R1 in ruleset a and b
+		want     []string
+	}{
+		{
+			name:     "same ruleset",
+			replaced: []string{"R2", ""},
+		},
+		{
+			name:     "other ruleset",
+			replaced: []string{"b#R1", ""},
+		},
+		{
+			name:     "own ruleset key",
+			replaced: []string{"a#R2", ""},
+		},
+		{
+			name:     "unknown rule",
+			replaced: []string{"R9", ""},
+			want:     []string{`a.json#/ruleset/rules/0/lifecycle/replaced_by: rule "R1": lifecycle.replaced_by "R9": rule "R9" not found in ruleset "a"`},
+		},
+		{
+			name:     "unknown ruleset",
+			replaced: []string{"c#R1", ""},
+			want:     []string{`a.json#/ruleset/rules/0/lifecycle/replaced_by: rule "R1": lifecycle.replaced_by "c#R1": ruleset "c" not found`},
+		},
+		{
+			name:     "unknown rule in other ruleset",
+			replaced: []string{"b#R9", ""},
+			want:     []string{`a.json#/ruleset/rules/0/lifecycle/replaced_by: rule "R1": lifecycle.replaced_by "b#R9": rule "R9" not found in ruleset "b"`},
+		},
+		{
+			name:     "self",
+			replaced: []string{"R1", ""},
+			want:     []string{`a.json#/ruleset/rules/0/lifecycle/replaced_by: rule "R1": lifecycle.replaced_by cycle: a#R1 -> a#R1`},
+		},
+		{
+			name:     "cycle across rulesets",
+			replaced: []string{"b#R1", "a#R1"},
+			want: []string{
+				`a.json#/ruleset/rules/0/lifecycle/replaced_by: rule "R1": lifecycle.replaced_by cycle: a#R1 -> b#R1 -> a#R1`,
+				`b.json#/ruleset/rules/0/lifecycle/replaced_by: rule "R1": lifecycle.replaced_by cycle: b#R1 -> a#R1 -> b#R1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bundle{}
+			for i, key := range []string{"a", "b"} {
+				doc := minimalRulesetDoc(key, types.Scope{Kind: types.ScopeKindGlobal})
+				r2 := doc.Ruleset.Rules[0]
+				r2.Key, r2.Title = "R2", "R2"
+				doc.Ruleset.Rules = append(doc.Ruleset.Rules, r2)
+				if tt.replaced[i] != "" {
+					doc.Ruleset.Rules[0].Lifecycle = &types.Lifecycle{IsActive: &inactive, ReplacedBy: tt.replaced[i]}
+				}
+				b.Rulesets = append(b.Rulesets, struct {
+					Path string
+					Doc  types.RulesetDoc
+				}{Path: key + ".json", Doc: doc})
+			}
+
+			var got []string
+			for _, e := range ValidateSemantic(b) {
+				got = append(got, e.File+"#"+e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_ProfileOverridesInactiveRule(t *testing.T) {
+	inactive := false
+	doc := minimalRulesetDoc("a", types.Scope{Kind: types.ScopeKindGlobal})
+	doc.Ruleset.Rules[0].Lifecycle = &types.Lifecycle{IsActive: &inactive}
+	b := &Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{{Path: "a.json", Doc: doc}},
+		Profiles: []struct {
+			Path string
+			Doc  types.ProfileDoc
+		}{{Path: "profile.json", Doc: types.ProfileDoc{SchemaVersion: 1, Kind: "opensspm.profile", Profile: types.Profile{
+			Key:      "p",
+			Name:     "p",
+			Rulesets: []types.ProfileRulesetRef{{Key: "a", Overrides: []types.RuleOverride{{RuleKey: "R1", Severity: types.SeverityHigh}}}},
+		}}}},
+	}
+
+	errs := ValidateSemantic(b)
+	want := `profile "p": ruleset "a": override of rule "R1": rule is inactive`
+	if len(errs) != 1 || errs[0].Code != diag.CodeProfileOverride || errs[0].Message != want {
+		t.Fatalf("expected %q, got:\n%s", want, joinErrs(errs))
+	}
+}
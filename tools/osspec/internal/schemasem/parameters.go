@@ -0,0 +1,258 @@
+package schemasem
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// validateParameters checks a rule's parameters (3.3): parameters.defaults and
+// parameters.schema must declare the same keys, and each default must match its schema's
+// type, bounds and enum.
+func validateParameters(path, ptr string, r *types.Rule) diag.List {
+	if r.Parameters == nil {
+		return nil
+	}
+	var errs diag.List
+	p := r.Parameters
+	for _, k := range sortedKeys(p.Defaults) {
+		if _, ok := p.Schema[k]; !ok {
+			errs = append(errs, ruleErrorf(diag.CodeParametersSchema, path, ptr+"/parameters/defaults/"+escapePointer(k), r, "parameters.defaults=%q has no parameters.schema entry", k))
+		}
+	}
+	for _, k := range sortedSchemaKeys(p.Schema) {
+		s := p.Schema[k]
+		schemaPtr := ptr + "/parameters/schema/" + escapePointer(k)
+		if msg := checkParameterSchema(s); msg != "" {
+			errs = append(errs, ruleErrorf(diag.CodeParametersSchema, path, schemaPtr, r, "parameters.schema=%q: %s", k, msg))
+			continue
+		}
+		if p.Defaults == nil {
+			errs = append(errs, ruleErrorf(diag.CodeParametersSchema, path, schemaPtr, r, "parameters.schema=%q but parameters.defaults is missing", k))
+			continue
+		}
+		v, ok := p.Defaults[k]
+		if !ok {
+			errs = append(errs, ruleErrorf(diag.CodeParametersSchema, path, schemaPtr, r, "parameters.schema=%q not found in parameters.defaults", k))
+			continue
+		}
+		if msg := checkParameterValue(s, v); msg != "" {
+			errs = append(errs, ruleErrorf(diag.CodeParametersSchema, path, ptr+"/parameters/defaults/"+escapePointer(k), r, "parameters.defaults=%q: %s", k, msg))
+		}
+	}
+	return errs
+}
+
+// checkParameterSchema returns why s is inconsistent, or "" if it is not.
+func checkParameterSchema(s types.ParameterSchema) string {
+	numeric := s.Type == "integer" || s.Type == "number"
+	if (s.Minimum != nil || s.Maximum != nil) && !numeric {
+		return fmt.Sprintf("minimum and maximum only apply to number and integer parameters, not %s", s.Type)
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		return fmt.Sprintf("minimum %v is greater than maximum %v", *s.Minimum, *s.Maximum)
+	}
+	for _, e := range s.Enum {
+		if !typeAccepts(s.Type, jsonType(e)) {
+			return fmt.Sprintf("enum value %s is not of type %s", jsonString(e), s.Type)
+		}
+	}
+	return ""
+}
+
+// checkParameterValue returns why v does not satisfy s, or "" if it does.
+func checkParameterValue(s types.ParameterSchema, v any) string {
+	if t := jsonType(v); !typeAccepts(s.Type, t) {
+		return fmt.Sprintf("value %s is %s, want %s", jsonString(v), article(t), s.Type)
+	}
+	if n, ok := toFloat(v); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Sprintf("value %s is below minimum %v", jsonString(v), *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Sprintf("value %s is above maximum %v", jsonString(v), *s.Maximum)
+		}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return jsonString(e) == jsonString(v) }) {
+		enum := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			enum = append(enum, jsonString(e))
+		}
+		return fmt.Sprintf("value %s is not one of %s", jsonString(v), strings.Join(enum, ", "))
+	}
+	return ""
+}
+
+// validateValueParamTypes checks that each value_param names a parameter whose type the
+// operator accepts (6.7): in needs an array, ordering operators a number, string and
+// relative time operators a string (see checkOperand for its default) and check.compare an
+// integer. Unknown parameters are reported by validateCheck.
+func validateValueParamTypes(path, ptr string, r *types.Rule, c *types.Check) diag.List {
+	var errs diag.List
+	check := func(field, fieldPtr, name, op string, want ...string) {
+		got := parameterType(r.Parameters, name)
+		if got == "" || slices.ContainsFunc(want, func(w string) bool { return typeAccepts(w, got) }) {
+			return
+		}
+		errs = append(errs, ruleErrorf(diag.CodeValueParam, path, fieldPtr, r, "%s: op=%q needs %s value_param, but parameter %q is %s", field, op, article(strings.Join(want, " or ")), name, article(got)))
+	}
+	predicate := func(field, predPtr string, p *types.Predicate) {
+		name := strings.TrimSpace(p.ValueParam)
+		if name == "" {
+			return
+		}
+		switch p.Op {
+		case types.OperatorIn:
+			check(field, predPtr+"/value_param", name, string(p.Op), "array")
+		case types.OperatorLt, types.OperatorLte, types.OperatorGt, types.OperatorGte:
+			check(field, predPtr+"/value_param", name, string(p.Op), "number")
+		case types.OperatorMatches, types.OperatorStartsWith, types.OperatorEndsWith, types.OperatorEqIgnoreCase, types.OperatorOlderThan, types.OperatorNewerThan:
+			check(field, predPtr+"/value_param", name, string(p.Op), "string")
+			if s, ok := parameterDefault(r.Parameters, name).(string); ok {
+				if msg := checkOperand(p.Op, s); msg != "" {
+					errs = append(errs, ruleErrorf(diag.CodeValueParam, path, predPtr+"/value_param", r, "%s: parameter %q: %s", field, name, msg))
+				}
+			}
+		}
+	}
+	for i := range c.Where {
+		walkComparisons(predicateField("check.where", i), fmt.Sprintf("%s/where/%d", ptr, i), &c.Where[i], predicate)
+	}
+	if c.Assert != nil {
+		walkComparisons("check.assert", ptr+"/assert", c.Assert, predicate)
+	}
+	if c.Compare != nil {
+		if name := strings.TrimSpace(c.Compare.ValueParam); name != "" {
+			want := "integer"
+			if c.Type == types.CheckTypeDatasetAggregateCompare {
+				want = "number"
+			}
+			check("check.compare", ptr+"/compare/value_param", name, string(c.Compare.Op), want)
+		}
+	}
+	return errs
+}
+
+// checkParameterOperand returns why v, a value of parameter name, is not a valid operand
+// of the comparisons of c that read it through value_param (see checkOperand), or "".
+func checkParameterOperand(c *types.Check, name string, v any) string {
+	s, ok := v.(string)
+	if c == nil || !ok {
+		return ""
+	}
+	var msg string
+	visit := func(_, _ string, p *types.Predicate) {
+		if msg == "" && strings.TrimSpace(p.ValueParam) == name {
+			msg = checkOperand(p.Op, s)
+		}
+	}
+	for i := range c.Where {
+		walkComparisons("", "", &c.Where[i], visit)
+	}
+	if c.Assert != nil {
+		walkComparisons("", "", c.Assert, visit)
+	}
+	return msg
+}
+
+// parameterType returns the type of parameter name: its schema type, or the JSON type of
+// its default when it has no schema entry. It returns "" for unknown parameters.
+func parameterType(p *types.Parameters, name string) string {
+	if p == nil {
+		return ""
+	}
+	if s, ok := p.Schema[name]; ok {
+		return s.Type
+	}
+	if v, ok := p.Defaults[name]; ok {
+		return jsonType(v)
+	}
+	return ""
+}
+
+// parameterDefault returns the default of parameter name, or nil if it has none.
+func parameterDefault(p *types.Parameters, name string) any {
+	if p == nil {
+		return nil
+	}
+	return p.Defaults[name]
+}
+
+// inferParameterSchema returns the schema entries implied by parameter values, for checks
+// (such as inline conformance checks) whose parameters carry no schema.
+func inferParameterSchema(values map[string]any) map[string]types.ParameterSchema {
+	out := make(map[string]types.ParameterSchema, len(values))
+	for k, v := range values {
+		out[k] = types.ParameterSchema{Type: jsonType(v)}
+	}
+	return out
+}
+
+// jsonType returns the JSON Schema type of v. Whole numbers are "integer".
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	}
+	if n, ok := toFloat(v); ok {
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// typeAccepts reports whether a value of JSON type got is valid for schema type want.
+func typeAccepts(want, got string) bool {
+	return want == got || (want == "number" && got == "integer")
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	default:
+		return 0, false
+	}
+}
+
+func article(t string) string {
+	switch t {
+	case "array", "integer", "object":
+		return "an " + t
+	default:
+		return "a " + t
+	}
+}
+
+func sortedSchemaKeys(m map[string]types.ParameterSchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
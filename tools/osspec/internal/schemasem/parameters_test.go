@@ -0,0 +1,243 @@
+package schemasem
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+)
+
+// parameterRulesetDoc returns a ruleset whose only rule has the given parameters and a
+// field_compare check on okta:log-streams with the given assert predicate.
+func parameterRulesetDoc(parameters, assert string) string {
+	return strings.NewReplacer("PARAMETERS", parameters, "ASSERT", assert).Replace(`{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.parameters.v1",
+    "name": "Example parameters",
+    "scope": { "kind": "global" },
+    "data_contracts": [
+      { "dataset": "okta:log-streams", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "R1",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["okta:log-streams"],
+        "parameters": PARAMETERS,
+        "check": {
+          "type": "dataset.field_compare",
+          "dataset": "okta:log-streams",
+          "assert": ASSERT
+        }
+      }
+    ]
+  }
+}`)
+}
+
+func TestValidateSemantic_ParameterDefaults(t *testing.T) {
+	const assert = `{ "path": "/name", "op": "eq", "value_param": "p" }`
+	tests := []struct {
+		name       string
+		parameters string
+		want       []string
+	}{
+		{
+			name:       "valid",
+			parameters: `{ "defaults": { "p": 5 }, "schema": { "p": { "type": "integer", "minimum": 1, "maximum": 10 } } }`,
+		},
+		{
+			name:       "integer accepted as number",
+			parameters: `{ "defaults": { "p": 5 }, "schema": { "p": { "type": "number" } } }`,
+		},
+		{
+			name:       "default without schema",
+			parameters: `{ "defaults": { "p": 5 } }`,
+			want:       []string{`/ruleset/rules/0/parameters/defaults/p: rule "R1": parameters.defaults="p" has no parameters.schema entry`},
+		},
+		{
+			name:       "wrong type",
+			parameters: `{ "defaults": { "p": "90" }, "schema": { "p": { "type": "integer" } } }`,
+			want:       []string{`/ruleset/rules/0/parameters/defaults/p: rule "R1": parameters.defaults="p": value "90" is a string, want integer`},
+		},
+		{
+			name:       "fraction for integer",
+			parameters: `{ "defaults": { "p": 1.5 }, "schema": { "p": { "type": "integer" } } }`,
+			want:       []string{`/ruleset/rules/0/parameters/defaults/p: rule "R1": parameters.defaults="p": value 1.5 is a number, want integer`},
+		},
+		{
+			name:       "below minimum",
+			parameters: `{ "defaults": { "p": 0 }, "schema": { "p": { "type": "integer", "minimum": 1 } } }`,
+			want:       []string{`/ruleset/rules/0/parameters/defaults/p: rule "R1": parameters.defaults="p": value 0 is below minimum 1`},
+		},
+		{
+			name:       "above maximum",
+			parameters: `{ "defaults": { "p": 11 }, "schema": { "p": { "type": "number", "maximum": 10.5 } } }`,
+			want:       []string{`/ruleset/rules/0/parameters/defaults/p: rule "R1": parameters.defaults="p": value 11 is above maximum 10.5`},
+		},
+		{
+			name:       "not in enum",
+			parameters: `{ "defaults": { "p": "c" }, "schema": { "p": { "type": "string", "enum": ["a", "b"] } } }`,
+			want:       []string{`/ruleset/rules/0/parameters/defaults/p: rule "R1": parameters.defaults="p": value "c" is not one of "a", "b"`},
+		},
+		{
+			name:       "inconsistent schema",
+			parameters: `{ "defaults": { "p": "a" }, "schema": { "p": { "type": "string", "minimum": 1 } } }`,
+			want:       []string{`/ruleset/rules/0/parameters/schema/p: rule "R1": parameters.schema="p": minimum and maximum only apply to number and integer parameters, not string`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateRulesetDocJSON(t, parameterRulesetDoc(tt.parameters, assert))
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_ValueParamTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters string
+		assert     string
+		want       string
+	}{
+		{
+			name:       "in needs an array",
+			parameters: `{ "defaults": { "p": "x" }, "schema": { "p": { "type": "string" } } }`,
+			assert:     `{ "path": "/name", "op": "in", "value_param": "p" }`,
+			want:       `/ruleset/rules/0/check/assert/value_param: rule "R1": check.assert: op="in" needs an array value_param, but parameter "p" is a string`,
+		},
+		{
+			name:       "ordering needs a number",
+			parameters: `{ "defaults": { "p": "90" }, "schema": { "p": { "type": "string" } } }`,
+			assert:     `{ "path": "/name", "op": "gte", "value_param": "p" }`,
+			want:       `/ruleset/rules/0/check/assert/value_param: rule "R1": check.assert: op="gte" needs a number value_param, but parameter "p" is a string`,
+		},
+		{
+			name:       "in with array",
+			parameters: `{ "defaults": { "p": ["a"] }, "schema": { "p": { "type": "array" } } }`,
+			assert:     `{ "path": "/name", "op": "in", "value_param": "p" }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateRulesetDocJSON(t, parameterRulesetDoc(tt.parameters, tt.assert))
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			var want []string
+			if tt.want != "" {
+				want = []string{tt.want}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_CompareValueParamNeedsInteger(t *testing.T) {
+	errs := validateRulesetDocJSON(t, `{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.compare_param.v1",
+    "name": "Example compare param",
+    "scope": { "kind": "global" },
+    "data_contracts": [
+      { "dataset": "okta:log-streams", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "R1",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["okta:log-streams"],
+        "parameters": {
+          "defaults": { "min": "1" },
+          "schema": { "min": { "type": "string" } }
+        },
+        "check": {
+          "type": "dataset.count_compare",
+          "dataset": "okta:log-streams",
+          "compare": { "op": "gte", "value_param": "min" }
+        }
+      }
+    ]
+  }
+}`)
+	if !containsErr(errs, `check.compare: op="gte" needs an integer value_param, but parameter "min" is a string`) {
+		t.Fatalf("expected compare value_param type error, got:\n%s", joinErrs(errs))
+	}
+}
+
+func TestValidateSemantic_CompareFractionalValue(t *testing.T) {
+	doc := func(check string) string {
+		return strings.Replace(`{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.compare_fractional.v1",
+    "name": "Example compare fractional",
+    "scope": { "kind": "global" },
+    "data_contracts": [
+      { "dataset": "okta:log-streams", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "R1",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["okta:log-streams"],
+        "parameters": {
+          "defaults": { "limit": 2.5 },
+          "schema": { "limit": { "type": "number" } }
+        },
+        "check": CHECK
+      }
+    ]
+  }
+}`, "CHECK", check, 1)
+	}
+	compareErrs := func(errs diag.List) diag.List {
+		var out diag.List
+		for _, e := range errs {
+			if strings.Contains(e.Error(), "check.compare") {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+
+	// Counts are compared with integers.
+	errs := compareErrs(validateRulesetDocJSON(t, doc(`{ "type": "dataset.count_compare", "dataset": "okta:log-streams", "compare": { "op": "lte", "value": 2.5 } }`)))
+	if !containsErr(errs, `check.compare value must be an integer for dataset.count_compare`) {
+		t.Fatalf("expected fractional value error, got:\n%s", joinErrs(errs))
+	}
+	errs = compareErrs(validateRulesetDocJSON(t, doc(`{ "type": "dataset.count_compare", "dataset": "okta:log-streams", "compare": { "op": "lte", "value_param": "limit" } }`)))
+	if !containsErr(errs, `check.compare: op="lte" needs an integer value_param, but parameter "limit" is a number`) {
+		t.Fatalf("expected number value_param error, got:\n%s", joinErrs(errs))
+	}
+
+	// Aggregates may be compared with any number.
+	for _, compare := range []string{`{ "op": "lte", "value": 2.5 }`, `{ "op": "lte", "value_param": "limit" }`} {
+		errs = compareErrs(validateRulesetDocJSON(t, doc(`{ "type": "dataset.aggregate_compare", "dataset": "okta:log-streams", "aggregate": { "function": "avg", "path": "/id" }, "compare": `+compare+` }`)))
+		if len(errs) != 0 {
+			t.Fatalf("compare %s: unexpected errors:\n%s", compare, joinErrs(errs))
+		}
+	}
+}
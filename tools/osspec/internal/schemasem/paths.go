@@ -0,0 +1,510 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// contractSchema is a decoded dataset contract schema used to resolve JSON pointers.
+type contractSchema struct {
+	ref  string // "dataset@version"
+	path string
+	root map[string]any
+}
+
+// pathInfo describes what a JSON pointer resolves to in a contract schema.
+type pathInfo struct {
+	// types lists the JSON Schema types the value may have. It is empty when the
+	// schema does not constrain the type (untyped or opaque objects).
+	types []string
+	// formats lists the JSON Schema formats declared for the value, if any.
+	formats []string
+}
+
+func (p pathInfo) known() bool { return len(p.types) > 0 }
+
+// String describes the value's types and formats for messages, such as
+// `string (format "date-time")`.
+func (p pathInfo) String() string {
+	s := strings.Join(p.types, "|")
+	if len(p.formats) > 0 {
+		s += fmt.Sprintf(" (format %q)", strings.Join(p.formats, "|"))
+	}
+	return s
+}
+
+func (p pathInfo) allows(t ...string) bool {
+	for _, want := range t {
+		if slices.Contains(p.types, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexContractSchemas(b *Bundle) map[string]*contractSchema {
+	out := map[string]*contractSchema{}
+	for _, dc := range b.DatasetContracts {
+		k := fmt.Sprintf("%s@%d", dc.Doc.Dataset.Key, dc.Doc.Dataset.Version)
+		if _, ok := out[k]; ok {
+			continue
+		}
+		var root map[string]any
+		if err := json.Unmarshal(dc.Doc.Dataset.Schema, &root); err != nil {
+			continue
+		}
+		out[k] = &contractSchema{ref: k, path: dc.Path, root: root}
+	}
+	return out
+}
+
+// resolve walks pointer through the schema, following $ref (local), allOf/anyOf/oneOf,
+// properties, additionalProperties and items. A PathWildcard segment resolves to the items
+// of an array. Objects that declare no properties are opaque and accept any sub-path.
+func (cs *contractSchema) resolve(pointer string) (pathInfo, error) {
+	if pointer == "" {
+		return pathInfo{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return pathInfo{}, fmt.Errorf("must be a JSON pointer starting with '/'")
+	}
+
+	nodes := cs.expand(cs.root, 0)
+	walked := ""
+	for _, seg := range strings.Split(pointer[1:], "/") {
+		seg = strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+		var (
+			next   []map[string]any
+			opaque bool
+			leaf   []string
+		)
+		for _, n := range nodes {
+			children, isOpaque, ok := cs.child(n, seg)
+			if isOpaque {
+				opaque = true
+				continue
+			}
+			if ok {
+				next = append(next, children...)
+				continue
+			}
+			if t := schemaTypes(n); len(t) > 0 && !slices.Contains(t, "object") && !slices.Contains(t, "array") {
+				leaf = append(leaf, t...)
+			}
+		}
+		if opaque {
+			return pathInfo{}, nil
+		}
+		if len(next) == 0 {
+			if seg == types.PathWildcard {
+				return pathInfo{}, fmt.Errorf("%q is not an array, so %q matches nothing", or(walked, "/"), seg)
+			}
+			if len(leaf) > 0 {
+				return pathInfo{}, fmt.Errorf("%q is a %s field and has no %q", or(walked, "/"), strings.Join(leaf, "|"), seg)
+			}
+			return pathInfo{}, fmt.Errorf("%q is not declared at %q", seg, or(walked, "/"))
+		}
+		nodes = next
+		walked += "/" + seg
+	}
+
+	var info pathInfo
+	for _, n := range nodes {
+		t := schemaTypes(n)
+		if len(t) == 0 {
+			return pathInfo{}, nil
+		}
+		info.types = append(info.types, t...)
+		if f, ok := n["format"].(string); ok {
+			info.formats = append(info.formats, f)
+		}
+	}
+	slices.Sort(info.types)
+	info.types = slices.Compact(info.types)
+	slices.Sort(info.formats)
+	info.formats = slices.Compact(info.formats)
+	return info, nil
+}
+
+// child returns the schemas for seg below n. isOpaque reports that n does not describe its
+// children, so any sub-path is accepted.
+func (cs *contractSchema) child(n map[string]any, seg string) (children []map[string]any, isOpaque, ok bool) {
+	t := schemaTypes(n)
+	typed := len(t) > 0
+
+	if seg == types.PathWildcard {
+		if typed && !slices.Contains(t, "array") {
+			return nil, false, false
+		}
+		switch items := n["items"].(type) {
+		case map[string]any:
+			return cs.expand(items, 0), false, true
+		case []any:
+			for _, item := range items {
+				if m, isMap := item.(map[string]any); isMap {
+					children = append(children, cs.expand(m, 0)...)
+				}
+			}
+			return children, len(children) == 0, len(children) > 0
+		default:
+			return nil, true, false
+		}
+	}
+	if !typed || slices.Contains(t, "array") {
+		if i, err := strconv.Atoi(seg); err == nil && i >= 0 {
+			switch items := n["items"].(type) {
+			case map[string]any:
+				return cs.expand(items, 0), false, true
+			case []any:
+				if i < len(items) {
+					if m, isMap := items[i].(map[string]any); isMap {
+						return cs.expand(m, 0), false, true
+					}
+				}
+				return nil, true, false
+			case nil:
+				if typed && !slices.Contains(t, "object") {
+					return nil, true, false
+				}
+			default:
+				return nil, true, false
+			}
+		}
+	}
+	if typed && !slices.Contains(t, "object") {
+		return nil, false, false
+	}
+
+	props, hasProps := n["properties"].(map[string]any)
+	if hasProps {
+		if p, found := props[seg]; found {
+			if m, isMap := p.(map[string]any); isMap {
+				return cs.expand(m, 0), false, true
+			}
+			return nil, true, false
+		}
+	}
+	if ap, isMap := n["additionalProperties"].(map[string]any); isMap {
+		return cs.expand(ap, 0), false, true
+	}
+	if hasProps || n["patternProperties"] != nil {
+		return nil, false, false
+	}
+	// Objects without declared properties (including untyped schemas) are opaque.
+	return nil, true, false
+}
+
+// expand follows local $ref and flattens allOf/anyOf/oneOf into candidate schemas.
+func (cs *contractSchema) expand(n map[string]any, depth int) []map[string]any {
+	if depth > 32 {
+		return []map[string]any{{}}
+	}
+	if ref, ok := n["$ref"].(string); ok {
+		target, ok := cs.lookupRef(ref)
+		if !ok {
+			// Remote or unresolvable references are treated as opaque.
+			return []map[string]any{{}}
+		}
+		return cs.expand(target, depth+1)
+	}
+	out := []map[string]any{n}
+	for _, kw := range []string{"allOf", "anyOf", "oneOf"} {
+		list, _ := n[kw].([]any)
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok {
+				out = append(out, cs.expand(m, depth+1)...)
+			}
+		}
+	}
+	return out
+}
+
+func (cs *contractSchema) lookupRef(ref string) (map[string]any, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	var cur any = cs.root
+	if ref == "#" {
+		return cs.root, true
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	for _, seg := range strings.Split(ref[2:], "/") {
+		seg = strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[seg]; !ok {
+			return nil, false
+		}
+	}
+	m, ok := cur.(map[string]any)
+	return m, ok
+}
+
+func schemaTypes(n map[string]any) []string {
+	switch t := n["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var out []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func or(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// validatePaths resolves predicate paths, join key paths, evidence fields and contract
+// primary keys against dataset contract schemas, and checks operator/type compatibility.
+// References to datasets without a contract are skipped (see validateReferences).
+func validatePaths(b *Bundle) diag.List {
+	var errs diag.List
+	schemas := indexContractSchemas(b)
+
+	for _, dc := range b.DatasetContracts {
+		cs := schemas[fmt.Sprintf("%s@%d", dc.Doc.Dataset.Key, dc.Doc.Dataset.Version)]
+		if cs == nil || cs.path != dc.Path {
+			continue
+		}
+		for _, f := range []struct{ name, ptr, pointer string }{
+			{"dataset.primary_key", "/dataset/primary_key", dc.Doc.Dataset.PrimaryKey},
+			{"dataset.recommended_display", "/dataset/recommended_display", dc.Doc.Dataset.RecommendedDisplay},
+		} {
+			if f.pointer == "" {
+				continue
+			}
+			if types.HasWildcard(f.pointer) {
+				errs = append(errs, diag.New(diag.CodeUnknownPath, dc.Path, f.ptr, "%s %q: %q wildcards are only allowed in predicate paths", f.name, f.pointer, types.PathWildcard))
+				continue
+			}
+			if _, err := cs.resolve(f.pointer); err != nil {
+				errs = append(errs, diag.New(diag.CodeUnknownPath, dc.Path, f.ptr, "%s %q: %v", f.name, f.pointer, err))
+			}
+		}
+	}
+
+	for _, rs := range b.Rulesets {
+		ruleset := &rs.Doc.Ruleset
+		for i := range ruleset.Rules {
+			r := &ruleset.Rules[i]
+			pv := pathValidator{path: rs.Path, ptr: fmt.Sprintf("/ruleset/rules/%d", i), rule: r, ruleset: ruleset, schemas: schemas}
+			errs = append(errs, pv.validateRule()...)
+		}
+	}
+	return errs
+}
+
+type pathValidator struct {
+	path    string
+	ptr     string // pointer to the rule
+	rule    *types.Rule
+	ruleset *types.Ruleset
+	schemas map[string]*contractSchema
+}
+
+func (pv *pathValidator) contract(dataset string) *contractSchema {
+	if strings.TrimSpace(dataset) == "" {
+		return nil
+	}
+	version := 0
+	if pv.rule.Check != nil && slices.Contains(datasetsReferencedByCheck(pv.rule.Check), dataset) {
+		version = pv.rule.Check.DatasetVersion
+	}
+	return pv.schemas[fmt.Sprintf("%s@%d", dataset, types.EffectiveDatasetVersion(dataset, pv.ruleset.DataContracts, version))]
+}
+
+// errorf reports a diagnostic at ptr (relative to the rule) with the contract schema as a
+// related location.
+func (pv *pathValidator) errorf(code, ptr string, cs *contractSchema, format string, args ...any) diag.List {
+	d := diag.New(code, pv.path, pv.ptr+ptr, "rule %q: %s", pv.rule.Key, fmt.Sprintf(format, args...))
+	return diag.List{d.WithRelated(cs.path, "/dataset/schema")}
+}
+
+func (pv *pathValidator) validateRule() diag.List {
+	var errs diag.List
+	c := pv.rule.Check
+	if c != nil {
+		switch c.Type {
+		case types.CheckTypeDatasetFieldCompare, types.CheckTypeDatasetCountCompare, types.CheckTypeDatasetAggregateCompare:
+			cs := pv.contract(c.Dataset)
+			if c.Aggregate != nil {
+				errs = append(errs, pv.validateAggregate(cs, c.Aggregate)...)
+			}
+			predicate := func(field, ptr string, p *types.Predicate) {
+				errs = append(errs, pv.validatePredicate(field, ptr, "/path", cs, p.Path, *p)...)
+			}
+			for i := range c.Where {
+				walkComparisons(fmt.Sprintf("check.where[%d]", i), fmt.Sprintf("/check/where/%d", i), &c.Where[i], predicate)
+			}
+			if c.Assert != nil {
+				walkComparisons("check.assert", "/check/assert", c.Assert, predicate)
+			}
+		case types.CheckTypeDatasetJoinCountCompare, types.CheckTypeDatasetJoinFieldCompare:
+			var left, right *contractSchema
+			if c.Left != nil {
+				left = pv.contract(c.Left.Dataset)
+				errs = append(errs, pv.validateField("check.left.key_path", "/check/left/key_path", left, c.Left.KeyPath)...)
+			}
+			if c.Right != nil {
+				right = pv.contract(c.Right.Dataset)
+				errs = append(errs, pv.validateField("check.right.key_path", "/check/right/key_path", right, c.Right.KeyPath)...)
+			}
+			predicate := func(field, ptr string, p *types.Predicate) {
+				if p.LeftPath != "" {
+					errs = append(errs, pv.validatePredicate(field+".left_path", ptr, "/left_path", left, p.LeftPath, *p)...)
+				}
+				if p.RightPath != "" {
+					errs = append(errs, pv.validatePredicate(field+".right_path", ptr, "/right_path", right, p.RightPath, *p)...)
+				}
+			}
+			for i := range c.Where {
+				walkComparisons(fmt.Sprintf("check.where[%d]", i), fmt.Sprintf("/check/where/%d", i), &c.Where[i], predicate)
+			}
+			if c.Assert != nil {
+				walkComparisons("check.assert", "/check/assert", c.Assert, predicate)
+			}
+		}
+	}
+	if pv.rule.Evidence != nil && pv.rule.Evidence.AffectedResources != nil {
+		ar := pv.rule.Evidence.AffectedResources
+		cs := pv.contract(ar.Dataset)
+		errs = append(errs, pv.validateField("evidence.affected_resources.id_field", "/evidence/affected_resources/id_field", cs, ar.IDField)...)
+		errs = append(errs, pv.validateField("evidence.affected_resources.display_field", "/evidence/affected_resources/display_field", cs, ar.DisplayField)...)
+	}
+	return errs
+}
+
+// validateAggregate checks that check.aggregate.path resolves, and to a numeric field unless
+// the function is count_distinct, which counts values of any type.
+func (pv *pathValidator) validateAggregate(cs *contractSchema, a *types.Aggregate) diag.List {
+	const field, ptr = "check.aggregate.path", "/check/aggregate/path"
+	if errs := pv.validateField(field, ptr, cs, a.Path); len(errs) > 0 || cs == nil || a.Function == types.AggregateFunctionCountDistinct {
+		return errs
+	}
+	info, err := cs.resolve(a.Path)
+	if err != nil || !info.known() || info.allows("integer", "number") {
+		return nil
+	}
+	return pv.errorf(diag.CodeTypeMismatch, "/check/aggregate/function", cs, "check.aggregate: function=%q requires a numeric field, but %q is %s in dataset contract %q (%s)", a.Function, a.Path, info, cs.ref, cs.path)
+}
+
+func (pv *pathValidator) validateField(field, ptr string, cs *contractSchema, pointer string) diag.List {
+	if cs == nil || pointer == "" {
+		return nil
+	}
+	if types.HasWildcard(pointer) {
+		return pv.errorf(diag.CodeUnknownPath, ptr, cs, "%s %q: %q wildcards are only allowed in predicate paths", field, pointer, types.PathWildcard)
+	}
+	if _, err := cs.resolve(pointer); err != nil {
+		return pv.errorf(diag.CodeUnknownPath, ptr, cs, "%s %q: %v in dataset contract %q (%s)", field, pointer, err, cs.ref, cs.path)
+	}
+	return nil
+}
+
+// validatePredicate checks the predicate at ptr; pathPtr names the path member within it.
+func (pv *pathValidator) validatePredicate(field, ptr, pathPtr string, cs *contractSchema, pointer string, p types.Predicate) diag.List {
+	if cs == nil || strings.TrimSpace(pointer) == "" {
+		return nil
+	}
+	info, err := cs.resolve(pointer)
+	if err != nil {
+		return pv.errorf(diag.CodeUnknownPath, ptr+pathPtr, cs, "%s: path %q: %v in dataset contract %q (%s)", field, pointer, err, cs.ref, cs.path)
+	}
+	if !info.known() {
+		return nil
+	}
+
+	mismatch := func(want string) diag.List {
+		return pv.errorf(diag.CodeTypeMismatch, ptr+"/op", cs, "%s: op=%q requires %s field, but %q is %s in dataset contract %q (%s)", field, p.Op, want, pointer, info, cs.ref, cs.path)
+	}
+	switch p.Op {
+	case types.OperatorLt, types.OperatorLte, types.OperatorGt, types.OperatorGte:
+		if !info.allows("integer", "number") {
+			return mismatch("a numeric")
+		}
+	case types.OperatorContains:
+		if !info.allows("array") {
+			return mismatch("an array")
+		}
+	case types.OperatorMatches, types.OperatorStartsWith, types.OperatorEndsWith, types.OperatorEqIgnoreCase:
+		if !info.allows("string") {
+			return mismatch("a string")
+		}
+	case types.OperatorOlderThan, types.OperatorNewerThan:
+		if !info.allows("string") || !slices.Contains(info.formats, "date-time") {
+			return mismatch(`a "date-time" formatted string`)
+		}
+	}
+
+	// Literal values must be representable by the field's type.
+	switch p.Op {
+	case types.OperatorEq, types.OperatorNeq, types.OperatorLt, types.OperatorLte, types.OperatorGt, types.OperatorGte:
+		if p.Value != nil && !info.acceptsValue(p.Value) {
+			return pv.errorf(diag.CodeTypeMismatch, ptr+"/value", cs, "%s: value %s does not match type %s of %q in dataset contract %q (%s)", field, jsonString(p.Value), strings.Join(info.types, "|"), pointer, cs.ref, cs.path)
+		}
+	case types.OperatorIn:
+		list, _ := p.Value.([]any)
+		for i, v := range list {
+			if !info.acceptsValue(v) {
+				return pv.errorf(diag.CodeTypeMismatch, fmt.Sprintf("%s/value/%d", ptr, i), cs, "%s: value %s does not match type %s of %q in dataset contract %q (%s)", field, jsonString(v), strings.Join(info.types, "|"), pointer, cs.ref, cs.path)
+			}
+		}
+	}
+	return nil
+}
+
+func (p pathInfo) acceptsValue(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return p.allows("null")
+	case bool:
+		return p.allows("boolean")
+	case string:
+		return p.allows("string")
+	case float64:
+		if x == math.Trunc(x) && p.allows("integer") {
+			return true
+		}
+		return p.allows("number")
+	case json.Number:
+		if _, err := x.Int64(); err == nil && p.allows("integer") {
+			return true
+		}
+		return p.allows("number")
+	case []any:
+		return p.allows("array")
+	case map[string]any:
+		return p.allows("object")
+	default:
+		return true
+	}
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,218 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+const usersContractSchema = `{
+  "type": "object",
+  "definitions": {
+    "profile": {
+      "type": "object",
+      "properties": {
+        "email": { "type": "string" },
+        "age_days": { "type": "integer" },
+        "last_login": { "type": "string", "format": "date-time" }
+      }
+    }
+  },
+  "properties": {
+    "id": { "type": "string" },
+    "status": { "type": "string" },
+    "profile": { "$ref": "#/definitions/profile" },
+    "groups": { "type": "array", "items": { "type": "object", "properties": { "name": { "type": "string" } } } },
+    "tags": { "type": "array", "items": { "type": "string" } },
+    "settings": { "type": "object", "additionalProperties": true },
+    "labels": { "type": "object", "additionalProperties": { "type": "string" } }
+  }
+}`
+
+func pathsBundle(rule types.Rule, primaryKey string) *Bundle {
+	rs := minimalRulesetDoc("r1", types.Scope{Kind: types.ScopeKindGlobal})
+	rs.Ruleset.DataContracts = []types.DatasetContractRef{{Dataset: "core:users", Version: 1}}
+	rs.Ruleset.Rules = []types.Rule{rule}
+	return &Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{{Path: "specs/rulesets/r1.json", Doc: rs}},
+		DatasetContracts: []struct {
+			Path string
+			Doc  types.DatasetContractDoc
+		}{{Path: "specs/datasets/core/users/v1.json", Doc: types.DatasetContractDoc{Dataset: types.DatasetContract{
+			Key: "core:users", Version: 1, PrimaryKey: primaryKey, Schema: json.RawMessage(usersContractSchema),
+		}}}},
+	}
+}
+
+func fieldCompareRule(where []types.Predicate, assert types.Predicate) types.Rule {
+	return types.Rule{
+		Key:          "R1",
+		Title:        "R1",
+		Severity:     types.SeverityLow,
+		Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+		RequiredData: []string{"core:users"},
+		Check: &types.Check{
+			Type:    types.CheckTypeDatasetFieldCompare,
+			Dataset: "core:users",
+			Where:   where,
+			Assert:  &assert,
+		},
+		Evidence: &types.Evidence{AffectedResources: &types.AffectedResources{Dataset: "core:users", IDField: "/id", DisplayField: "/profile/email"}},
+	}
+}
+
+func TestValidateSemantic_PathsResolveAgainstContractSchema(t *testing.T) {
+	rule := fieldCompareRule(
+		[]types.Predicate{
+			{Path: "/status", Op: types.OperatorIn, Value: []any{"ACTIVE", "LOCKED"}},
+			{Path: "/groups/0/name", Op: types.OperatorEq, Value: "admins"},
+			{Path: "/settings/anything/below", Op: types.OperatorExists},
+			{Path: "/labels/team", Op: types.OperatorEq, Value: "sec"},
+			{Path: "/profile/email", Op: types.OperatorEndsWith, Value: "@example.com"},
+			{Path: "/profile/last_login", Op: types.OperatorNewerThan, Value: "P90D"},
+		},
+		types.Predicate{Path: "/profile/age_days", Op: types.OperatorLte, Value: float64(90)},
+	)
+	if errs := ValidateSemantic(pathsBundle(rule, "/id")); len(errs) != 0 {
+		t.Fatalf("expected no errors, got:\n%s", joinErrs(errs))
+	}
+}
+
+func TestValidateSemantic_PathErrors(t *testing.T) {
+	rule := fieldCompareRule(
+		[]types.Predicate{
+			{Path: "/profile/emial", Op: types.OperatorExists},
+			{Path: "/status/code", Op: types.OperatorExists},
+			{Path: "/profile/email", Op: types.OperatorLt, Value: "a"},
+			{Path: "/status", Op: types.OperatorContains, Value: "A"},
+			{Path: "/profile/age_days", Op: types.OperatorEq, Value: "90"},
+			{Path: "/profile/age_days", Op: types.OperatorMatches, Value: "^9"},
+			{Path: "/profile/email", Op: types.OperatorOlderThan, Value: "P90D"},
+		},
+		types.Predicate{Path: "/tags", Op: types.OperatorContains, Value: "x"},
+	)
+	rule.Evidence.AffectedResources.DisplayField = "/mail"
+	errs := ValidateSemantic(pathsBundle(rule, "/uid"))
+	for _, want := range []string{
+		`check.where[0]: path "/profile/emial": "emial" is not declared at "/profile" in dataset contract "core:users@1" (specs/datasets/core/users/v1.json)`,
+		`check.where[1]: path "/status/code": "/status" is a string field and has no "code"`,
+		`check.where[2]: op="lt" requires a numeric field, but "/profile/email" is string`,
+		`check.where[3]: op="contains" requires an array field, but "/status" is string`,
+		`check.where[4]: value "90" does not match type integer of "/profile/age_days"`,
+		`check.where[5]: op="matches" requires a string field, but "/profile/age_days" is integer`,
+		`check.where[6]: op="older_than" requires a "date-time" formatted string field, but "/profile/email" is string in dataset contract`,
+		`evidence.affected_resources.display_field "/mail": "mail" is not declared at "/"`,
+		`specs/datasets/core/users/v1.json: dataset.primary_key "/uid": "uid" is not declared at "/"`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+	if containsErr(errs, "check.assert") {
+		t.Fatalf("unexpected error for valid assert, got:\n%s", joinErrs(errs))
+	}
+}
+
+func TestValidateSemantic_WildcardPaths(t *testing.T) {
+	rule := fieldCompareRule(
+		[]types.Predicate{
+			{Path: "/groups/*/name", Op: types.OperatorEq, Value: "admins", Quantifier: types.QuantifierAny},
+			{Path: "/tags/*", Op: types.OperatorIn, Value: []any{"a", "b"}, Quantifier: types.QuantifierAll},
+			{Path: "/settings/list/*/below", Op: types.OperatorExists, Quantifier: types.QuantifierAny},
+		},
+		types.Predicate{Path: "/profile/age_days", Op: types.OperatorLte, Value: float64(90)},
+	)
+	if errs := ValidateSemantic(pathsBundle(rule, "/id")); len(errs) != 0 {
+		t.Fatalf("expected no errors, got:\n%s", joinErrs(errs))
+	}
+
+	rule = fieldCompareRule(
+		[]types.Predicate{
+			{Path: "/status/*", Op: types.OperatorExists, Quantifier: types.QuantifierAny},
+			{Path: "/groups/*/nmae", Op: types.OperatorExists, Quantifier: types.QuantifierAll},
+			{Path: "/tags/*", Op: types.OperatorGt, Value: float64(1), Quantifier: types.QuantifierAny},
+			{Path: "/profile/email", Op: types.OperatorExists, Quantifier: types.QuantifierAll},
+		},
+		types.Predicate{Path: "/profile/age_days", Op: types.OperatorLte, Value: float64(90)},
+	)
+	rule.Evidence.AffectedResources.IDField = "/groups/*/name"
+	errs := ValidateSemantic(pathsBundle(rule, "/id"))
+	for _, want := range []string{
+		`check.where[0]: path "/status/*": "/status" is not an array, so "*" matches nothing`,
+		`check.where[1]: path "/groups/*/nmae": "nmae" is not declared at "/groups/*"`,
+		`check.where[2]: op="gt" requires a numeric field, but "/tags/*" is string`,
+		`check.where[3]: quantifier="all" requires a path with a "*" wildcard segment`,
+		`evidence.affected_resources.id_field "/groups/*/name": "*" wildcards are only allowed in predicate paths`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+}
+
+func TestValidateSemantic_AggregatePaths(t *testing.T) {
+	aggregateRule := func(a types.Aggregate) types.Rule {
+		one := 1.0
+		rule := fieldCompareRule(nil, types.Predicate{})
+		rule.Check = &types.Check{
+			Type:      types.CheckTypeDatasetAggregateCompare,
+			Dataset:   "core:users",
+			Aggregate: &a,
+			Compare:   &types.Compare{Op: types.CompareOpGte, Value: &one},
+		}
+		return rule
+	}
+
+	for _, a := range []types.Aggregate{
+		{Function: types.AggregateFunctionAvg, Path: "/profile/age_days"},
+		{Function: types.AggregateFunctionCountDistinct, Path: "/status"},
+	} {
+		if errs := ValidateSemantic(pathsBundle(aggregateRule(a), "/id")); len(errs) != 0 {
+			t.Fatalf("%s of %s: expected no errors, got:\n%s", a.Function, a.Path, joinErrs(errs))
+		}
+	}
+
+	for _, tc := range []struct {
+		aggregate types.Aggregate
+		want      string
+	}{
+		{types.Aggregate{Function: types.AggregateFunctionSum, Path: "/status"}, `check.aggregate: function="sum" requires a numeric field, but "/status" is string`},
+		{types.Aggregate{Function: types.AggregateFunctionMax, Path: "/profile/age"}, `check.aggregate.path "/profile/age": "age" is not declared at "/profile"`},
+		{types.Aggregate{Function: types.AggregateFunctionCountDistinct, Path: "/tags/*"}, `check.aggregate.path "/tags/*": "*" wildcards are only allowed in predicate paths`},
+	} {
+		errs := ValidateSemantic(pathsBundle(aggregateRule(tc.aggregate), "/id"))
+		if !containsErr(errs, tc.want) {
+			t.Fatalf("expected error containing %q, got:\n%s", tc.want, joinErrs(errs))
+		}
+	}
+}
+
+func TestValidateSemantic_JoinFieldComparePaths(t *testing.T) {
+	rule := fieldCompareRule(nil, types.Predicate{})
+	rule.Check = &types.Check{
+		Type:  types.CheckTypeDatasetJoinFieldCompare,
+		Left:  &types.JoinSide{Dataset: "core:users", KeyPath: "/id"},
+		Right: &types.JoinSide{Dataset: "core:users", KeyPath: "/profile/email"},
+		Assert: &types.Predicate{AnyOf: []types.Predicate{
+			{LeftPath: "/status", Op: types.OperatorEq, Value: "ACTIVE"},
+			{RightPath: "/profile/age", Op: types.OperatorLt, Value: float64(30)},
+			{RightPath: "/status", Op: types.OperatorGt, Value: float64(1)},
+		}},
+	}
+	errs := ValidateSemantic(pathsBundle(rule, "/id"))
+	for _, want := range []string{
+		`check.assert.any_of[1].right_path: path "/profile/age": "age" is not declared at "/profile"`,
+		`check.assert.any_of[2].right_path: op="gt" requires a numeric field, but "/status" is string`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+	if containsErr(errs, "any_of[0]") {
+		t.Fatalf("unexpected error for valid left_path, got:\n%s", joinErrs(errs))
+	}
+}
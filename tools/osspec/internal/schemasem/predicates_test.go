@@ -0,0 +1,224 @@
+package schemasem
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestValidateSemantic_PredicateGroups(t *testing.T) {
+	const parameters = `{ "defaults": { "p": "x" }, "schema": { "p": { "type": "string" } } }`
+	tests := []struct {
+		name   string
+		assert string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			assert: `{ "any_of": [ { "path": "/name", "op": "eq", "value_param": "p" }, { "not": { "path": "/status", "op": "exists" } } ] }`,
+		},
+		{
+			// The invalid member sorts last during normalization; the pointer maps back to the
+			// source, the field name (like check.where[i]) names the normalized position.
+			name:   "nested comparison",
+			assert: `{ "any_of": [ { "path": "/z", "op": "exists", "value": 1 }, { "path": "/a", "op": "exists" } ] }`,
+			want:   []string{`/ruleset/rules/0/check/assert/any_of/0/op: rule "R1": check.assert.any_of[1]: op="exists" forbids value and value_param`},
+		},
+		{
+			name:   "nested value_param type",
+			assert: `{ "all_of": [ { "not": { "path": "/n", "op": "gte", "value_param": "p" } } ] }`,
+			want:   []string{`/ruleset/rules/0/check/assert/all_of/0/not/value_param: rule "R1": check.assert.all_of[0].not: op="gte" needs a number value_param, but parameter "p" is a string`},
+		},
+		{
+			name:   "nested unknown value_param",
+			assert: `{ "not": { "path": "/n", "op": "eq", "value_param": "q" } }`,
+			want:   []string{`/ruleset/rules/0/check: rule "R1": value_param "q" not found in parameters.defaults`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range validateRulesetDocJSON(t, parameterRulesetDoc(parameters, tt.assert)) {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidatePredicateGroup(t *testing.T) {
+	leaf := types.Predicate{Path: "/a", Op: types.OperatorExists}
+	tests := []struct {
+		name string
+		p    types.Predicate
+		want []string
+	}{
+		{
+			name: "several groups",
+			p:    types.Predicate{AllOf: []types.Predicate{leaf}, Not: &leaf},
+			want: []string{`rule "R1": check.assert: must set exactly one of all_of, any_of or not`},
+		},
+		{
+			name: "group with comparison fields",
+			p:    types.Predicate{Path: "/a", AnyOf: []types.Predicate{leaf}},
+			want: []string{`rule "R1": check.assert: all_of/any_of/not cannot be combined with path, left_path, right_path, op, value or value_param`},
+		},
+		{
+			name: "empty group",
+			p:    types.Predicate{AllOf: []types.Predicate{}},
+			want: []string{`rule "R1": check.assert: all_of must not be empty`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range validatePredicate("inline.json", "/check/assert", "R1", "check.assert", -1, tt.p) {
+				got = append(got, e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_JoinGroupMixesSides(t *testing.T) {
+	errs := validateRulesetDocJSON(t, `{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.join_group.v1",
+    "name": "Example join group",
+    "scope": { "kind": "global" },
+    "data_contracts": [
+      { "dataset": "core:identities", "version": 1 },
+      { "dataset": "core:entitlement_assignments", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "R1",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["core:identities", "core:entitlement_assignments"],
+        "check": {
+          "type": "dataset.join_count_compare",
+          "left": { "dataset": "core:identities", "key_path": "/email" },
+          "right": { "dataset": "core:entitlement_assignments", "key_path": "/identity/email" },
+          "where": [
+            { "any_of": [ { "left_path": "/status", "op": "eq", "value": "ACTIVE" }, { "right_path": "/active", "op": "eq", "value": true } ] }
+          ],
+          "compare": { "op": "eq", "value": 0 }
+        }
+      }
+    ]
+  }
+}`)
+	if !containsErr(errs, `check.where[0]: group mixes left_path and right_path predicates`) {
+		t.Fatalf("expected join group side error, got:\n%s", joinErrs(errs))
+	}
+}
+
+func TestValidateSemantic_StringOperators(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters string
+		assert     string
+		want       []string
+	}{
+		{
+			name:   "valid",
+			assert: `{ "all_of": [ { "path": "/name", "op": "matches", "value": "^siem-[a-z]+$" }, { "path": "/type", "op": "eq_ignore_case", "value": "splunk" } ] }`,
+		},
+		{
+			name:   "invalid pattern",
+			assert: `{ "path": "/name", "op": "matches", "value": "siem-(" }`,
+			want:   []string{"/ruleset/rules/0/check/assert/value: rule \"R1\": check.assert: op=\"matches\": \"siem-(\" is not a valid RE2 regular expression: error parsing regexp: missing closing ): `siem-(`"},
+		},
+		{
+			name:   "non-string value",
+			assert: `{ "path": "/name", "op": "starts_with", "value": 1 }`,
+			want:   []string{`/ruleset/rules/0/check/assert/value: rule "R1": check.assert: op="starts_with" requires a string value, got 1`},
+		},
+		{
+			name:   "missing value",
+			assert: `{ "path": "/name", "op": "ends_with" }`,
+			want:   []string{`/ruleset/rules/0/check/assert/value: rule "R1": check.assert: op="ends_with" requires a string value, got null`},
+		},
+		{
+			name:       "invalid pattern default",
+			parameters: `{ "defaults": { "p": "(?=x)" }, "schema": { "p": { "type": "string" } } }`,
+			assert:     `{ "path": "/name", "op": "matches", "value_param": "p" }`,
+			want:       []string{"/ruleset/rules/0/check/assert/value_param: rule \"R1\": check.assert: parameter \"p\": op=\"matches\": \"(?=x)\" is not a valid RE2 regular expression: error parsing regexp: invalid or unsupported Perl syntax: `(?=`"},
+		},
+		{
+			name:       "value_param type",
+			parameters: `{ "defaults": { "p": 1 }, "schema": { "p": { "type": "integer" } } }`,
+			assert:     `{ "path": "/name", "op": "eq_ignore_case", "value_param": "p" }`,
+			want:       []string{`/ruleset/rules/0/check/assert/value_param: rule "R1": check.assert: op="eq_ignore_case" needs a string value_param, but parameter "p" is an integer`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := tt.parameters
+			if parameters == "" {
+				parameters = `{ "defaults": { "p": "x" }, "schema": { "p": { "type": "string" } } }`
+			}
+			var got []string
+			for _, e := range validateRulesetDocJSON(t, parameterRulesetDoc(parameters, tt.assert)) {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_RelativeTimeOperators(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters string
+		assert     string
+		want       []string
+	}{
+		{
+			name:   "valid",
+			assert: `{ "any_of": [ { "path": "/name", "op": "older_than", "value": "P1Y6M" }, { "path": "/name", "op": "newer_than", "value_param": "p" } ] }`,
+		},
+		{
+			name:   "invalid duration",
+			assert: `{ "path": "/name", "op": "older_than", "value": "90 days" }`,
+			want:   []string{`/ruleset/rules/0/check/assert/value: rule "R1": check.assert: op="older_than": "90 days" is not an ISO-8601 duration (such as P90D or PT12H)`},
+		},
+		{
+			name:   "non-string value",
+			assert: `{ "path": "/name", "op": "newer_than", "value": 90 }`,
+			want:   []string{`/ruleset/rules/0/check/assert/value: rule "R1": check.assert: op="newer_than" requires an ISO-8601 duration value, got 90`},
+		},
+		{
+			name:       "invalid duration default",
+			parameters: `{ "defaults": { "p": "P90" }, "schema": { "p": { "type": "string" } } }`,
+			assert:     `{ "path": "/name", "op": "newer_than", "value_param": "p" }`,
+			want:       []string{`/ruleset/rules/0/check/assert/value_param: rule "R1": check.assert: parameter "p": op="newer_than": "P90" is not an ISO-8601 duration (such as P90D or PT12H)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := tt.parameters
+			if parameters == "" {
+				parameters = `{ "defaults": { "p": "P30D" }, "schema": { "p": { "type": "string" } } }`
+			}
+			var got []string
+			for _, e := range validateRulesetDocJSON(t, parameterRulesetDoc(parameters, tt.assert)) {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
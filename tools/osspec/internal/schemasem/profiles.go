@@ -0,0 +1,338 @@
+package schemasem
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/normalize"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// validateProfileOverrides checks the rule overrides of each profile: every override names
+// an active rule of the referenced ruleset (at most once), excluded rules are not otherwise
+// tailored, and parameter overrides name parameters the rule declares and match its
+// parameters.schema. Inherited overrides are checked again against the ruleset version the
+// extending profile uses, if that differs from the version they were declared for.
+// Overrides of rulesets that do not resolve are reported by validateReferences.
+func validateProfileOverrides(b *Bundle) diag.List {
+	versions := indexRulesetVersions(b)
+	rulesOf := func(key, version string) (map[string]*types.Rule, int) {
+		idx, _ := versions.resolve(key, version)
+		if idx < 0 {
+			return nil, idx
+		}
+		rs := &b.Rulesets[idx].Doc.Ruleset
+		rules := map[string]*types.Rule{}
+		for j := range rs.Rules {
+			rules[rs.Rules[j].Key] = &rs.Rules[j]
+		}
+		return rules, idx
+	}
+
+	var errs diag.List
+	for _, p := range b.Profiles {
+		profile := &p.Doc.Profile
+		seenRulesets := map[string]bool{}
+		for i, ref := range profile.Rulesets {
+			refPtr := fmt.Sprintf("/profile/rulesets/%d", i)
+			if seenRulesets[ref.Key] {
+				errs = append(errs, diag.New(diag.CodeDuplicateKey, p.Path, refPtr+"/key", "profile %q: ruleset %q is listed more than once", profile.Key, ref.Key))
+			}
+			seenRulesets[ref.Key] = true
+
+			rules, idx := rulesOf(ref.Key, ref.Version)
+			if idx < 0 {
+				continue
+			}
+			seenRules := map[string]bool{}
+			for j, o := range ref.Overrides {
+				ptr := fmt.Sprintf("%s/overrides/%d", refPtr, j)
+				if seenRules[o.RuleKey] {
+					errs = append(errs, diag.New(diag.CodeDuplicateKey, p.Path, ptr+"/rule_key", "profile %q: ruleset %q: rule %q is overridden more than once", profile.Key, ref.Key, o.RuleKey))
+					continue
+				}
+				seenRules[o.RuleKey] = true
+				for _, pr := range checkOverride(ref.Key, rules, o) {
+					errs = append(errs, diag.New(diag.CodeProfileOverride, p.Path, ptr+pr.field, "profile %q: ruleset %q: override of rule %q: %s", profile.Key, ref.Key, o.RuleKey, pr.msg))
+				}
+			}
+		}
+	}
+
+	f := flattenProfiles(b)
+	for _, p := range b.Profiles {
+		key := p.Doc.Profile.Key
+		flat, ok := f.done[key]
+		if !ok {
+			continue
+		}
+		for _, ref := range flat.Rulesets {
+			rules, idx := rulesOf(ref.Key, ref.Version)
+			if idx < 0 {
+				continue
+			}
+			for _, o := range ref.Overrides {
+				origin := f.origins[key][overrideKey(ref.Key, o.RuleKey)]
+				if origin == "" || origin == key {
+					continue
+				}
+				// Already reported for the declaring profile if it uses the same version.
+				if _, declIdx := rulesOf(ref.Key, profileRulesetVersion(f.done[origin], ref.Key)); declIdx == idx {
+					continue
+				}
+				for _, pr := range checkOverride(ref.Key, rules, o) {
+					errs = append(errs, diag.New(diag.CodeProfileOverride, p.Path, "/profile/extends", "profile %q: ruleset %q: override of rule %q inherited from %q: %s", key, ref.Key, o.RuleKey, origin, pr.msg))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+type overrideProblem struct {
+	field string
+	msg   string
+}
+
+// checkOverride checks o against the rules of ruleset rulesetKey.
+func checkOverride(rulesetKey string, rules map[string]*types.Rule, o types.RuleOverride) []overrideProblem {
+	var out []overrideProblem
+	add := func(field, format string, args ...any) {
+		out = append(out, overrideProblem{field: field, msg: fmt.Sprintf(format, args...)})
+	}
+	rule := rules[o.RuleKey]
+	if rule == nil {
+		add("/rule_key", "rule not found in ruleset %q", rulesetKey)
+		return out
+	}
+	if !rule.Active() {
+		if rule.Lifecycle.ReplacedBy != "" {
+			add("/rule_key", "rule is inactive (replaced by %q)", rule.Lifecycle.ReplacedBy)
+		} else {
+			add("/rule_key", "rule is inactive")
+		}
+		return out
+	}
+	if o.Exclude && (o.Severity != "" || len(o.Parameters) > 0) {
+		add("/exclude", "an excluded rule cannot also override severity or parameters")
+	}
+	for _, k := range sortedKeys(o.Parameters) {
+		field := "/parameters/" + escapePointer(k)
+		if rule.Parameters == nil || rule.Parameters.Defaults == nil {
+			add(field, "parameter %q overrides a rule without parameters.defaults", k)
+			continue
+		}
+		if _, ok := rule.Parameters.Defaults[k]; !ok {
+			add(field, "parameter %q not found in rule parameters.defaults", k)
+			continue
+		}
+		s, ok := rule.Parameters.Schema[k]
+		if !ok {
+			s = types.ParameterSchema{Type: parameterType(rule.Parameters, k)}
+		}
+		if msg := checkParameterValue(s, o.Parameters[k]); msg != "" {
+			add(field, "parameter %q: %s", k, msg)
+		} else if msg := checkParameterOperand(rule.Check, k, o.Parameters[k]); msg != "" {
+			add(field, "parameter %q: %s", k, msg)
+		}
+	}
+	return out
+}
+
+// profileRulesetVersion returns the version constraint p includes ruleset key at.
+func profileRulesetVersion(p types.Profile, key string) string {
+	for _, ref := range p.Rulesets {
+		if ref.Key == key {
+			return ref.Version
+		}
+	}
+	return ""
+}
+
+// FlattenProfiles returns every profile, by key, with the rulesets and rule overrides of
+// the profiles it extends (transitively) merged in. Extends is kept, sorted by
+// normalization, so the order in which a profile lists the profiles it extends has no
+// effect. Rulesets are united by key: the profile's own ref takes precedence, and its own
+// override of a rule replaces any inherited override of that rule. Inherited refs and
+// overrides that conflict (see validateProfileExtends) resolve to the first extended profile
+// in key order. The bundle should have passed ValidateSemantic.
+func FlattenProfiles(b *Bundle) map[string]types.Profile {
+	return flattenProfiles(b).done
+}
+
+// validateProfileExtends reports profiles that extend themselves, directly or through other
+// profiles, and conflicts between the profiles one extends: a ruleset included at
+// different versions, or a rule overridden differently, that the profile itself does not
+// decide. Unknown profile keys are reported by validateReferences.
+func validateProfileExtends(b *Bundle) diag.List {
+	return flattenProfiles(b).errs
+}
+
+func flattenProfiles(b *Bundle) *profileFlattener {
+	f := &profileFlattener{
+		bundle:   b,
+		profiles: map[string]int{},
+		done:     map[string]types.Profile{},
+		origins:  map[string]map[string]string{},
+		visiting: map[string]bool{},
+	}
+	for i := range b.Profiles {
+		if _, ok := f.profiles[b.Profiles[i].Doc.Profile.Key]; !ok {
+			f.profiles[b.Profiles[i].Doc.Profile.Key] = i
+		}
+	}
+	for _, p := range b.Profiles {
+		f.flatten(p.Doc.Profile.Key)
+	}
+	return f
+}
+
+type profileFlattener struct {
+	bundle   *Bundle
+	profiles map[string]int // key -> index in bundle.Profiles (first wins)
+	done     map[string]types.Profile
+	origins  map[string]map[string]string // key -> overrideKey -> profile declaring the override
+	visiting map[string]bool
+	stack    []string
+	errs     diag.List
+}
+
+// inheritedRef is a ruleset ref merged from extended profiles, with the profile each part
+// came from for conflict messages.
+type inheritedRef struct {
+	ref          types.ProfileRulesetRef
+	from         string
+	overrides    map[string]types.RuleOverride
+	overrideFrom map[string]string
+}
+
+func (f *profileFlattener) flatten(key string) (types.Profile, bool) {
+	if p, ok := f.done[key]; ok {
+		return p, true
+	}
+	idx, ok := f.profiles[key]
+	if !ok || f.visiting[key] {
+		return types.Profile{}, false
+	}
+	p := f.bundle.Profiles[idx]
+	f.visiting[key] = true
+	f.stack = append(f.stack, key)
+	defer func() {
+		f.visiting[key] = false
+		f.stack = f.stack[:len(f.stack)-1]
+	}()
+
+	own := map[string]types.ProfileRulesetRef{}
+	for _, ref := range p.Doc.Profile.Rulesets {
+		if _, ok := own[ref.Key]; !ok {
+			own[ref.Key] = ref
+		}
+	}
+
+	inherited := map[string]*inheritedRef{}
+	for i, parentKey := range p.Doc.Profile.Extends {
+		if f.visiting[parentKey] {
+			cycle := append(slices.Clone(f.stack[slices.Index(f.stack, parentKey):]), parentKey)
+			f.errs = append(f.errs, diag.New(diag.CodeProfileExtends, p.Path, fmt.Sprintf("/profile/extends/%d", i), "profile %q: extends cycle: %s", key, strings.Join(cycle, " -> ")))
+			continue
+		}
+		parent, ok := f.flatten(parentKey)
+		if !ok {
+			continue
+		}
+		for _, ref := range parent.Rulesets {
+			f.inherit(p.Path, key, own[ref.Key], inherited, parentKey, ref)
+		}
+	}
+
+	flat := p.Doc.Profile
+	flat.Rulesets = make([]types.ProfileRulesetRef, 0, len(p.Doc.Profile.Rulesets))
+	for _, ref := range p.Doc.Profile.Rulesets {
+		if slices.ContainsFunc(flat.Rulesets, func(r types.ProfileRulesetRef) bool { return r.Key == ref.Key }) {
+			continue // duplicate ref, reported by validateProfileOverrides
+		}
+		if in := inherited[ref.Key]; in != nil {
+			if ref.Version == "" {
+				ref.Version = in.ref.Version
+			}
+			overrides := maps.Clone(in.overrides)
+			for _, o := range ref.Overrides {
+				overrides[o.RuleKey] = o
+			}
+			ref.Overrides = sortedOverrides(overrides)
+		}
+		flat.Rulesets = append(flat.Rulesets, ref)
+	}
+	for _, k := range slices.Sorted(maps.Keys(inherited)) {
+		if _, ok := own[k]; ok {
+			continue
+		}
+		ref := inherited[k].ref
+		ref.Overrides = sortedOverrides(inherited[k].overrides)
+		flat.Rulesets = append(flat.Rulesets, ref)
+	}
+	doc := types.ProfileDoc{Profile: flat}
+	normalize.ProfileDoc(&doc)
+	f.done[key] = doc.Profile
+
+	origins := map[string]string{}
+	for _, ref := range doc.Profile.Rulesets {
+		ownRef := own[ref.Key]
+		for _, o := range ref.Overrides {
+			k := overrideKey(ref.Key, o.RuleKey)
+			if slices.ContainsFunc(ownRef.Overrides, func(x types.RuleOverride) bool { return x.RuleKey == o.RuleKey }) {
+				origins[k] = key
+			} else if in := inherited[ref.Key]; in != nil {
+				origins[k] = f.origins[in.overrideFrom[o.RuleKey]][k]
+			}
+		}
+	}
+	f.origins[key] = origins
+	return doc.Profile, true
+}
+
+func overrideKey(rulesetKey, ruleKey string) string {
+	return rulesetKey + "#" + ruleKey
+}
+
+// inherit merges ref, included by the extended profile parentKey, into inherited. own is the
+// extending profile's own ref to the same ruleset, if any; what it decides does not conflict.
+func (f *profileFlattener) inherit(path, key string, own types.ProfileRulesetRef, inherited map[string]*inheritedRef, parentKey string, ref types.ProfileRulesetRef) {
+	in := inherited[ref.Key]
+	if in == nil {
+		in = &inheritedRef{ref: ref, from: parentKey, overrides: map[string]types.RuleOverride{}, overrideFrom: map[string]string{}}
+		in.ref.Overrides = nil
+		inherited[ref.Key] = in
+	} else if in.ref.Version != ref.Version && own.Version == "" {
+		f.errs = append(f.errs, diag.New(diag.CodeProfileExtends, path, "/profile/extends", "profile %q: ruleset %q is included at version %q by %q and at version %q by %q; include it with an explicit version to decide", key, ref.Key, in.ref.Version, in.from, ref.Version, parentKey))
+	}
+	ownOverrides := map[string]bool{}
+	for _, o := range own.Overrides {
+		ownOverrides[o.RuleKey] = true
+	}
+	for _, o := range ref.Overrides {
+		prev, ok := in.overrides[o.RuleKey]
+		if !ok {
+			in.overrides[o.RuleKey] = o
+			in.overrideFrom[o.RuleKey] = parentKey
+			continue
+		}
+		if jsonString(prev) != jsonString(o) && !ownOverrides[o.RuleKey] {
+			f.errs = append(f.errs, diag.New(diag.CodeProfileExtends, path, "/profile/extends", "profile %q: ruleset %q: rule %q is overridden differently by %q and %q; override it to decide", key, ref.Key, o.RuleKey, in.overrideFrom[o.RuleKey], parentKey))
+		}
+	}
+}
+
+func sortedOverrides(m map[string]types.RuleOverride) []types.RuleOverride {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]types.RuleOverride, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		out = append(out, m[k])
+	}
+	return out
+}
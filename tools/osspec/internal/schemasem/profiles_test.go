@@ -0,0 +1,355 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/normalize"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestValidateSemantic_ProfileOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides string
+		want      []string
+	}{
+		{
+			name:      "valid",
+			overrides: `[{ "rule_key": "R1", "severity": "high", "parameters": { "p": 7 }, "rationale": "stricter" }]`,
+		},
+		{
+			name:      "exclude",
+			overrides: `[{ "rule_key": "R1", "exclude": true }]`,
+		},
+		{
+			name:      "unknown rule",
+			overrides: `[{ "rule_key": "R9", "severity": "high" }]`,
+			want:      []string{`/profile/rulesets/0/overrides/0/rule_key: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R9": rule not found in ruleset "example.parameters.v1"`},
+		},
+		{
+			name:      "duplicate rule",
+			overrides: `[{ "rule_key": "R1", "severity": "high" }, { "rule_key": "R1", "severity": "low" }]`,
+			want:      []string{`/profile/rulesets/0/overrides/1/rule_key: profile "example.profile.v1": ruleset "example.parameters.v1": rule "R1" is overridden more than once`},
+		},
+		{
+			name:      "exclude with tailoring",
+			overrides: `[{ "rule_key": "R1", "exclude": true, "severity": "high" }]`,
+			want:      []string{`/profile/rulesets/0/overrides/0/exclude: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": an excluded rule cannot also override severity or parameters`},
+		},
+		{
+			name:      "unknown parameter",
+			overrides: `[{ "rule_key": "R1", "parameters": { "q": 1 } }]`,
+			want:      []string{`/profile/rulesets/0/overrides/0/parameters/q: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": parameter "q" not found in rule parameters.defaults`},
+		},
+		{
+			name:      "parameter out of bounds",
+			overrides: `[{ "rule_key": "R1", "parameters": { "p": 11 } }]`,
+			want:      []string{`/profile/rulesets/0/overrides/0/parameters/p: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": parameter "p": value 11 is above maximum 10`},
+		},
+		{
+			name:      "parameter wrong type",
+			overrides: `[{ "rule_key": "R1", "parameters": { "p": "7" } }]`,
+			want:      []string{`/profile/rulesets/0/overrides/0/parameters/p: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": parameter "p": value "7" is a string, want integer`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range validateProfileJSON(t, tt.overrides) {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_ProfileOverrideOperands(t *testing.T) {
+	tests := []struct {
+		name     string
+		assert   string
+		defaults string
+		value    string
+		want     string
+	}{
+		{
+			name:     "invalid regex",
+			assert:   `{ "path": "/name", "op": "matches", "value_param": "p" }`,
+			defaults: `"^okta"`,
+			value:    `"(["`,
+			want:     `/profile/rulesets/0/overrides/0/parameters/p: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": parameter "p": op="matches": "([" is not a valid RE2 regular expression: error parsing regexp: missing closing ]: ` + "`[`",
+		},
+		{
+			name:     "valid regex",
+			assert:   `{ "path": "/name", "op": "matches", "value_param": "p" }`,
+			defaults: `"^okta"`,
+			value:    `"^splunk"`,
+		},
+		{
+			name:     "malformed duration",
+			assert:   `{ "path": "/name", "op": "older_than", "value_param": "p" }`,
+			defaults: `"P90D"`,
+			value:    `"90 days"`,
+			want:     `/profile/rulesets/0/overrides/0/parameters/p: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": parameter "p": op="older_than": "90 days" is not an ISO-8601 duration (such as P90D or PT12H)`,
+		},
+		{
+			name:     "out of range duration",
+			assert:   `{ "path": "/name", "op": "newer_than", "value_param": "p" }`,
+			defaults: `"P90D"`,
+			value:    `"P2000000000000000000W"`,
+			want:     `/profile/rulesets/0/overrides/0/parameters/p: profile "example.profile.v1": ruleset "example.parameters.v1": override of rule "R1": parameter "p": op="newer_than": "P2000000000000000000W" is out of range`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := parameterRulesetDoc(`{ "defaults": { "p": `+tt.defaults+` } }`, tt.assert)
+			var got []string
+			for _, e := range validateProfileRulesetJSON(t, rs, `[{ "rule_key": "R1", "parameters": { "p": `+tt.value+` } }]`) {
+				got = append(got, e.Pointer+": "+e.Message)
+			}
+			var want []string
+			if tt.want != "" {
+				want = []string{tt.want}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// validateProfileJSON validates a profile including the parameterRulesetDoc ruleset with the
+// given overrides, and returns the diagnostics reported for the profile.
+func validateProfileJSON(t *testing.T, overrides string) diag.List {
+	t.Helper()
+	return validateProfileRulesetJSON(t, parameterRulesetDoc(`{ "defaults": { "p": 5 }, "schema": { "p": { "type": "integer", "minimum": 1, "maximum": 10 } } }`, `{ "path": "/name", "op": "eq", "value_param": "p" }`), overrides)
+}
+
+// validateProfileRulesetJSON is validateProfileJSON for the given ruleset document.
+func validateProfileRulesetJSON(t *testing.T, ruleset, overrides string) diag.List {
+	t.Helper()
+
+	reg, err := LoadRegistry(filepath.Join(testutil.RepoRoot(t), "metaschema"))
+	if err != nil {
+		t.Fatalf("LoadRegistry error: %v", err)
+	}
+	rsJSON := []byte(ruleset)
+	profileJSON := []byte(strings.Replace(`{
+  "schema_version": 1,
+  "kind": "opensspm.profile",
+  "profile": {
+    "key": "example.profile.v1",
+    "name": "Example profile",
+    "rulesets": [
+      { "key": "example.parameters.v1", "overrides": OVERRIDES }
+    ]
+  }
+}`, "OVERRIDES", overrides, 1))
+	if err := reg.ValidateKindJSON("opensspm.profile", profileJSON); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+
+	var rs types.RulesetDoc
+	if err := json.Unmarshal(rsJSON, &rs); err != nil {
+		t.Fatalf("unmarshal ruleset: %v", err)
+	}
+	normalize.RulesetDoc(&rs)
+	var p types.ProfileDoc
+	if err := json.Unmarshal(profileJSON, &p); err != nil {
+		t.Fatalf("unmarshal profile: %v", err)
+	}
+	normalize.ProfileDoc(&p)
+
+	bundle := &Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{{Path: "ruleset.json", Doc: rs}},
+		Profiles: []struct {
+			Path string
+			Doc  types.ProfileDoc
+		}{{Path: "profile.json", Doc: p}},
+		Sources: map[string][]byte{"ruleset.json": rsJSON, "profile.json": profileJSON},
+	}
+	addReferencedDocs(bundle)
+	var out diag.List
+	for _, e := range ValidateSemantic(bundle) {
+		if e.File == "profile.json" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func extendsBundle(profiles ...types.Profile) *Bundle {
+	b := &Bundle{}
+	for _, p := range profiles {
+		doc := types.ProfileDoc{SchemaVersion: 1, Kind: "opensspm.profile", Profile: p}
+		normalize.ProfileDoc(&doc)
+		b.Profiles = append(b.Profiles, struct {
+			Path string
+			Doc  types.ProfileDoc
+		}{Path: p.Key + ".json", Doc: doc})
+	}
+	return b
+}
+
+func TestFlattenProfiles(t *testing.T) {
+	base := types.Profile{Key: "base", Rulesets: []types.ProfileRulesetRef{
+		{Key: "rs.a", Version: "v1.0.0", Overrides: []types.RuleOverride{
+			{RuleKey: "R1", Parameters: map[string]any{"max_age": 90.0}},
+			{RuleKey: "R2", Exclude: true},
+		}},
+	}}
+	extra := types.Profile{Key: "extra", Rulesets: []types.ProfileRulesetRef{{Key: "rs.b"}}}
+	strict := types.Profile{Key: "strict", Extends: []string{"extra", "base"}, Rulesets: []types.ProfileRulesetRef{
+		{Key: "rs.a", Overrides: []types.RuleOverride{{RuleKey: "R1", Parameters: map[string]any{"max_age": 30.0}}}},
+	}}
+	b := extendsBundle(base, extra, strict)
+	if errs := validateProfileExtends(b); len(errs) != 0 {
+		t.Fatalf("unexpected diagnostics:\n%s", joinErrs(errs))
+	}
+
+	want := types.Profile{Key: "strict", Extends: []string{"base", "extra"}, Rulesets: []types.ProfileRulesetRef{
+		{Key: "rs.a", Version: "v1.0.0", Overrides: []types.RuleOverride{
+			{RuleKey: "R1", Parameters: map[string]any{"max_age": 30.0}},
+			{RuleKey: "R2", Exclude: true},
+		}},
+		{Key: "rs.b"},
+	}}
+	if diff := cmp.Diff(want, FlattenProfiles(b)["strict"], cmpopts.EquateEmpty()); diff != "" {
+		t.Fatalf("flattened profile mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateSemantic_ProfileExtends(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []types.Profile
+		want     []string
+	}{
+		{
+			name: "self",
+			profiles: []types.Profile{
+				{Key: "a", Extends: []string{"a"}, Rulesets: []types.ProfileRulesetRef{}},
+			},
+			want: []string{`a.json#/profile/extends/0: profile "a": extends cycle: a -> a`},
+		},
+		{
+			name: "cycle",
+			profiles: []types.Profile{
+				{Key: "a", Extends: []string{"b"}, Rulesets: []types.ProfileRulesetRef{}},
+				{Key: "b", Extends: []string{"c"}, Rulesets: []types.ProfileRulesetRef{}},
+				{Key: "c", Extends: []string{"a"}, Rulesets: []types.ProfileRulesetRef{}},
+			},
+			want: []string{`c.json#/profile/extends/0: profile "c": extends cycle: a -> b -> c -> a`},
+		},
+		{
+			name: "version conflict",
+			profiles: []types.Profile{
+				{Key: "a", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: "v1.0.0"}}},
+				{Key: "b", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: "v2.0.0"}}},
+				{Key: "c", Extends: []string{"a", "b"}, Rulesets: []types.ProfileRulesetRef{}},
+			},
+			want: []string{`c.json#/profile/extends: profile "c": ruleset "rs" is included at version "v1.0.0" by "a" and at version "v2.0.0" by "b"; include it with an explicit version to decide`},
+		},
+		{
+			name: "version decided",
+			profiles: []types.Profile{
+				{Key: "a", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: "v1.0.0"}}},
+				{Key: "b", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: "v2.0.0"}}},
+				{Key: "c", Extends: []string{"a", "b"}, Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: "v2.0.0"}}},
+			},
+		},
+		{
+			name: "override conflict",
+			profiles: []types.Profile{
+				{Key: "a", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Overrides: []types.RuleOverride{{RuleKey: "R1", Severity: types.SeverityHigh}}}}},
+				{Key: "b", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Overrides: []types.RuleOverride{{RuleKey: "R1", Exclude: true}}}}},
+				{Key: "c", Extends: []string{"b", "a"}, Rulesets: []types.ProfileRulesetRef{}},
+			},
+			want: []string{`c.json#/profile/extends: profile "c": ruleset "rs": rule "R1" is overridden differently by "a" and "b"; override it to decide`},
+		},
+		{
+			name: "diamond",
+			profiles: []types.Profile{
+				{Key: "base", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: "v1.0.0", Overrides: []types.RuleOverride{{RuleKey: "R1", Exclude: true}}}}},
+				{Key: "left", Extends: []string{"base"}, Rulesets: []types.ProfileRulesetRef{}},
+				{Key: "right", Extends: []string{"base"}, Rulesets: []types.ProfileRulesetRef{}},
+				{Key: "top", Extends: []string{"left", "right"}, Rulesets: []types.ProfileRulesetRef{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range validateProfileExtends(extendsBundle(tt.profiles...)) {
+				got = append(got, e.File+"#"+e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_InheritedOverridesUseExtendingProfileVersion(t *testing.T) {
+	const assert = `{ "path": "/name", "op": "eq", "value_param": "p" }`
+	rulesetVersion := func(version string, maximum int, ruleKeys ...string) types.RulesetDoc {
+		var rs types.RulesetDoc
+		params := fmt.Sprintf(`{ "defaults": { "p": 5 }, "schema": { "p": { "type": "integer", "minimum": 1, "maximum": %d } } }`, maximum)
+		if err := json.Unmarshal([]byte(parameterRulesetDoc(params, assert)), &rs); err != nil {
+			t.Fatalf("unmarshal ruleset: %v", err)
+		}
+		rs.Ruleset.Version = version
+		r1 := rs.Ruleset.Rules[0]
+		rs.Ruleset.Rules = nil
+		for _, k := range ruleKeys {
+			r := r1
+			r.Key, r.Title = k, k
+			rs.Ruleset.Rules = append(rs.Ruleset.Rules, r)
+		}
+		normalize.RulesetDoc(&rs)
+		return rs
+	}
+
+	b := extendsBundle(
+		types.Profile{Key: "base", Rulesets: []types.ProfileRulesetRef{{Key: "example.parameters.v1", Version: "v1.0.0", Overrides: []types.RuleOverride{
+			{RuleKey: "R1", Parameters: map[string]any{"p": 7.0}},
+			{RuleKey: "R2", Severity: types.SeverityHigh},
+		}}}},
+		types.Profile{Key: "same", Extends: []string{"base"}, Rulesets: []types.ProfileRulesetRef{{Key: "example.parameters.v1", Version: "v1.0.0"}}},
+		types.Profile{Key: "child", Extends: []string{"base"}, Rulesets: []types.ProfileRulesetRef{{Key: "example.parameters.v1", Version: "v2.0.0"}}},
+	)
+	b.Rulesets = []struct {
+		Path string
+		Doc  types.RulesetDoc
+	}{
+		{Path: "v1.json", Doc: rulesetVersion("1.0.0", 10, "R1", "R2")},
+		{Path: "v2.json", Doc: rulesetVersion("2.0.0", 5, "R1")},
+	}
+	addReferencedDocs(b)
+
+	var got []string
+	for _, e := range ValidateSemantic(b) {
+		if e.Code == diag.CodeProfileOverride {
+			got = append(got, e.File+"#"+e.Pointer+": "+e.Message)
+		}
+	}
+	want := []string{
+		`child.json#/profile/extends: profile "child": ruleset "example.parameters.v1": override of rule "R1" inherited from "base": parameter "p": value 7 is above maximum 5`,
+		`child.json#/profile/extends: profile "child": ruleset "example.parameters.v1": override of rule "R2" inherited from "base": rule not found in ruleset "example.parameters.v1"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
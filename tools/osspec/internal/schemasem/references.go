@@ -0,0 +1,241 @@
+package schemasem
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// docRefs indexes documents by the keys other documents use to reference them.
+type docRefs struct {
+	// contracts maps "dataset@version" to the contract's path.
+	contracts map[string]string
+	// contractVersions maps a dataset key to its contract versions (for hints).
+	contractVersions map[string][]int
+	// connectors maps connector.kind to the manifest.
+	connectors map[string]*connectorRef
+	// rulesets indexes rulesets by key and version.
+	rulesets rulesetVersions
+	// incomplete is Bundle.Incomplete.
+	incomplete map[string]bool
+}
+
+type connectorRef struct {
+	path     string
+	provides map[string]struct{}
+}
+
+func indexDocRefs(b *Bundle) docRefs {
+	refs := docRefs{
+		contracts:        map[string]string{},
+		contractVersions: map[string][]int{},
+		connectors:       map[string]*connectorRef{},
+		rulesets:         indexRulesetVersions(b),
+		incomplete:       b.Incomplete,
+	}
+	for _, dc := range b.DatasetContracts {
+		k := fmt.Sprintf("%s@%d", dc.Doc.Dataset.Key, dc.Doc.Dataset.Version)
+		if _, ok := refs.contracts[k]; ok {
+			continue
+		}
+		refs.contracts[k] = dc.Path
+		refs.contractVersions[dc.Doc.Dataset.Key] = append(refs.contractVersions[dc.Doc.Dataset.Key], dc.Doc.Dataset.Version)
+	}
+	for k := range refs.contractVersions {
+		slices.Sort(refs.contractVersions[k])
+	}
+	for _, c := range b.Connectors {
+		if _, ok := refs.connectors[c.Doc.Connector.Kind]; ok {
+			continue
+		}
+		cr := &connectorRef{path: c.Path, provides: map[string]struct{}{}}
+		for _, p := range c.Doc.Connector.Provides {
+			cr.provides[fmt.Sprintf("%s@%d", p.Dataset, p.Version)] = struct{}{}
+		}
+		refs.connectors[c.Doc.Connector.Kind] = cr
+	}
+	return refs
+}
+
+// validateReferences resolves references between documents: ruleset data_contracts and
+// connector provides must name dataset contracts, connector_instance scopes and the
+// connector_kind qualifiers of global rulesets must name a connector manifest that provides
+// the datasets read from it, profile rulesets must name rulesets and profile extends must
+// name profiles.
+func validateReferences(b *Bundle) diag.List {
+	var errs diag.List
+	refs := indexDocRefs(b)
+
+	seenConnectorKinds := map[string]string{}
+	for _, c := range b.Connectors {
+		kind := c.Doc.Connector.Kind
+		if prev, ok := seenConnectorKinds[kind]; ok {
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, c.Path, "/connector/kind", "duplicate connector.kind %q (also in %s)", kind, prev).WithRelated(prev, "/connector/kind"))
+		} else {
+			seenConnectorKinds[kind] = c.Path
+		}
+		for i, p := range c.Doc.Connector.Provides {
+			if err := refs.resolveContract(c.Path, fmt.Sprintf("/connector/provides/%d", i), fmt.Sprintf("connector.provides[%d]", i), p.Dataset, p.Version); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, rs := range b.Rulesets {
+		ruleset := &rs.Doc.Ruleset
+		for i, dc := range ruleset.DataContracts {
+			if err := refs.resolveContract(rs.Path, fmt.Sprintf("/ruleset/data_contracts/%d", i), fmt.Sprintf("ruleset.data_contracts[%d]", i), dc.Dataset, dc.Version); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if ruleset.Scope.Kind == types.ScopeKindGlobal {
+			errs = append(errs, refs.validateConnectorQualifiers(rs.Path, ruleset)...)
+		}
+		if ruleset.Scope.Kind != types.ScopeKindConnectorInstance || strings.TrimSpace(ruleset.Scope.ConnectorKind) == "" {
+			continue
+		}
+		conn, ok := refs.connectors[ruleset.Scope.ConnectorKind]
+		if !ok {
+			if refs.incomplete["opensspm.connector_manifest"] {
+				continue
+			}
+			errs = append(errs, diag.New(diag.CodeUnresolvedReference, rs.Path, "/ruleset/scope/connector_kind", "scope.connector_kind %q does not match any connector manifest", ruleset.Scope.ConnectorKind))
+			continue
+		}
+		for _, ref := range datasetRefsReadByRuleset(ruleset) {
+			if _, ok := conn.provides[ref]; !ok {
+				errs = append(errs, diag.New(diag.CodeConnectorCoverage, rs.Path, "/ruleset/scope/connector_kind", "ruleset %q reads dataset %q, which connector manifest %s (kind %q) does not provide", ruleset.Key, ref, conn.path, ruleset.Scope.ConnectorKind).WithRelated(conn.path, "/connector/provides"))
+			}
+		}
+	}
+
+	seenProfileKeys := map[string]string{}
+	for _, p := range b.Profiles {
+		key := p.Doc.Profile.Key
+		if prev, ok := seenProfileKeys[key]; ok {
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, p.Path, "/profile/key", "duplicate profile.key %q (also in %s)", key, prev).WithRelated(prev, "/profile/key"))
+		} else {
+			seenProfileKeys[key] = p.Path
+		}
+		for i, ref := range p.Doc.Profile.Rulesets {
+			ptr := fmt.Sprintf("/profile/rulesets/%d", i)
+			idx, err := refs.rulesets.resolve(ref.Key, ref.Version)
+			switch {
+			case err != nil:
+				errs = append(errs, diag.New(diag.CodeUnresolvedReference, p.Path, ptr+"/version", "profile %q: rulesets[%d].version %q is not a valid version constraint: %v", key, i, ref.Version, err))
+			case idx >= 0 || refs.incomplete["opensspm.ruleset"]:
+			case len(refs.rulesets[ref.Key]) == 0:
+				errs = append(errs, diag.New(diag.CodeUnresolvedReference, p.Path, ptr+"/key", "profile %q: rulesets[%d].key %q does not match any ruleset", key, i, ref.Key))
+			default:
+				errs = append(errs, diag.New(diag.CodeUnresolvedReference, p.Path, ptr+"/version", "profile %q: rulesets[%d] %q: no version matches %q; available versions: %s", key, i, ref.Key, ref.Version, refs.rulesets.available(ref.Key)))
+			}
+		}
+	}
+	for _, p := range b.Profiles {
+		for i, parent := range p.Doc.Profile.Extends {
+			if _, ok := seenProfileKeys[parent]; !ok && !refs.incomplete["opensspm.profile"] {
+				errs = append(errs, diag.New(diag.CodeUnresolvedReference, p.Path, fmt.Sprintf("/profile/extends/%d", i), "profile %q: extends[%d] %q does not match any profile", p.Doc.Profile.Key, i, parent))
+			}
+		}
+	}
+
+	return errs
+}
+
+// connectorQualifier is a connector_kind that qualifies a dataset read by a check.
+type connectorQualifier struct {
+	field   string // pointer relative to the check
+	name    string
+	dataset string
+	kind    string
+}
+
+func connectorQualifiers(c *types.Check) []connectorQualifier {
+	var out []connectorQualifier
+	if c.ConnectorKind != "" {
+		out = append(out, connectorQualifier{field: "/connector_kind", name: "check.connector_kind", dataset: c.Dataset, kind: c.ConnectorKind})
+	}
+	if c.Left != nil && c.Left.ConnectorKind != "" {
+		out = append(out, connectorQualifier{field: "/left/connector_kind", name: "check.left.connector_kind", dataset: c.Left.Dataset, kind: c.Left.ConnectorKind})
+	}
+	if c.Right != nil && c.Right.ConnectorKind != "" {
+		out = append(out, connectorQualifier{field: "/right/connector_kind", name: "check.right.connector_kind", dataset: c.Right.Dataset, kind: c.Right.ConnectorKind})
+	}
+	return out
+}
+
+// validateConnectorQualifiers checks that every connector_kind in the checks of a global
+// ruleset names a connector manifest that provides the qualified dataset (at its effective
+// version).
+func (refs docRefs) validateConnectorQualifiers(path string, ruleset *types.Ruleset) diag.List {
+	var errs diag.List
+	for i := range ruleset.Rules {
+		r := &ruleset.Rules[i]
+		if r.Check == nil {
+			continue
+		}
+		for _, q := range connectorQualifiers(r.Check) {
+			ptr := fmt.Sprintf("/ruleset/rules/%d/check%s", i, q.field)
+			conn, ok := refs.connectors[q.kind]
+			if !ok {
+				if !refs.incomplete["opensspm.connector_manifest"] {
+					errs = append(errs, ruleErrorf(diag.CodeUnresolvedReference, path, ptr, r, "%s %q does not match any connector manifest", q.name, q.kind))
+				}
+				continue
+			}
+			if strings.TrimSpace(q.dataset) == "" {
+				continue
+			}
+			ref := fmt.Sprintf("%s@%d", q.dataset, types.EffectiveDatasetVersion(q.dataset, ruleset.DataContracts, r.Check.DatasetVersion))
+			if _, ok := conn.provides[ref]; !ok {
+				errs = append(errs, ruleErrorf(diag.CodeConnectorCoverage, path, ptr, r, "%s %q: dataset %q is not provided by connector manifest %s", q.name, q.kind, ref, conn.path).WithRelated(conn.path, "/connector/provides"))
+			}
+		}
+	}
+	return errs
+}
+
+func (refs docRefs) resolveContract(path, ptr, field, dataset string, version int) *diag.Diagnostic {
+	ref := fmt.Sprintf("%s@%d", dataset, version)
+	if _, ok := refs.contracts[ref]; ok || refs.incomplete["opensspm.dataset_contract"] {
+		return nil
+	}
+	versions := refs.contractVersions[dataset]
+	if len(versions) == 0 {
+		return diag.New(diag.CodeUnresolvedReference, path, ptr, "%s %q does not match any dataset contract", field, ref)
+	}
+	d := diag.New(diag.CodeUnresolvedReference, path, ptr, "")
+	var available []string
+	for _, v := range versions {
+		p := refs.contracts[fmt.Sprintf("%s@%d", dataset, v)]
+		available = append(available, fmt.Sprintf("v%d (%s)", v, p))
+		d.WithRelated(p, "/dataset/version")
+	}
+	d.Message = fmt.Sprintf("%s %q does not match any dataset contract; available versions: %s", field, ref, strings.Join(available, ", "))
+	return d
+}
+
+// datasetRefsReadByRuleset returns the sorted "dataset@version" references read by the
+// ruleset's checks, using effective dataset versions.
+func datasetRefsReadByRuleset(rs *types.Ruleset) []string {
+	set := map[string]struct{}{}
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if r.Check == nil {
+			continue
+		}
+		for _, d := range datasetsReferencedByCheck(r.Check) {
+			set[fmt.Sprintf("%s@%d", d, types.EffectiveDatasetVersion(d, rs.DataContracts, r.Check.DatasetVersion))] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
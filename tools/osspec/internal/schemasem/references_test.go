@@ -0,0 +1,145 @@
+package schemasem
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+func TestValidateSemantic_CrossDocumentReferences(t *testing.T) {
+	rs := minimalRulesetDoc("okta.v1", types.Scope{Kind: types.ScopeKindConnectorInstance, ConnectorKind: "okta"})
+	rs.Ruleset.DataContracts = []types.DatasetContractRef{{Dataset: "okta:users", Version: 2}, {Dataset: "okta:groups", Version: 1}}
+	rs.Ruleset.Rules = append(rs.Ruleset.Rules, types.Rule{
+		Key:          "R2",
+		Title:        "R2",
+		Severity:     types.SeverityLow,
+		Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+		RequiredData: []string{"okta:groups"},
+		Check: &types.Check{
+			Type:    types.CheckTypeDatasetCountCompare,
+			Dataset: "okta:groups",
+			Compare: &types.Compare{Op: types.CompareOpEq, Value: new(float64)},
+		},
+	})
+	github := minimalRulesetDoc("github.v1", types.Scope{Kind: types.ScopeKindConnectorInstance, ConnectorKind: "github"})
+
+	b := &Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{
+			{Path: "specs/rulesets/okta.json", Doc: rs},
+			{Path: "specs/rulesets/github.json", Doc: github},
+		},
+		DatasetContracts: []struct {
+			Path string
+			Doc  types.DatasetContractDoc
+		}{
+			{Path: "specs/datasets/okta/users/v1.json", Doc: types.DatasetContractDoc{Dataset: types.DatasetContract{Key: "okta:users", Version: 1, Schema: json.RawMessage(`{}`)}}},
+			{Path: "specs/datasets/okta/groups/v1.json", Doc: types.DatasetContractDoc{Dataset: types.DatasetContract{Key: "okta:groups", Version: 1, Schema: json.RawMessage(`{}`)}}},
+		},
+		Connectors: []struct {
+			Path string
+			Doc  types.ConnectorManifestDoc
+		}{
+			{Path: "specs/connectors/okta.json", Doc: types.ConnectorManifestDoc{Connector: types.ConnectorManifest{
+				Kind:     "okta",
+				Provides: []types.DatasetRefSpec{{Dataset: "okta:users", Version: 1}, {Dataset: "okta:apps", Version: 1}},
+			}}},
+		},
+		Profiles: []struct {
+			Path string
+			Doc  types.ProfileDoc
+		}{
+			{Path: "specs/profiles/p.json", Doc: types.ProfileDoc{Profile: types.Profile{Key: "p", Rulesets: []types.ProfileRulesetRef{{Key: "okta.v1"}, {Key: "missing.v1"}}, Extends: []string{"missing.profile"}}}},
+		},
+	}
+
+	errs := ValidateSemantic(b)
+	for _, want := range []string{
+		`specs/rulesets/okta.json: ruleset.data_contracts[0] "okta:users@2" does not match any dataset contract; available versions: v1 (specs/datasets/okta/users/v1.json)`,
+		`specs/connectors/okta.json: connector.provides[1] "okta:apps@1" does not match any dataset contract`,
+		`specs/rulesets/okta.json: ruleset "okta.v1" reads dataset "okta:groups@1", which connector manifest specs/connectors/okta.json (kind "okta") does not provide`,
+		`specs/rulesets/github.json: scope.connector_kind "github" does not match any connector manifest`,
+		`specs/profiles/p.json: profile "p": rulesets[1].key "missing.v1" does not match any ruleset`,
+		`specs/profiles/p.json: profile "p": extends[0] "missing.profile" does not match any profile`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+	if containsErr(errs, "rulesets[0].key") {
+		t.Fatalf("unexpected error for resolvable profile ruleset, got:\n%s", joinErrs(errs))
+	}
+}
+
+func TestValidateSemantic_ConnectorQualifiedDatasets(t *testing.T) {
+	rs := minimalRulesetDoc("cross.v1", types.Scope{Kind: types.ScopeKindGlobal})
+	rs.Ruleset.Rules = append(rs.Ruleset.Rules,
+		types.Rule{
+			Key:          "R2",
+			Title:        "R2",
+			Severity:     types.SeverityLow,
+			Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+			RequiredData: []string{"core:users"},
+			Check: &types.Check{
+				Type:    types.CheckTypeDatasetJoinCountCompare,
+				Left:    &types.JoinSide{Dataset: "core:users", ConnectorKind: "okta", KeyPath: "/email"},
+				Right:   &types.JoinSide{Dataset: "core:users", ConnectorKind: "slack", KeyPath: "/email"},
+				Compare: &types.Compare{Op: types.CompareOpEq, Value: new(float64)},
+			},
+		},
+		types.Rule{
+			Key:          "R3",
+			Title:        "R3",
+			Severity:     types.SeverityLow,
+			Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+			RequiredData: []string{"okta:groups"},
+			Check: &types.Check{
+				Type:          types.CheckTypeDatasetCountCompare,
+				Dataset:       "okta:groups",
+				ConnectorKind: "okta",
+				Compare:       &types.Compare{Op: types.CompareOpEq, Value: new(float64)},
+			},
+		},
+	)
+
+	b := &Bundle{
+		Rulesets: []struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{
+			{Path: "specs/rulesets/cross.json", Doc: rs},
+		},
+		DatasetContracts: []struct {
+			Path string
+			Doc  types.DatasetContractDoc
+		}{
+			{Path: "specs/datasets/core/users/v1.json", Doc: types.DatasetContractDoc{Dataset: types.DatasetContract{Key: "core:users", Version: 1, Schema: json.RawMessage(`{}`)}}},
+			{Path: "specs/datasets/okta/groups/v1.json", Doc: types.DatasetContractDoc{Dataset: types.DatasetContract{Key: "okta:groups", Version: 1, Schema: json.RawMessage(`{}`)}}},
+		},
+		Connectors: []struct {
+			Path string
+			Doc  types.ConnectorManifestDoc
+		}{
+			{Path: "specs/connectors/okta.json", Doc: types.ConnectorManifestDoc{Connector: types.ConnectorManifest{
+				Kind:     "okta",
+				Provides: []types.DatasetRefSpec{{Dataset: "core:users", Version: 1}},
+			}}},
+		},
+	}
+
+	errs := ValidateSemantic(b)
+	for _, want := range []string{
+		`specs/rulesets/cross.json: rule "R2": check.right.connector_kind "slack" does not match any connector manifest`,
+		`specs/rulesets/cross.json: rule "R3": check.connector_kind "okta": dataset "okta:groups@1" is not provided by connector manifest specs/connectors/okta.json`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+	if containsErr(errs, "check.left.connector_kind") {
+		t.Fatalf("unexpected error for a dataset the connector provides, got:\n%s", joinErrs(errs))
+	}
+}
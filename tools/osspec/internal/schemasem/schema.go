@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -26,18 +28,25 @@ var KnownSchemas = []KindSchema{
 	{Kind: "opensspm.connector_manifest", Filename: "opensspm.connector_manifest.schema.json"},
 	{Kind: "opensspm.profile", Filename: "opensspm.profile.schema.json"},
 	{Kind: "opensspm.dictionary", Filename: "opensspm.dictionary.schema.json"},
+	// Conformance suites reference ruleset definitions, so they are registered after rulesets.
+	{Kind: "opensspm.conformance_suite", Filename: "opensspm.conformance_suite.schema.json"},
+	{Kind: "opensspm.evaluation_result", Filename: "opensspm.evaluation_result.schema.json"},
 }
 
 func LoadRegistry(metaschemaDir string) (*Registry, error) {
 	if metaschemaDir == "" {
 		return nil, errors.New("schemasem: metaschemaDir is required")
 	}
+	return LoadRegistryFS(os.DirFS(metaschemaDir), ".")
+}
+
+// LoadRegistryFS is like LoadRegistry but reads the metaschema files from dir in fsys.
+func LoadRegistryFS(fsys fs.FS, dir string) (*Registry, error) {
 	c := jsonschema.NewCompiler()
 
 	schemas := make(map[string]*jsonschema.Schema, len(KnownSchemas))
 	for _, ks := range KnownSchemas {
-		path := filepath.Join(metaschemaDir, ks.Filename)
-		b, err := os.ReadFile(path)
+		b, err := fs.ReadFile(fsys, path.Join(dir, ks.Filename))
 		if err != nil {
 			return nil, fmt.Errorf("schemasem: read schema %s: %w", ks.Filename, err)
 		}
@@ -53,20 +62,54 @@ func LoadRegistry(metaschemaDir string) (*Registry, error) {
 	return &Registry{schemas: schemas}, nil
 }
 
+// ValidateKindJSON validates jsonBytes against the schema registered for kind. Schema
+// violations are returned as a diag.List with one diagnostic per failing leaf keyword,
+// positioned in jsonBytes; callers set the file with diag.List.InFile.
 func (r *Registry) ValidateKindJSON(kind string, jsonBytes []byte) error {
 	s, ok := r.schemas[kind]
 	if !ok {
-		return fmt.Errorf("schemasem: no schema registered for kind %q", kind)
+		d := diag.New(diag.CodeUnknownKind, "", "/kind", "no schema registered for kind %q", kind)
+		d.Locate(jsonBytes)
+		return diag.List{d}
 	}
 	var v any
 	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
 	dec.UseNumber()
 	if err := dec.Decode(&v); err != nil {
-		return fmt.Errorf("schemasem: decode json: %w", err)
+		d := diag.New(diag.CodeInvalidJSON, "", "", "decode json: %v", err)
+		var se *json.SyntaxError
+		if errors.As(err, &se) {
+			d.Line, d.Column = diag.OffsetPosition(jsonBytes, int(se.Offset))
+		}
+		return diag.List{d}
+	}
+	err := s.Validate(v)
+	if err == nil {
+		return nil
 	}
-	if err := s.Validate(v); err != nil {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
 		return fmt.Errorf("schemasem: schema validation failed: %w", err)
 	}
-	return nil
+	var out diag.List
+	seen := map[string]struct{}{}
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, c := range e.Causes {
+				walk(c)
+			}
+			return
+		}
+		k := e.InstanceLocation + "\x00" + e.Message
+		if _, dup := seen[k]; dup {
+			return
+		}
+		seen[k] = struct{}{}
+		d := diag.New(diag.CodeSchema, "", e.InstanceLocation, "%s", e.Message)
+		d.Locate(jsonBytes)
+		out = append(out, d)
+	}
+	walk(ve)
+	return out
 }
-
@@ -0,0 +1,63 @@
+package schemasem
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	runtimev1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/runtime/v1"
+	specv1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/spec/v1"
+	"github.com/open-sspm/open-sspm-spec/pkg/evaluator"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
+)
+
+type emptyProvider struct{}
+
+func (emptyProvider) Capabilities(context.Context) []runtimev1.DatasetRef { return nil }
+
+func (emptyProvider) GetDataset(context.Context, runtimev1.EvalContext, runtimev1.DatasetRef) runtimev1.DatasetResult {
+	return runtimev1.DatasetResult{Error: &runtimev1.DatasetError{Kind: runtimev1.DatasetErrorKind_PERMISSION_DENIED, Message: "denied"}}
+}
+
+func TestValidateKindJSON_EvaluationResultFromReferenceEvaluator(t *testing.T) {
+	root := testutil.RepoRoot(t)
+	reg, err := LoadRegistry(filepath.Join(root, "metaschema"))
+	if err != nil {
+		t.Fatalf("LoadRegistry error: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(root, "dist", "descriptor.v1.json"))
+	if err != nil {
+		t.Fatalf("read descriptor: %v", err)
+	}
+	desc, err := specv1.ParseDescriptorV1(b)
+	if err != nil {
+		t.Fatalf("parse descriptor: %v", err)
+	}
+	if len(desc.Rulesets) == 0 {
+		t.Fatalf("descriptor has no rulesets")
+	}
+
+	eval := runtimev1.EvalContext{ScopeKind: runtimev1.ScopeKind_CONNECTOR_INSTANCE, ConnectorKind: "okta", ConnectorInstance: "acme"}
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	res := evaluator.Evaluate(context.Background(), desc.Rulesets[0], eval, emptyProvider{}, evaluator.Options{})
+	out, err := json.Marshal(res.Document(start, start.Add(time.Second)))
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	if err := reg.ValidateKindJSON("opensspm.evaluation_result", out); err != nil {
+		t.Fatalf("evaluation result does not match metaschema: %v\n%s", err, out)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	doc["result"].(map[string]any)["rules"] = []any{map[string]any{"rule_key": "R1", "status": "passed"}}
+	bad, _ := json.Marshal(doc)
+	if err := reg.ValidateKindJSON("opensspm.evaluation_result", bad); err == nil {
+		t.Fatalf("expected unknown status to be rejected")
+	}
+}
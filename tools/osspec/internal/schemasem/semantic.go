@@ -1,10 +1,16 @@
 package schemasem
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/open-sspm/open-sspm-spec/pkg/duration"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
 )
 
@@ -30,22 +36,49 @@ type Bundle struct {
 		Path string
 		Doc  types.ProfileDoc
 	}
+	Conformance []struct {
+		Path string
+		Doc  types.ConformanceSuiteDoc
+	}
+
+	// Sources maps a document path to its source bytes. When set, diagnostics are mapped
+	// back to source pointers and get line/column positions.
+	Sources map[string][]byte
+
+	// Incomplete marks document kinds (e.g. "opensspm.dataset_contract") of which at least
+	// one document failed to load. References to documents of these kinds are not reported
+	// as unresolved, since the target may be the document that failed.
+	Incomplete map[string]bool
+
+	// Baseline is a previously published descriptor. When set, dataset contracts that it
+	// also contains (same key and version) must be backward compatible with it.
+	Baseline *types.DescriptorV1
 }
 
-func ValidateSemantic(b *Bundle) []error {
+func ValidateSemantic(b *Bundle) diag.List {
 	if b == nil {
-		return []error{fmt.Errorf("semantic: nil bundle")}
+		return diag.List{diag.New(diag.CodeInternal, "", "", "semantic: nil bundle")}
 	}
 
-	var errs []error
+	var errs diag.List
 
+	// Several versions of a ruleset may coexist, but only if each declares a distinct one.
 	seenRulesetKeys := map[string]string{}
+	seenRulesetIDs := map[string]string{}
 	for _, rs := range b.Rulesets {
 		key := rs.Doc.Ruleset.Key
-		if prev, ok := seenRulesetKeys[key]; ok {
-			errs = append(errs, fmt.Errorf("semantic: duplicate ruleset.key %q in %s and %s", key, prev, rs.Path))
-		} else {
+		id := rs.Doc.Ruleset.ID()
+		prev, seen := seenRulesetKeys[key]
+		switch {
+		case !seen:
 			seenRulesetKeys[key] = rs.Path
+			seenRulesetIDs[id] = rs.Path
+		case rs.Doc.Ruleset.Version == "" || seenRulesetIDs[key] != "":
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, rs.Path, "/ruleset/key", "duplicate ruleset.key %q (also in %s); rulesets sharing a key must each declare a distinct ruleset.version", key, prev).WithRelated(prev, "/ruleset/key"))
+		case seenRulesetIDs[id] != "":
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, rs.Path, "/ruleset/version", "duplicate ruleset %q version %q (also in %s)", key, rs.Doc.Ruleset.Version, seenRulesetIDs[id]).WithRelated(seenRulesetIDs[id], "/ruleset/version"))
+		default:
+			seenRulesetIDs[id] = rs.Path
 		}
 
 		errs = append(errs, validateScope(rs.Path, rs.Doc.Ruleset.Scope)...)
@@ -56,28 +89,37 @@ func ValidateSemantic(b *Bundle) []error {
 	for _, dc := range b.DatasetContracts {
 		k := fmt.Sprintf("%s@%d", dc.Doc.Dataset.Key, dc.Doc.Dataset.Version)
 		if prev, ok := seenDataset[k]; ok {
-			errs = append(errs, fmt.Errorf("semantic: duplicate dataset (key,version) %q in %s and %s", k, prev, dc.Path))
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, dc.Path, "/dataset/key", "duplicate dataset (key,version) %q (also in %s)", k, prev).WithRelated(prev, "/dataset/key"))
 		} else {
 			seenDataset[k] = dc.Path
 		}
 	}
 
+	errs = append(errs, validateReferences(b)...)
+	errs = append(errs, validateLifecycle(b)...)
+	errs = append(errs, validateProfileOverrides(b)...)
+	errs = append(errs, validateProfileExtends(b)...)
+	errs = append(errs, validatePaths(b)...)
+	errs = append(errs, validateConformance(b)...)
+	errs = append(errs, validateContractCompat(b)...)
+
+	locate(b, errs)
 	return errs
 }
 
-func validateScope(path string, s types.Scope) []error {
-	var errs []error
+func validateScope(path string, s types.Scope) diag.List {
+	var errs diag.List
 	switch s.Kind {
 	case types.ScopeKindConnectorInstance:
 		if strings.TrimSpace(s.ConnectorKind) == "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: scope.kind=connector_instance requires scope.connector_kind", path))
+			errs = append(errs, diag.New(diag.CodeScope, path, "/ruleset/scope", "scope.kind=connector_instance requires scope.connector_kind"))
 		}
 	case types.ScopeKindGlobal:
 		if strings.TrimSpace(s.ConnectorKind) != "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: scope.kind=global forbids scope.connector_kind", path))
+			errs = append(errs, diag.New(diag.CodeScope, path, "/ruleset/scope/connector_kind", "scope.kind=global forbids scope.connector_kind"))
 		}
 	default:
-		errs = append(errs, fmt.Errorf("semantic: %s: unknown scope.kind %q", path, s.Kind))
+		errs = append(errs, diag.New(diag.CodeScope, path, "/ruleset/scope/kind", "unknown scope.kind %q", s.Kind))
 	}
 	return errs
 }
@@ -107,77 +149,76 @@ func indexDatasetContracts(contracts []types.DatasetContractRef) datasetContract
 	return idx
 }
 
-func validateRulesetRules(path string, doc *types.RulesetDoc) []error {
-	var errs []error
+func validateRulesetRules(path string, doc *types.RulesetDoc) diag.List {
+	var errs diag.List
 
 	contractsIdx := indexDatasetContracts(doc.Ruleset.DataContracts)
 
 	seenRuleKeys := map[string]struct{}{}
 	for i := range doc.Ruleset.Rules {
 		r := &doc.Ruleset.Rules[i]
+		ptr := fmt.Sprintf("/ruleset/rules/%d", i)
 
 		if _, ok := seenRuleKeys[r.Key]; ok {
-			errs = append(errs, fmt.Errorf("semantic: %s: duplicate rule.key %q", path, r.Key))
+			errs = append(errs, diag.New(diag.CodeDuplicateKey, path, ptr+"/key", "duplicate rule.key %q", r.Key))
 		} else {
 			seenRuleKeys[r.Key] = struct{}{}
 		}
 
-		errs = append(errs, validateRule(path, &doc.Ruleset, r, contractsIdx)...)
+		errs = append(errs, validateRule(path, ptr, &doc.Ruleset, r, contractsIdx)...)
 	}
 
 	return errs
 }
 
-func validateRule(path string, rs *types.Ruleset, r *types.Rule, contractsIdx datasetContractIndex) []error {
-	var errs []error
+// ruleErrorf reports a diagnostic for rule r; ptr points into the rule's document.
+func ruleErrorf(code, path, ptr string, r *types.Rule, format string, args ...any) *diag.Diagnostic {
+	return diag.New(code, path, ptr, "rule %q: %s", r.Key, fmt.Sprintf(format, args...))
+}
 
-	// 3.3 Parameters schema keys must exist in defaults.
-	if r.Parameters != nil && r.Parameters.Schema != nil {
-		for k := range r.Parameters.Schema {
-			if r.Parameters.Defaults == nil {
-				errs = append(errs, fmt.Errorf("semantic: %s: rule %q: parameters.schema=%q but parameters.defaults is missing", path, r.Key, k))
-				continue
-			}
-			if _, ok := r.Parameters.Defaults[k]; !ok {
-				errs = append(errs, fmt.Errorf("semantic: %s: rule %q: parameters.schema=%q not found in parameters.defaults", path, r.Key, k))
-			}
-		}
-	}
+func validateRule(path, ptr string, rs *types.Ruleset, r *types.Rule, contractsIdx datasetContractIndex) diag.List {
+	var errs diag.List
+
+	// 3.3 Parameters defaults must match parameters schema.
+	errs = append(errs, validateParameters(path, ptr, r)...)
 
 	// 6.3 Monitoring/check constraints
 	switch r.Monitoring.Status {
 	case types.MonitoringStatusAutomated, types.MonitoringStatusPartial:
 		if r.Check == nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: monitoring.status=%q requires rule.check", path, r.Key, r.Monitoring.Status))
+			errs = append(errs, ruleErrorf(diag.CodeMonitoringCheck, path, ptr+"/monitoring/status", r, "monitoring.status=%q requires rule.check", r.Monitoring.Status))
 			// If check is missing, further check validation is not meaningful.
 			return errs
 		}
 	case types.MonitoringStatusManual, types.MonitoringStatusUnsupported:
 		if r.Check != nil && r.Check.Type != types.CheckTypeManualAttestation {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: monitoring.status=%q only allows check.type=manual.attestation or check omission", path, r.Key, r.Monitoring.Status))
+			errs = append(errs, ruleErrorf(diag.CodeMonitoringCheck, path, ptr+"/check/type", r, "monitoring.status=%q only allows check.type=manual.attestation or check omission", r.Monitoring.Status))
 		}
 	default:
 		// Schema should catch unknowns, but keep semantic validation explicit.
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: unknown monitoring.status %q", path, r.Key, r.Monitoring.Status))
+		errs = append(errs, ruleErrorf(diag.CodeMonitoringCheck, path, ptr+"/monitoring/status", r, "unknown monitoring.status %q", r.Monitoring.Status))
 	}
 
 	if r.Check == nil {
 		return errs
 	}
 
-	errs = append(errs, validateCheck(path, rs, r, r.Check, contractsIdx)...)
+	errs = append(errs, validateCheck(path, ptr+"/check", rs, r, r.Check, contractsIdx)...)
 	return errs
 }
 
-func validateCheck(path string, rs *types.Ruleset, r *types.Rule, c *types.Check, contractsIdx datasetContractIndex) []error {
-	var errs []error
+func validateCheck(path, ptr string, rs *types.Ruleset, r *types.Rule, c *types.Check, contractsIdx datasetContractIndex) diag.List {
+	var errs diag.List
+	errorf := func(code, field, format string, args ...any) {
+		errs = append(errs, ruleErrorf(code, path, ptr+field, r, format, args...))
+	}
 
 	// 6.4 Supported check types only (whitelist)
 	switch c.Type {
-	case types.CheckTypeDatasetFieldCompare, types.CheckTypeDatasetCountCompare, types.CheckTypeDatasetJoinCountCompare, types.CheckTypeManualAttestation:
+	case types.CheckTypeDatasetFieldCompare, types.CheckTypeDatasetCountCompare, types.CheckTypeDatasetJoinCountCompare, types.CheckTypeDatasetJoinFieldCompare, types.CheckTypeDatasetAggregateCompare, types.CheckTypeManualAttestation:
 		// ok
 	default:
-		return []error{fmt.Errorf("semantic: %s: rule %q: unknown check.type %q", path, r.Key, c.Type)}
+		return diag.List{ruleErrorf(diag.CodeCheckType, path, ptr+"/type", r, "unknown check.type %q", c.Type)}
 	}
 
 	// Type-specific required fields.
@@ -186,59 +227,120 @@ func validateCheck(path string, rs *types.Ruleset, r *types.Rule, c *types.Check
 		// No additional required fields.
 	case types.CheckTypeDatasetFieldCompare:
 		if strings.TrimSpace(c.Dataset) == "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.field_compare requires check.dataset", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.field_compare requires check.dataset")
 		}
 		if c.Assert == nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.field_compare requires check.assert", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.field_compare requires check.assert")
 		}
 		if c.Compare != nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.field_compare forbids check.compare", path, r.Key))
+			errorf(diag.CodeCheckFields, "/compare", "dataset.field_compare forbids check.compare")
 		}
 		if c.Left != nil || c.Right != nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.field_compare forbids check.left/check.right", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.field_compare forbids check.left/check.right")
 		}
 	case types.CheckTypeDatasetCountCompare:
 		if strings.TrimSpace(c.Dataset) == "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.count_compare requires check.dataset", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.count_compare requires check.dataset")
 		}
 		if c.Compare == nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.count_compare requires check.compare", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.count_compare requires check.compare")
 		}
 		if c.Assert != nil || c.Expect != nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.count_compare forbids check.assert/check.expect", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.count_compare forbids check.assert/check.expect")
 		}
 		if c.Left != nil || c.Right != nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.count_compare forbids check.left/check.right", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.count_compare forbids check.left/check.right")
 		}
 	case types.CheckTypeDatasetJoinCountCompare:
 		if c.Left == nil || strings.TrimSpace(c.Left.Dataset) == "" || strings.TrimSpace(c.Left.KeyPath) == "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.join_count_compare requires check.left.dataset and check.left.key_path", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.join_count_compare requires check.left.dataset and check.left.key_path")
 		}
 		if c.Right == nil || strings.TrimSpace(c.Right.Dataset) == "" || strings.TrimSpace(c.Right.KeyPath) == "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.join_count_compare requires check.right.dataset and check.right.key_path", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.join_count_compare requires check.right.dataset and check.right.key_path")
 		}
 		if c.Compare == nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.join_count_compare requires check.compare", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.join_count_compare requires check.compare")
 		}
 		if strings.TrimSpace(c.Dataset) != "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.join_count_compare forbids check.dataset", path, r.Key))
+			errorf(diag.CodeCheckFields, "/dataset", "dataset.join_count_compare forbids check.dataset")
+		}
+		if c.Assert != nil || c.Expect != nil {
+			errorf(diag.CodeCheckFields, "", "dataset.join_count_compare forbids check.assert/check.expect")
+		}
+	case types.CheckTypeDatasetJoinFieldCompare:
+		if c.Left == nil || strings.TrimSpace(c.Left.Dataset) == "" || strings.TrimSpace(c.Left.KeyPath) == "" {
+			errorf(diag.CodeCheckFields, "", "dataset.join_field_compare requires check.left.dataset and check.left.key_path")
+		}
+		if c.Right == nil || strings.TrimSpace(c.Right.Dataset) == "" || strings.TrimSpace(c.Right.KeyPath) == "" {
+			errorf(diag.CodeCheckFields, "", "dataset.join_field_compare requires check.right.dataset and check.right.key_path")
+		}
+		if c.Assert == nil {
+			errorf(diag.CodeCheckFields, "", "dataset.join_field_compare requires check.assert")
+		}
+		if strings.TrimSpace(c.Dataset) != "" {
+			errorf(diag.CodeCheckFields, "/dataset", "dataset.join_field_compare forbids check.dataset")
+		}
+		if c.Compare != nil {
+			errorf(diag.CodeCheckFields, "/compare", "dataset.join_field_compare forbids check.compare")
+		}
+	case types.CheckTypeDatasetAggregateCompare:
+		if strings.TrimSpace(c.Dataset) == "" {
+			errorf(diag.CodeCheckFields, "", "dataset.aggregate_compare requires check.dataset")
+		}
+		if c.Aggregate == nil {
+			errorf(diag.CodeCheckFields, "", "dataset.aggregate_compare requires check.aggregate")
+		} else if strings.TrimSpace(c.Aggregate.Path) == "" {
+			errorf(diag.CodeCheckFields, "/aggregate/path", "dataset.aggregate_compare requires check.aggregate.path")
+		}
+		if c.Compare == nil {
+			errorf(diag.CodeCheckFields, "", "dataset.aggregate_compare requires check.compare")
 		}
 		if c.Assert != nil || c.Expect != nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset.join_count_compare forbids check.assert/check.expect", path, r.Key))
+			errorf(diag.CodeCheckFields, "", "dataset.aggregate_compare forbids check.assert/check.expect")
+		}
+		if c.Left != nil || c.Right != nil {
+			errorf(diag.CodeCheckFields, "", "dataset.aggregate_compare forbids check.left/check.right")
+		}
+	}
+	if c.Aggregate != nil && c.Type != types.CheckTypeDatasetAggregateCompare {
+		errorf(diag.CodeCheckFields, "/aggregate", "%s forbids check.aggregate", c.Type)
+	}
+
+	// Connector-qualified datasets. Connector instance rulesets read every dataset from
+	// scope.connector_kind; validateReferences checks that the qualifying manifests exist.
+	if c.ConnectorKind != "" {
+		switch {
+		case c.Type.IsJoin():
+			errorf(diag.CodeCheckFields, "/connector_kind", "%s forbids check.connector_kind; set check.left.connector_kind and check.right.connector_kind instead", c.Type)
+		case c.Type == types.CheckTypeManualAttestation:
+			errorf(diag.CodeCheckFields, "/connector_kind", "%s forbids check.connector_kind", c.Type)
+		}
+	}
+	if rs.Scope.Kind != types.ScopeKindGlobal {
+		for _, q := range connectorQualifiers(c) {
+			errorf(diag.CodeScope, q.field, "%s is only allowed in global rulesets, not scope.kind=%q", q.name, rs.Scope.Kind)
 		}
 	}
 
 	// 6.8 Predicate structural constraints
-	if c.Type == types.CheckTypeDatasetJoinCountCompare {
+	if c.Type.IsJoin() {
 		for i := range c.Where {
-			errs = append(errs, validateJoinPredicate(path, r.Key, "check.where", i, c.Where[i])...)
+			errs = append(errs, validateJoinPredicate(path, fmt.Sprintf("%s/where/%d", ptr, i), r.Key, "check.where", i, c.Where[i])...)
+			// Left and right rows are filtered separately, so a group must stay on one side.
+			if left, right := predicateSides(&c.Where[i]); left && right {
+				errorf(diag.CodePredicate, fmt.Sprintf("/where/%d", i), "%s: group mixes left_path and right_path predicates", predicateField("check.where", i))
+			}
+		}
+		// assert is evaluated on joined (left, right) pairs, so its groups may mix sides.
+		if c.Assert != nil {
+			errs = append(errs, validateJoinPredicate(path, ptr+"/assert", r.Key, "check.assert", -1, *c.Assert)...)
 		}
 	} else {
 		for i := range c.Where {
-			errs = append(errs, validatePredicate(path, r.Key, "check.where", i, c.Where[i])...)
+			errs = append(errs, validatePredicate(path, fmt.Sprintf("%s/where/%d", ptr, i), r.Key, "check.where", i, c.Where[i])...)
 		}
 		if c.Assert != nil {
-			errs = append(errs, validatePredicate(path, r.Key, "check.assert", -1, *c.Assert)...)
+			errs = append(errs, validatePredicate(path, ptr+"/assert", r.Key, "check.assert", -1, *c.Assert)...)
 		}
 	}
 
@@ -249,7 +351,7 @@ func validateCheck(path string, rs *types.Ruleset, r *types.Rule, c *types.Check
 	}
 	for _, d := range datasetsReferencedByCheck(c) {
 		if _, ok := requiredDataSet[d]; !ok {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: required_data missing dataset %q referenced by check", path, r.Key, d))
+			errs = append(errs, ruleErrorf(diag.CodeRequiredDataCoverage, path, strings.TrimSuffix(ptr, "/check")+"/required_data", r, "required_data missing dataset %q referenced by check", d))
 		}
 	}
 
@@ -258,11 +360,11 @@ func validateCheck(path string, rs *types.Ruleset, r *types.Rule, c *types.Check
 	for _, d := range referencedDatasets {
 		versions := contractsIdx.versionsByDataset[d]
 		if c.DatasetVersion == 0 && len(versions) > 1 {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: dataset %q has multiple data_contracts versions; check.dataset_version is required", path, r.Key, d))
+			errorf(diag.CodeDatasetVersion, "", "dataset %q has multiple data_contracts versions; check.dataset_version is required", d)
 		}
 		if c.DatasetVersion != 0 {
 			if _, ok := contractsIdx.pairSet[fmt.Sprintf("%s@%d", d, c.DatasetVersion)]; !ok {
-				errs = append(errs, fmt.Errorf("semantic: %s: rule %q: check.dataset_version=%d requires ruleset.data_contracts entry for %q@%d", path, r.Key, c.DatasetVersion, d, c.DatasetVersion))
+				errorf(diag.CodeDatasetVersion, "/dataset_version", "check.dataset_version=%d requires ruleset.data_contracts entry for %q@%d", c.DatasetVersion, d, c.DatasetVersion)
 			}
 		}
 	}
@@ -271,22 +373,21 @@ func validateCheck(path string, rs *types.Ruleset, r *types.Rule, c *types.Check
 	valueParams := valueParamsReferencedByCheck(c)
 	if len(valueParams) > 0 {
 		if r.Parameters == nil || r.Parameters.Defaults == nil {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: value_param used but parameters.defaults is missing", path, r.Key))
+			errorf(diag.CodeValueParam, "", "value_param used but parameters.defaults is missing")
 		} else {
 			for _, vp := range valueParams {
 				if _, ok := r.Parameters.Defaults[vp]; !ok {
-					errs = append(errs, fmt.Errorf("semantic: %s: rule %q: value_param %q not found in parameters.defaults", path, r.Key, vp))
+					errorf(diag.CodeValueParam, "", "value_param %q not found in parameters.defaults", vp)
 				}
 			}
+			errs = append(errs, validateValueParamTypes(path, ptr, r, c)...)
 		}
 	}
 
 	// Compare clause structural constraints (also includes parameter references).
 	if c.Compare != nil {
-		errs = append(errs, validateCompare(path, r.Key, c.Compare)...)
+		errs = append(errs, validateCompare(path, ptr+"/compare", r.Key, c.Type, c.Compare)...)
 	}
-
-	_ = rs
 	return errs
 }
 
@@ -294,24 +395,11 @@ func datasetsReferencedByCheck(c *types.Check) []string {
 	if c == nil {
 		return nil
 	}
-	switch c.Type {
-	case types.CheckTypeDatasetFieldCompare, types.CheckTypeDatasetCountCompare:
-		if strings.TrimSpace(c.Dataset) == "" {
-			return nil
-		}
-		return []string{c.Dataset}
-	case types.CheckTypeDatasetJoinCountCompare:
-		var out []string
-		if c.Left != nil && strings.TrimSpace(c.Left.Dataset) != "" {
-			out = append(out, c.Left.Dataset)
-		}
-		if c.Right != nil && strings.TrimSpace(c.Right.Dataset) != "" {
-			out = append(out, c.Right.Dataset)
-		}
-		return out
-	default:
-		return nil
+	var out []string
+	for _, d := range c.Datasets() {
+		out = append(out, d.Dataset)
 	}
+	return out
 }
 
 func valueParamsReferencedByCheck(c *types.Check) []string {
@@ -320,15 +408,16 @@ func valueParamsReferencedByCheck(c *types.Check) []string {
 	}
 	set := map[string]struct{}{}
 
-	for i := range c.Where {
-		if vp := strings.TrimSpace(c.Where[i].ValueParam); vp != "" {
+	add := func(_ string, p *types.Predicate) {
+		if vp := strings.TrimSpace(p.ValueParam); vp != "" {
 			set[vp] = struct{}{}
 		}
 	}
+	for i := range c.Where {
+		c.Where[i].Walk(add)
+	}
 	if c.Assert != nil {
-		if vp := strings.TrimSpace(c.Assert.ValueParam); vp != "" {
-			set[vp] = struct{}{}
-		}
+		c.Assert.Walk(add)
 	}
 	if c.Compare != nil {
 		if vp := strings.TrimSpace(c.Compare.ValueParam); vp != "" {
@@ -347,70 +436,252 @@ func valueParamsReferencedByCheck(c *types.Check) []string {
 	return out
 }
 
-func validatePredicate(path, ruleKey, field string, index int, p types.Predicate) []error {
-	var errs []error
+func predicateField(field string, index int) string {
+	if index < 0 {
+		return field
+	}
+	return fmt.Sprintf("%s[%d]", field, index)
+}
+
+// walkComparisons calls fn for every comparison in p (p itself, or the predicates nested
+// in its groups), with its field name and pointer.
+func walkComparisons(field, ptr string, p *types.Predicate, fn func(field, ptr string, q *types.Predicate)) {
+	p.Walk(func(rel string, q *types.Predicate) {
+		if q.IsGroup() {
+			return
+		}
+		f := field
+		for _, seg := range strings.Split(rel, "/")[1:] {
+			if _, err := strconv.Atoi(seg); err == nil {
+				f += "[" + seg + "]"
+			} else {
+				f += "." + seg
+			}
+		}
+		fn(f, ptr+rel, q)
+	})
+}
+
+// predicateSides reports whether p compares left_path and right_path fields.
+func predicateSides(p *types.Predicate) (left, right bool) {
+	p.Walk(func(_ string, q *types.Predicate) {
+		left = left || strings.TrimSpace(q.LeftPath) != ""
+		right = right || strings.TrimSpace(q.RightPath) != ""
+	})
+	return left, right
+}
+
+// validatePredicateGroup checks a boolean group (6.8): exactly one non-empty all_of, any_of
+// or not, and no comparison fields. Nested predicates are checked with validate.
+func validatePredicateGroup(path, ptr, ruleKey, field string, p types.Predicate, validate func(path, ptr, ruleKey, field string, index int, p types.Predicate) diag.List) diag.List {
+	var errs diag.List
+	errorf := func(format string, args ...any) {
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: %s", ruleKey, field, fmt.Sprintf(format, args...)))
+	}
+	groups := 0
+	for _, set := range []bool{p.AllOf != nil, p.AnyOf != nil, p.Not != nil} {
+		if set {
+			groups++
+		}
+	}
+	if groups > 1 {
+		errorf("must set exactly one of all_of, any_of or not")
+	}
+	if p.Path != "" || p.LeftPath != "" || p.RightPath != "" || p.Op != "" || p.Value != nil || p.ValueParam != "" {
+		errorf("all_of/any_of/not cannot be combined with path, left_path, right_path, op, value or value_param")
+	}
+	if p.AllOf != nil && len(p.AllOf) == 0 {
+		errorf("all_of must not be empty")
+	}
+	if p.AnyOf != nil && len(p.AnyOf) == 0 {
+		errorf("any_of must not be empty")
+	}
+	for i := range p.AllOf {
+		errs = append(errs, validate(path, fmt.Sprintf("%s/all_of/%d", ptr, i), ruleKey, fmt.Sprintf("%s.all_of[%d]", field, i), -1, p.AllOf[i])...)
+	}
+	for i := range p.AnyOf {
+		errs = append(errs, validate(path, fmt.Sprintf("%s/any_of/%d", ptr, i), ruleKey, fmt.Sprintf("%s.any_of[%d]", field, i), -1, p.AnyOf[i])...)
+	}
+	if p.Not != nil {
+		errs = append(errs, validate(path, ptr+"/not", ruleKey, field+".not", -1, *p.Not)...)
+	}
+	return errs
+}
+
+func validatePredicate(path, ptr, ruleKey, field string, index int, p types.Predicate) diag.List {
+	var errs diag.List
+	f := predicateField(field, index)
+	if p.IsGroup() {
+		return validatePredicateGroup(path, ptr, ruleKey, f, p, validatePredicate)
+	}
 
 	if strings.TrimSpace(p.Path) == "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: missing path", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: missing path", ruleKey, f))
 	}
 	if strings.TrimSpace(p.LeftPath) != "" || strings.TrimSpace(p.RightPath) != "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: left_path/right_path not allowed in non-join predicate", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: left_path/right_path not allowed in non-join predicate", ruleKey, f))
 	}
 	if p.Op == "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: missing op", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: missing op", ruleKey, f))
 		return errs
 	}
 
-	errs = append(errs, validatePredicateValue(path, ruleKey, field, index, p.Op, p.Value, p.ValueParam)...)
+	errs = append(errs, validatePredicateValue(path, ptr, ruleKey, f, p.Op, p.Value, p.ValueParam)...)
+	errs = append(errs, validateQuantifier(path, ptr, ruleKey, f, p)...)
 	return errs
 }
 
-func validateJoinPredicate(path, ruleKey, field string, index int, p types.Predicate) []error {
-	var errs []error
+func validateJoinPredicate(path, ptr, ruleKey, field string, index int, p types.Predicate) diag.List {
+	var errs diag.List
+	f := predicateField(field, index)
+	if p.IsGroup() {
+		return validatePredicateGroup(path, ptr, ruleKey, f, p, validateJoinPredicate)
+	}
 
 	if strings.TrimSpace(p.Path) != "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: path not allowed in join predicate", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr+"/path", "rule %q: %s: path not allowed in join predicate", ruleKey, f))
 	}
 	leftSet := strings.TrimSpace(p.LeftPath) != ""
 	rightSet := strings.TrimSpace(p.RightPath) != ""
 	if leftSet == rightSet {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: must set exactly one of left_path or right_path", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: must set exactly one of left_path or right_path", ruleKey, f))
 	}
 	if p.Op == "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: missing op", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: missing op", ruleKey, f))
 		return errs
 	}
 
-	errs = append(errs, validatePredicateValue(path, ruleKey, field, index, p.Op, p.Value, p.ValueParam)...)
+	errs = append(errs, validatePredicateValue(path, ptr, ruleKey, f, p.Op, p.Value, p.ValueParam)...)
+	errs = append(errs, validateQuantifier(path, ptr, ruleKey, f, p)...)
 	return errs
 }
 
-func validatePredicateValue(path, ruleKey, field string, index int, op types.Operator, value any, valueParam string) []error {
-	var errs []error
+// validateQuantifier checks that a quantifier is only set on predicates whose path has a
+// wildcard segment, since it combines the values the wildcards reach.
+func validateQuantifier(path, ptr, ruleKey, field string, p types.Predicate) diag.List {
+	if p.Quantifier == "" || types.HasWildcard(p.ComparedPath()) {
+		return nil
+	}
+	return diag.List{diag.New(diag.CodePredicate, path, ptr+"/quantifier", "rule %q: %s: quantifier=%q requires a path with a %q wildcard segment", ruleKey, field, p.Quantifier, types.PathWildcard)}
+}
+
+func validatePredicateValue(path, ptr, ruleKey, field string, op types.Operator, value any, valueParam string) diag.List {
+	var errs diag.List
 	if op == types.OperatorExists || op == types.OperatorAbsent {
 		if value != nil || strings.TrimSpace(valueParam) != "" {
-			errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: op=%q forbids value and value_param", path, ruleKey, field, index, op))
+			errs = append(errs, diag.New(diag.CodePredicate, path, ptr+"/op", "rule %q: %s: op=%q forbids value and value_param", ruleKey, field, op))
 		}
 		return errs
 	}
 	if value != nil && strings.TrimSpace(valueParam) != "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: %s[%d]: value and value_param are mutually exclusive", path, ruleKey, field, index))
+		errs = append(errs, diag.New(diag.CodePredicate, path, ptr, "rule %q: %s: value and value_param are mutually exclusive", ruleKey, field))
+		return errs
+	}
+	if (op.IsStringMatch() || op.IsRelativeTime()) && strings.TrimSpace(valueParam) == "" {
+		s, ok := value.(string)
+		if !ok {
+			want := "a string"
+			if op.IsRelativeTime() {
+				want = "an ISO-8601 duration"
+			}
+			errs = append(errs, diag.New(diag.CodePredicate, path, ptr+"/value", "rule %q: %s: op=%q requires %s value, got %s", ruleKey, field, op, want, jsonString(value)))
+		} else if msg := checkOperand(op, s); msg != "" {
+			errs = append(errs, diag.New(diag.CodePredicate, path, ptr+"/value", "rule %q: %s: %s", ruleKey, field, msg))
+		}
 	}
 	return errs
 }
 
-func validateCompare(path, ruleKey string, c *types.Compare) []error {
+// checkOperand returns why s is not a valid operand of op, or "" if it is: op=matches takes
+// an RE2 regular expression and older_than/newer_than an ISO-8601 duration. Operands of
+// other operators are not checked.
+func checkOperand(op types.Operator, s string) string {
+	switch op {
+	case types.OperatorMatches:
+		if _, err := regexp.Compile(s); err != nil {
+			return fmt.Sprintf("op=%q: %q is not a valid RE2 regular expression: %v", op, s, err)
+		}
+	case types.OperatorOlderThan, types.OperatorNewerThan:
+		if _, err := duration.Parse(s); err != nil {
+			return fmt.Sprintf("op=%q: %v", op, err)
+		}
+	}
+	return ""
+}
+
+func validateCompare(path, ptr, ruleKey string, checkType types.CheckType, c *types.Compare) diag.List {
 	if c == nil {
 		return nil
 	}
-	var errs []error
+	var errs diag.List
 	if c.Op == "" {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: check.compare missing op", path, ruleKey))
+		errs = append(errs, diag.New(diag.CodeCompare, path, ptr, "rule %q: check.compare missing op", ruleKey))
 	}
 	hasValue := c.Value != nil
 	hasValueParam := strings.TrimSpace(c.ValueParam) != ""
 	if hasValue == hasValueParam {
-		errs = append(errs, fmt.Errorf("semantic: %s: rule %q: check.compare must set exactly one of value or value_param", path, ruleKey))
+		errs = append(errs, diag.New(diag.CodeCompare, path, ptr, "rule %q: check.compare must set exactly one of value or value_param", ruleKey))
+	}
+	// Only aggregates can be fractional; counts are compared with integers.
+	if hasValue && checkType != types.CheckTypeDatasetAggregateCompare && *c.Value != math.Trunc(*c.Value) {
+		errs = append(errs, diag.New(diag.CodeCompare, path, ptr+"/value", "rule %q: check.compare value must be an integer for %s", ruleKey, checkType))
 	}
 	return errs
 }
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// locate maps diagnostic pointers from normalized documents back to their sources and
+// sets line/column positions. Documents without source bytes are left unchanged.
+func locate(b *Bundle, errs diag.List) {
+	if len(b.Sources) == 0 {
+		return
+	}
+	docs := map[string]any{}
+	for _, rs := range b.Rulesets {
+		docs[rs.Path] = rs.Doc
+	}
+	for _, dc := range b.DatasetContracts {
+		docs[dc.Path] = dc.Doc
+	}
+	for _, c := range b.Connectors {
+		docs[c.Path] = c.Doc
+	}
+	for _, p := range b.Profiles {
+		docs[p.Path] = p.Doc
+	}
+	for _, s := range b.Conformance {
+		docs[s.Path] = s.Doc
+	}
+
+	type decoded struct{ normalized, source any }
+	cache := map[string]*decoded{}
+	loc := func(l *diag.Location) {
+		src, ok := b.Sources[l.File]
+		if !ok {
+			return
+		}
+		d := cache[l.File]
+		if d == nil {
+			d = &decoded{source: diag.Decode(src)}
+			if doc, ok := docs[l.File]; ok {
+				if nb, err := json.Marshal(doc); err == nil {
+					d.normalized = diag.Decode(nb)
+				}
+			}
+			cache[l.File] = d
+		}
+		if d.normalized != nil {
+			l.Pointer = diag.Remap(d.normalized, d.source, l.Pointer)
+		}
+		l.Locate(src)
+	}
+	for _, e := range errs {
+		loc(&e.Location)
+		for i := range e.Related {
+			loc(&e.Related[i])
+		}
+	}
+}
@@ -2,10 +2,14 @@ package schemasem
 
 import (
 	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/normalize"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
 	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
@@ -98,7 +102,10 @@ func TestValidateSemantic_ValidExamples_PerCheckType(t *testing.T) {
         "severity": "high",
         "monitoring": { "status": "automated" },
         "required_data": ["okta:policies/sign-on"],
-        "parameters": { "defaults": { "max_idle_minutes": 15 } },
+        "parameters": {
+          "defaults": { "max_idle_minutes": 15 },
+          "schema": { "max_idle_minutes": { "type": "integer", "minimum": 0 } }
+        },
         "check": {
           "type": "dataset.field_compare",
           "dataset": "okta:policies/sign-on",
@@ -132,7 +139,10 @@ func TestValidateSemantic_ValidExamples_PerCheckType(t *testing.T) {
         "severity": "medium",
         "monitoring": { "status": "automated" },
         "required_data": ["okta:log-streams"],
-        "parameters": { "defaults": { "min_enabled": 1 } },
+        "parameters": {
+          "defaults": { "min_enabled": 1 },
+          "schema": { "min_enabled": { "type": "integer", "minimum": 0 } }
+        },
         "check": {
           "type": "dataset.count_compare",
           "dataset": "okta:log-streams",
@@ -145,6 +155,37 @@ func TestValidateSemantic_ValidExamples_PerCheckType(t *testing.T) {
       }
     ]
   }
+}`,
+		},
+		{
+			name: "dataset.aggregate_compare",
+			doc: `{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.aggregate_compare.v1",
+    "name": "Example aggregate compare",
+    "scope": { "kind": "connector_instance", "connector_kind": "okta" },
+    "data_contracts": [
+      { "dataset": "okta:policies/sign-on", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "Few sign-on rules",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["okta:policies/sign-on"],
+        "check": {
+          "type": "dataset.aggregate_compare",
+          "dataset": "okta:policies/sign-on",
+          "dataset_version": 1,
+          "aggregate": { "function": "max", "path": "/priority" },
+          "compare": { "op": "lte", "value": 10 }
+        }
+      }
+    ]
+  }
 }`,
 		},
 		{
@@ -167,7 +208,10 @@ func TestValidateSemantic_ValidExamples_PerCheckType(t *testing.T) {
         "severity": "high",
         "monitoring": { "status": "automated" },
         "required_data": ["core:identities", "core:entitlement_assignments"],
-        "parameters": { "defaults": { "max_admin_entitlements": 0 } },
+        "parameters": {
+          "defaults": { "max_admin_entitlements": 0 },
+          "schema": { "max_admin_entitlements": { "type": "integer", "minimum": 0 } }
+        },
         "check": {
           "type": "dataset.join_count_compare",
           "dataset_version": 1,
@@ -181,6 +225,48 @@ func TestValidateSemantic_ValidExamples_PerCheckType(t *testing.T) {
       }
     ]
   }
+}`,
+		},
+		{
+			name: "dataset.join_field_compare",
+			doc: `{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.join_field_compare.v1",
+    "name": "Example join field compare",
+    "scope": { "kind": "global" },
+    "data_contracts": [
+      { "dataset": "core:identities", "version": 1 },
+      { "dataset": "core:authenticators", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "Admins use phishing-resistant factors",
+        "severity": "high",
+        "monitoring": { "status": "automated" },
+        "required_data": ["core:identities", "core:authenticators"],
+        "check": {
+          "type": "dataset.join_field_compare",
+          "dataset_version": 1,
+          "left": { "dataset": "core:identities", "key_path": "/email" },
+          "right": { "dataset": "core:authenticators", "key_path": "/identity/email" },
+          "where": [
+            { "left_path": "/is_admin", "op": "eq", "value": true }
+          ],
+          "assert": {
+            "all_of": [
+              { "right_path": "/type", "op": "eq", "value": "webauthn" },
+              { "right_path": "/status", "op": "eq", "value": "ACTIVE" }
+            ]
+          },
+          "expect": { "match": "all" },
+          "on_unmatched_left": "count"
+        }
+      }
+    ]
+  }
 }`,
 		},
 	}
@@ -443,6 +529,124 @@ func TestValidateSemantic_JoinWhereExactlyOneSide(t *testing.T) {
 	}
 }
 
+func TestValidateSemantic_AggregateCompareFields(t *testing.T) {
+	cases := []struct {
+		name  string
+		check string
+		want  string
+	}{
+		{
+			name:  "missing compare",
+			check: `{ "type": "dataset.aggregate_compare", "dataset": "okta:log-streams", "aggregate": { "function": "count_distinct", "path": "/type" } }`,
+			want:  "dataset.aggregate_compare requires check.compare",
+		},
+		{
+			name:  "missing aggregate",
+			check: `{ "type": "dataset.aggregate_compare", "dataset": "okta:log-streams", "compare": { "op": "eq", "value": 1 } }`,
+			want:  "dataset.aggregate_compare requires check.aggregate",
+		},
+		{
+			name:  "assert",
+			check: `{ "type": "dataset.aggregate_compare", "dataset": "okta:log-streams", "aggregate": { "function": "count_distinct", "path": "/type" }, "compare": { "op": "eq", "value": 1 }, "assert": { "path": "/status", "op": "eq", "value": "ACTIVE" } }`,
+			want:  "dataset.aggregate_compare forbids check.assert/check.expect",
+		},
+		{
+			name:  "aggregate on count_compare",
+			check: `{ "type": "dataset.count_compare", "dataset": "okta:log-streams", "aggregate": { "function": "count_distinct", "path": "/type" }, "compare": { "op": "eq", "value": 1 } }`,
+			want:  "dataset.count_compare forbids check.aggregate",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateRulesetDocJSON(t, `{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.aggregate_bad.v1",
+    "name": "Example aggregate bad",
+    "scope": { "kind": "connector_instance", "connector_kind": "okta" },
+    "data_contracts": [
+      { "dataset": "okta:log-streams", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "R1",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["okta:log-streams"],
+        "check": `+tc.check+`
+      }
+    ]
+  }
+}`)
+			if !containsErr(errs, tc.want) {
+				t.Fatalf("expected %q, got:\n%s", tc.want, joinErrs(errs))
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_JoinFieldCompareFields(t *testing.T) {
+	const join = `"left": { "dataset": "core:identities", "key_path": "/email" }, "right": { "dataset": "core:authenticators", "key_path": "/identity/email" }`
+	cases := []struct {
+		name  string
+		check string
+		want  string
+	}{
+		{
+			name:  "missing assert",
+			check: `{ "type": "dataset.join_field_compare", ` + join + ` }`,
+			want:  "dataset.join_field_compare requires check.assert",
+		},
+		{
+			name:  "missing right",
+			check: `{ "type": "dataset.join_field_compare", "left": { "dataset": "core:identities", "key_path": "/email" }, "assert": { "left_path": "/is_admin", "op": "eq", "value": true } }`,
+			want:  "dataset.join_field_compare requires check.right.dataset and check.right.key_path",
+		},
+		{
+			name:  "compare",
+			check: `{ "type": "dataset.join_field_compare", ` + join + `, "assert": { "right_path": "/type", "op": "eq", "value": "webauthn" }, "compare": { "op": "eq", "value": 1 } }`,
+			want:  "dataset.join_field_compare forbids check.compare",
+		},
+		{
+			name:  "assert path",
+			check: `{ "type": "dataset.join_field_compare", ` + join + `, "assert": { "any_of": [ { "path": "/type", "op": "eq", "value": "webauthn" } ] } }`,
+			want:  "check.assert.any_of[0]: path not allowed in join predicate",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateRulesetDocJSON(t, `{
+  "schema_version": 1,
+  "kind": "opensspm.ruleset",
+  "ruleset": {
+    "key": "example.join_field_bad.v1",
+    "name": "Example join field bad",
+    "scope": { "kind": "global" },
+    "data_contracts": [
+      { "dataset": "core:identities", "version": 1 },
+      { "dataset": "core:authenticators", "version": 1 }
+    ],
+    "rules": [
+      {
+        "key": "R1",
+        "title": "R1",
+        "severity": "low",
+        "monitoring": { "status": "automated" },
+        "required_data": ["core:identities", "core:authenticators"],
+        "check": `+tc.check+`
+      }
+    ]
+  }
+}`)
+			if !containsErr(errs, tc.want) {
+				t.Fatalf("expected %q, got:\n%s", tc.want, joinErrs(errs))
+			}
+		})
+	}
+}
+
 func TestValidateSemantic_PredicateExistsForbidsValue(t *testing.T) {
 	errs := validateRulesetDocJSON(t, `{
   "schema_version": 1,
@@ -565,7 +769,7 @@ func minimalRulesetDoc(key string, scope types.Scope) types.RulesetDoc {
 	}
 }
 
-func validateRulesetDocJSON(t *testing.T, doc string) []error {
+func validateRulesetDocJSON(t *testing.T, doc string) diag.List {
 	t.Helper()
 
 	root := testutil.RepoRoot(t)
@@ -590,11 +794,69 @@ func validateRulesetDocJSON(t *testing.T, doc string) []error {
 			Path string
 			Doc  types.RulesetDoc
 		}{{Path: "inline.json", Doc: rs}},
+		Sources: map[string][]byte{"inline.json": b},
 	}
+	addReferencedDocs(bundle)
 	return ValidateSemantic(bundle)
 }
 
-func containsErr(errs []error, substr string) bool {
+// addReferencedDocs adds the dataset contracts and connector manifests the bundle's rulesets
+// reference, so tests focused on a single ruleset pass cross-document resolution.
+func addReferencedDocs(b *Bundle) {
+	contracts := map[string]bool{}
+	addContract := func(dataset string, version int) {
+		k := fmt.Sprintf("%s@%d", dataset, version)
+		if contracts[k] {
+			return
+		}
+		contracts[k] = true
+		b.DatasetContracts = append(b.DatasetContracts, struct {
+			Path string
+			Doc  types.DatasetContractDoc
+		}{Path: "contracts/" + k + ".json", Doc: types.DatasetContractDoc{
+			SchemaVersion: 1,
+			Kind:          "opensspm.dataset_contract",
+			Dataset:       types.DatasetContract{Key: dataset, Version: version, Schema: json.RawMessage(`{"type":"object"}`)},
+		}})
+	}
+
+	provides := map[string][]types.DatasetRefSpec{}
+	for _, rs := range b.Rulesets {
+		ruleset := &rs.Doc.Ruleset
+		for _, dc := range ruleset.DataContracts {
+			addContract(dc.Dataset, dc.Version)
+		}
+		if ruleset.Scope.Kind != types.ScopeKindConnectorInstance || ruleset.Scope.ConnectorKind == "" {
+			continue
+		}
+		for _, ref := range datasetRefsReadByRuleset(ruleset) {
+			i := strings.LastIndex(ref, "@")
+			version, _ := strconv.Atoi(ref[i+1:])
+			addContract(ref[:i], version)
+			provides[ruleset.Scope.ConnectorKind] = append(provides[ruleset.Scope.ConnectorKind], types.DatasetRefSpec{Dataset: ref[:i], Version: version})
+		}
+		if _, ok := provides[ruleset.Scope.ConnectorKind]; !ok {
+			provides[ruleset.Scope.ConnectorKind] = nil
+		}
+	}
+	kinds := make([]string, 0, len(provides))
+	for k := range provides {
+		kinds = append(kinds, k)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		b.Connectors = append(b.Connectors, struct {
+			Path string
+			Doc  types.ConnectorManifestDoc
+		}{Path: "connectors/" + kind + ".json", Doc: types.ConnectorManifestDoc{
+			SchemaVersion: 1,
+			Kind:          "opensspm.connector_manifest",
+			Connector:     types.ConnectorManifest{Kind: kind, Name: kind, Provides: provides[kind]},
+		}})
+	}
+}
+
+func containsErr(errs diag.List, substr string) bool {
 	for _, e := range errs {
 		if strings.Contains(e.Error(), substr) {
 			return true
@@ -603,7 +865,7 @@ func containsErr(errs []error, substr string) bool {
 	return false
 }
 
-func joinErrs(errs []error) string {
+func joinErrs(errs diag.List) string {
 	var b strings.Builder
 	for _, e := range errs {
 		b.WriteString(e.Error())
@@ -611,3 +873,48 @@ func joinErrs(errs []error) string {
 	}
 	return b.String()
 }
+
+func TestValidateSemantic_ConnectorKindQualifiers(t *testing.T) {
+	countRule := types.Rule{
+		Key:          "R2",
+		Title:        "R2",
+		Severity:     types.SeverityLow,
+		Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+		RequiredData: []string{"okta:users"},
+		Check: &types.Check{
+			Type:          types.CheckTypeDatasetCountCompare,
+			Dataset:       "okta:users",
+			ConnectorKind: "okta",
+			Compare:       &types.Compare{Op: types.CompareOpEq, Value: new(float64)},
+		},
+	}
+	joinRule := types.Rule{
+		Key:          "R3",
+		Title:        "R3",
+		Severity:     types.SeverityLow,
+		Monitoring:   types.Monitoring{Status: types.MonitoringStatusAutomated},
+		RequiredData: []string{"okta:users"},
+		Check: &types.Check{
+			Type:          types.CheckTypeDatasetJoinCountCompare,
+			ConnectorKind: "okta",
+			Left:          &types.JoinSide{Dataset: "okta:users", KeyPath: "/id"},
+			Right:         &types.JoinSide{Dataset: "okta:users", KeyPath: "/id"},
+			Compare:       &types.Compare{Op: types.CompareOpEq, Value: new(float64)},
+		},
+	}
+	rs := minimalRulesetDoc("okta.v1", types.Scope{Kind: types.ScopeKindConnectorInstance, ConnectorKind: "okta"})
+	rs.Ruleset.Rules = append(rs.Ruleset.Rules, countRule, joinRule)
+
+	errs := ValidateSemantic(&Bundle{Rulesets: []struct {
+		Path string
+		Doc  types.RulesetDoc
+	}{{Path: "inline.json", Doc: rs}}})
+	for _, want := range []string{
+		`rule "R2": check.connector_kind is only allowed in global rulesets, not scope.kind="connector_instance"`,
+		`rule "R3": dataset.join_count_compare forbids check.connector_kind; set check.left.connector_kind and check.right.connector_kind instead`,
+	} {
+		if !containsErr(errs, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, joinErrs(errs))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package schemasem
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// rulesetVersions indexes the rulesets of a bundle by key. Each key's rulesets are in
+// descending version order; unversioned rulesets come last.
+type rulesetVersions map[string][]rulesetVersion
+
+type rulesetVersion struct {
+	index   int // in Bundle.Rulesets
+	version *semver.Version
+}
+
+func indexRulesetVersions(b *Bundle) rulesetVersions {
+	rv := rulesetVersions{}
+	for i := range b.Rulesets {
+		rs := &b.Rulesets[i].Doc.Ruleset
+		e := rulesetVersion{index: i}
+		if rs.Version != "" {
+			v, err := semver.Parse(rs.Version)
+			if err != nil {
+				continue // rejected by the metaschema
+			}
+			e.version = &v
+		}
+		rv[rs.Key] = append(rv[rs.Key], e)
+	}
+	for _, entries := range rv {
+		slices.SortStableFunc(entries, func(a, b rulesetVersion) int {
+			switch {
+			case a.version == nil && b.version == nil:
+				return 0
+			case a.version == nil:
+				return 1
+			case b.version == nil:
+				return -1
+			}
+			return semver.Compare(*b.version, *a.version)
+		})
+	}
+	return rv
+}
+
+// resolve returns the index in Bundle.Rulesets of the highest version of ruleset key that
+// satisfies constraint, or -1. An empty constraint matches every version, including an
+// unversioned ruleset; a constraint never matches an unversioned ruleset. The error
+// reports an invalid constraint.
+func (rv rulesetVersions) resolve(key, constraint string) (int, error) {
+	entries := rv[key]
+	if constraint == "" {
+		if len(entries) == 0 {
+			return -1, nil
+		}
+		return entries[0].index, nil
+	}
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return -1, err
+	}
+	for _, e := range entries {
+		if e.version != nil && c.Check(*e.version) {
+			return e.index, nil
+		}
+	}
+	return -1, nil
+}
+
+// available lists the versions of ruleset key, for hints.
+func (rv rulesetVersions) available(key string) string {
+	var out []string
+	for _, e := range rv[key] {
+		if e.version != nil {
+			out = append(out, e.version.String())
+		} else {
+			out = append(out, "(unversioned)")
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// ResolveRuleset returns the highest version of ruleset key in b that satisfies the semver
+// constraint (see semver.ParseConstraint; empty matches any version), or nil if none does.
+func ResolveRuleset(b *Bundle, key, constraint string) *types.RulesetDoc {
+	i, err := indexRulesetVersions(b).resolve(key, constraint)
+	if err != nil || i < 0 {
+		return nil
+	}
+	return &b.Rulesets[i].Doc
+}
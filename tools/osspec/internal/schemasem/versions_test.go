@@ -0,0 +1,119 @@
+package schemasem
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+// versionedBundle returns a bundle with one ruleset per version (all with key "rs") and a
+// profile "p" including "rs" with the given version constraint.
+func versionedBundle(constraint string, versions ...string) *Bundle {
+	b := &Bundle{}
+	for _, v := range versions {
+		doc := minimalRulesetDoc("rs", types.Scope{Kind: types.ScopeKindGlobal})
+		doc.Ruleset.Version = v
+		// Tell versions apart by their only rule.
+		doc.Ruleset.Rules[0].Key = "R-" + v
+		b.Rulesets = append(b.Rulesets, struct {
+			Path string
+			Doc  types.RulesetDoc
+		}{Path: "rs-" + v + ".json", Doc: doc})
+	}
+	b.Profiles = append(b.Profiles, struct {
+		Path string
+		Doc  types.ProfileDoc
+	}{Path: "p.json", Doc: types.ProfileDoc{Profile: types.Profile{Key: "p", Rulesets: []types.ProfileRulesetRef{{Key: "rs", Version: constraint}}}}})
+	return b
+}
+
+func TestResolveRuleset(t *testing.T) {
+	versions := []string{"1.0.0", "2.1.0", "1.4.2", "2.0.0-rc.1", "2.0.0"}
+	for _, tt := range []struct {
+		constraint string
+		want       string
+	}{
+		{"", "2.1.0"},
+		{"1.0.0", "1.0.0"},
+		{"v1.0.0", "1.0.0"},
+		{"^1.0", "1.4.2"},
+		{"~2.0.0", "2.0.0"},
+		{">=1.1.0 <2.0.0", "1.4.2"},
+		{"2.0.0-rc.1", "2.0.0-rc.1"},
+		{"3.x", ""},
+	} {
+		rs := ResolveRuleset(versionedBundle(tt.constraint, versions...), "rs", tt.constraint)
+		got := ""
+		if rs != nil {
+			got = rs.Ruleset.Version
+		}
+		if got != tt.want {
+			t.Errorf("ResolveRuleset(%q) = %q, want %q", tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSemantic_RulesetVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		versions   []string
+		want       []string
+	}{
+		{
+			name:       "versions coexist",
+			constraint: "^1.0.0",
+			versions:   []string{"1.0.0", "1.1.0", "2.0.0"},
+		},
+		{
+			name:       "duplicate version",
+			constraint: "1.0.0",
+			versions:   []string{"1.0.0", "1.0.0"},
+			want:       []string{`rs-1.0.0.json#/ruleset/version: duplicate ruleset "rs" version "1.0.0" (also in rs-1.0.0.json)`},
+		},
+		{
+			name:     "unversioned alongside versioned",
+			versions: []string{"1.0.0", ""},
+			want:     []string{`rs-.json#/ruleset/key: duplicate ruleset.key "rs" (also in rs-1.0.0.json); rulesets sharing a key must each declare a distinct ruleset.version`},
+		},
+		{
+			name:       "no matching version",
+			constraint: ">=3.0.0",
+			versions:   []string{"1.0.0", "2.0.0"},
+			want:       []string{`p.json#/profile/rulesets/0/version: profile "p": rulesets[0] "rs": no version matches ">=3.0.0"; available versions: 2.0.0, 1.0.0`},
+		},
+		{
+			name:       "constraint on unversioned ruleset",
+			constraint: "1.0.0",
+			versions:   []string{""},
+			want:       []string{`p.json#/profile/rulesets/0/version: profile "p": rulesets[0] "rs": no version matches "1.0.0"; available versions: (unversioned)`},
+		},
+		{
+			name:       "invalid constraint",
+			constraint: "latest",
+			versions:   []string{"1.0.0"},
+			want:       []string{`p.json#/profile/rulesets/0/version: profile "p": rulesets[0].version "latest" is not a valid version constraint: semver: constraint "latest": invalid version "latest"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range ValidateSemantic(versionedBundle(tt.constraint, tt.versions...)) {
+				got = append(got, e.File+"#"+e.Pointer+": "+e.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSemantic_ProfileOverridesUseResolvedVersion(t *testing.T) {
+	b := versionedBundle("1.x", "1.0.0", "2.0.0")
+	b.Profiles[0].Doc.Profile.Rulesets[0].Overrides = []types.RuleOverride{{RuleKey: "R-2.0.0", Exclude: true}}
+	errs := ValidateSemantic(b)
+	if !containsErr(errs, `override of rule "R-2.0.0": rule not found in ruleset "rs"`) || len(errs) != 1 {
+		t.Fatalf("expected an override error against version 1.0.0, got:\n%s", joinErrs(errs))
+	}
+}
@@ -0,0 +1,197 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a set of version ranges. The syntax follows the common npm/Cargo forms:
+//
+//	1.2.3, =1.2.3, v1.2.3   exactly 1.2.3
+//	>1.2.3 >=1.2.3 <2 <=2.1 comparisons; space-separated comparisons must all hold
+//	^1.2.3                  >=1.2.3 <2.0.0 (^0.2.3 is >=0.2.3 <0.3.0)
+//	~1.2.3                  >=1.2.3 <1.3.0
+//	1.x, 1.2.*, *           any version with the given prefix
+//	A || B                  either range
+//
+// Prerelease versions only match comparisons that name a prerelease of the same
+// MAJOR.MINOR.PATCH, so ranges do not select them by accident.
+type Constraint struct {
+	text   string
+	ranges [][]comparator
+}
+
+type comparator struct {
+	op string // one of = > >= < <=
+	v  Version
+}
+
+// ParseConstraint parses s. An exact version without an operator is an exact match.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{text: s}
+	for _, alt := range strings.Split(s, "||") {
+		fields := strings.Fields(alt)
+		if len(fields) == 0 {
+			return Constraint{}, fmt.Errorf("semver: empty range in constraint %q", s)
+		}
+		var r []comparator
+		for _, f := range fields {
+			cs, err := parseComparator(f)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("semver: constraint %q: %w", s, err)
+			}
+			r = append(r, cs...)
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+func (c Constraint) String() string { return c.text }
+
+// Check reports whether v satisfies c.
+func (c Constraint) Check(v Version) bool {
+	for _, r := range c.ranges {
+		if matchesRange(r, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRange(r []comparator, v Version) bool {
+	prereleaseAllowed := len(v.Prerelease) == 0
+	for _, c := range r {
+		if !c.matches(v) {
+			return false
+		}
+		if len(c.v.Prerelease) > 0 && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
+
+func (c comparator) matches(v Version) bool {
+	n := Compare(v, c.v)
+	switch c.op {
+	case "=":
+		return n == 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	}
+	return false
+}
+
+// parseComparator parses one space-separated term into the comparators it stands for.
+func parseComparator(s string) ([]comparator, error) {
+	if s == "*" || s == "x" || s == "X" {
+		return []comparator{{op: ">=", v: Version{}}}, nil
+	}
+	for _, op := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if rest, ok := strings.CutPrefix(s, op); ok {
+			return expand(op, rest)
+		}
+	}
+	return expand("", s)
+}
+
+// expand turns an operator and a possibly partial version ("1", "1.2", "1.x") into
+// comparators.
+func expand(op, s string) ([]comparator, error) {
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+	next := func() Version { // the first version past the partial prefix
+		switch parts {
+		case 0:
+			return Version{}
+		case 1:
+			return Version{Major: v.Major + 1}
+		default:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+	}
+	switch op {
+	case "", "=":
+		if parts == 0 {
+			return []comparator{{op: ">=", v: Version{}}}, nil
+		}
+		if parts == 3 {
+			return []comparator{{op: "=", v: v}}, nil
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: next()}}, nil
+	case ">=", "<":
+		return []comparator{{op: op, v: v}}, nil
+	case ">":
+		if parts < 3 {
+			return []comparator{{op: ">=", v: next()}}, nil
+		}
+		return []comparator{{op: op, v: v}}, nil
+	case "<=":
+		if parts < 3 {
+			return []comparator{{op: "<", v: next()}}, nil
+		}
+		return []comparator{{op: op, v: v}}, nil
+	case "~":
+		if parts == 1 {
+			return []comparator{{op: ">=", v: v}, {op: "<", v: Version{Major: v.Major + 1}}}, nil
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: Version{Major: v.Major, Minor: v.Minor + 1}}}, nil
+	case "^":
+		var upper Version
+		switch {
+		case v.Major > 0 || parts == 1:
+			upper = Version{Major: v.Major + 1}
+		case v.Minor > 0 || parts == 2:
+			upper = Version{Minor: v.Minor + 1}
+		default:
+			upper = Version{Patch: v.Patch + 1}
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: upper}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// parsePartial parses a full or partial version. It returns the number of numeric parts
+// given (wildcards "x", "X" and "*" end the version).
+func parsePartial(s string) (Version, int, error) {
+	if s == "" {
+		return Version{}, 0, fmt.Errorf("missing version")
+	}
+	trimmed := strings.TrimPrefix(s, "v")
+	if v, err := Parse(trimmed); err == nil {
+		return v, 3, nil
+	}
+	var v Version
+	parts := strings.Split(trimmed, ".")
+	if len(parts) > 3 {
+		return Version{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+	nums := [3]*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			if i != len(parts)-1 {
+				return Version{}, 0, fmt.Errorf("invalid version %q", s)
+			}
+			return v, i, nil
+		}
+		n, ok := number(p)
+		if !ok {
+			return Version{}, 0, fmt.Errorf("invalid version %q", s)
+		}
+		*nums[i] = n
+	}
+	if len(parts) == 3 {
+		// Three numeric parts that Parse rejected.
+		return Version{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+	return v, len(parts), nil
+}
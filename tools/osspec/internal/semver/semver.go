@@ -0,0 +1,161 @@
+// Package semver parses semantic versions (https://semver.org) and the version constraints
+// profiles use to select ruleset versions.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is ignored for precedence.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          []string
+	Build               string
+}
+
+// Parse parses a semantic version such as "1.2.3", "1.2.3-rc.1" or "v1.2.3".
+func Parse(s string) (Version, error) {
+	in := s
+	s = strings.TrimPrefix(s, "v")
+	var v Version
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		v.Build = s[i+1:]
+		s = s[:i]
+		if !validIdentifiers(v.Build) {
+			return Version{}, fmt.Errorf("semver: invalid build metadata in %q", in)
+		}
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		pre := s[i+1:]
+		s = s[:i]
+		if !validIdentifiers(pre) {
+			return Version{}, fmt.Errorf("semver: invalid prerelease in %q", in)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("semver: %q is not MAJOR.MINOR.PATCH", in)
+	}
+	nums := [3]*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, ok := number(p)
+		if !ok {
+			return Version{}, fmt.Errorf("semver: invalid number %q in %q", p, in)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// String returns v without a leading "v".
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on the precedence of a and b.
+func Compare(a, b Version) int {
+	for _, c := range [][2]int{{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch}} {
+		if c[0] != c[1] {
+			return cmpInt(c[0], c[1])
+		}
+	}
+	// A version without a prerelease has higher precedence than one with.
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		x, y := a.Prerelease[i], b.Prerelease[i]
+		xn, xok := number(x)
+		yn, yok := number(y)
+		switch {
+		case xok && yok:
+			if xn != yn {
+				return cmpInt(xn, yn)
+			}
+		case xok:
+			return -1
+		case yok:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmpInt(len(a.Prerelease), len(b.Prerelease))
+}
+
+// CompareStrings orders version strings, such as ruleset versions, by precedence. The
+// empty string (no version) sorts first; strings that do not parse compare as text.
+func CompareStrings(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return Compare(va, vb)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// number parses a numeric identifier: digits without leading zeros.
+func number(s string) (int, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+func validIdentifiers(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		for _, r := range id {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package semver
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3"},
+		{"1.0.0-rc.1+build.5", "1.0.0-rc.1+build.5"},
+	} {
+		v, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := v.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "1", "1.2", "1.2.3.4", "01.2.3", "1.2.x", "1.2.3-", "1.2.3-a..b"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q): expected error", in)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// In ascending precedence, per the semver specification's example.
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, b := mustParse(t, ordered[i-1]), mustParse(t, ordered[i])
+		if Compare(a, b) != -1 || Compare(b, a) != 1 {
+			t.Errorf("expected %s < %s", a, b)
+		}
+	}
+	if Compare(mustParse(t, "1.0.0+a"), mustParse(t, "1.0.0+b")) != 0 {
+		t.Error("build metadata must not affect precedence")
+	}
+}
+
+func TestCompareStrings(t *testing.T) {
+	ordered := []string{"", "1.0.0-rc.1", "1.0.0", "1.10.0", "10.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, b := ordered[i-1], ordered[i]
+		if CompareStrings(a, b) != -1 || CompareStrings(b, a) != 1 {
+			t.Errorf("expected %q < %q", a, b)
+		}
+	}
+	if CompareStrings("1.0.0", "1.0.0") != 0 {
+		t.Error("equal versions must compare equal")
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	versions := []string{"0.2.3", "0.2.9", "0.3.0", "1.0.0", "1.2.0", "1.2.5", "1.3.0", "2.0.0-rc.1", "2.0.0", "2.1.0"}
+	for _, tt := range []struct {
+		constraint string
+		want       []string
+	}{
+		{"1.2.0", []string{"1.2.0"}},
+		{"v1.2.0", []string{"1.2.0"}},
+		{"=2.0.0-rc.1", []string{"2.0.0-rc.1"}},
+		{">=1.2.0 <2.0.0", []string{"1.2.0", "1.2.5", "1.3.0"}},
+		{">1.2", []string{"1.3.0", "2.0.0", "2.1.0"}},
+		{"<=1.2", []string{"0.2.3", "0.2.9", "0.3.0", "1.0.0", "1.2.0", "1.2.5"}},
+		{"^1.2.0", []string{"1.2.0", "1.2.5", "1.3.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}},
+		{"~1.2.0", []string{"1.2.0", "1.2.5"}},
+		{"1.x", []string{"1.0.0", "1.2.0", "1.2.5", "1.3.0"}},
+		{"1.2.*", []string{"1.2.0", "1.2.5"}},
+		{"*", []string{"0.2.3", "0.2.9", "0.3.0", "1.0.0", "1.2.0", "1.2.5", "1.3.0", "2.0.0", "2.1.0"}},
+		{"~0.3 || >=2.1.0", []string{"0.3.0", "2.1.0"}},
+		{">=2.0.0-rc.1", []string{"2.0.0-rc.1", "2.0.0", "2.1.0"}},
+	} {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		var got []string
+		for _, s := range versions {
+			if c.Check(mustParse(t, s)) {
+				got = append(got, s)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q matches %v, want %v", tt.constraint, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "||", ">=", "1.x.2", "1.2.3.4", "~>1.2", "latest"} {
+		if _, err := ParseConstraint(in); err == nil {
+			t.Errorf("ParseConstraint(%q): expected error", in)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return v
+}
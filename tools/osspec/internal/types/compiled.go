@@ -21,12 +21,16 @@ type RequirementsIndex struct {
 
 type RulesetRequirement struct {
 	RulesetKey  string       `json:"ruleset_key"`
+	RulesetVersion string    `json:"ruleset_version,omitempty"`
 	Status      string       `json:"status"`
 	Scope       Scope        `json:"scope"`
 	Datasets    []DatasetRefSpec `json:"datasets"`
 	CheckTypes  []CheckType  `json:"check_types"`
 	ValueParams []string     `json:"value_params"`
 	Rules       []RuleRequirement `json:"rules"`
+	// InactiveRules are the rules with lifecycle.is_active=false. They are not counted in
+	// Datasets, CheckTypes and ValueParams.
+	InactiveRules []RuleRequirement `json:"inactive_rules,omitempty"`
 }
 
 type RuleRequirement struct {
@@ -35,6 +39,7 @@ type RuleRequirement struct {
 	Datasets         []DatasetRefSpec `json:"datasets"`
 	CheckType        *CheckType       `json:"check_type"`
 	ValueParams      []string         `json:"value_params"`
+	ReplacedBy       string           `json:"replaced_by,omitempty"`
 	Monitoring       struct {
 		Status MonitoringStatus `json:"status"`
 	} `json:"monitoring"`
@@ -65,12 +70,19 @@ type CodegenRequest struct {
 	SchemaVersion int         `json:"schema_version"`
 	Kind          string      `json:"kind"`
 	Language      string      `json:"language"`
+	// GeneratorVersion is the version of osspec running the plugin.
+	GeneratorVersion string   `json:"generator_version,omitempty"`
 	Descriptor    DescriptorV1 `json:"descriptor"`
 }
 
 type CodegenResponse struct {
 	SchemaVersion int           `json:"schema_version"`
 	Kind          string        `json:"kind"`
+	// PluginVersion is the plugin's own version, for diagnostics.
+	PluginVersion string        `json:"plugin_version,omitempty"`
+	// SupportedSpecVersions is a semver constraint (for example "1.x") on the
+	// version.json spec_version the plugin generates code for. Required.
+	SupportedSpecVersions string `json:"supported_spec_versions"`
 	Files         []CodegenFile `json:"files"`
 }
 
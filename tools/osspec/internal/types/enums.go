@@ -32,22 +32,30 @@ const (
 	CheckTypeDatasetFieldCompare     CheckType = "dataset.field_compare"
 	CheckTypeDatasetCountCompare     CheckType = "dataset.count_compare"
 	CheckTypeDatasetJoinCountCompare CheckType = "dataset.join_count_compare"
+	CheckTypeDatasetJoinFieldCompare CheckType = "dataset.join_field_compare"
+	CheckTypeDatasetAggregateCompare CheckType = "dataset.aggregate_compare"
 	CheckTypeManualAttestation       CheckType = "manual.attestation"
 )
 
 type Operator string
 
 const (
-	OperatorEq       Operator = "eq"
-	OperatorNeq      Operator = "neq"
-	OperatorLt       Operator = "lt"
-	OperatorLte      Operator = "lte"
-	OperatorGt       Operator = "gt"
-	OperatorGte      Operator = "gte"
-	OperatorExists   Operator = "exists"
-	OperatorAbsent   Operator = "absent"
-	OperatorIn       Operator = "in"
-	OperatorContains Operator = "contains"
+	OperatorEq           Operator = "eq"
+	OperatorNeq          Operator = "neq"
+	OperatorLt           Operator = "lt"
+	OperatorLte          Operator = "lte"
+	OperatorGt           Operator = "gt"
+	OperatorGte          Operator = "gte"
+	OperatorExists       Operator = "exists"
+	OperatorAbsent       Operator = "absent"
+	OperatorIn           Operator = "in"
+	OperatorContains     Operator = "contains"
+	OperatorMatches      Operator = "matches"
+	OperatorStartsWith   Operator = "starts_with"
+	OperatorEndsWith     Operator = "ends_with"
+	OperatorEqIgnoreCase Operator = "eq_ignore_case"
+	OperatorOlderThan    Operator = "older_than"
+	OperatorNewerThan    Operator = "newer_than"
 )
 
 type CompareOp string
@@ -76,6 +84,13 @@ const (
 	OnUnmatchedLeftError  OnUnmatchedLeft = "error"
 )
 
+type Quantifier string
+
+const (
+	QuantifierAny Quantifier = "any"
+	QuantifierAll Quantifier = "all"
+)
+
 type FieldCompareMatch string
 
 const (
@@ -93,6 +108,16 @@ const (
 	FieldCompareOnEmptyError   FieldCompareOnEmpty = "error"
 )
 
+type AggregateFunction string
+
+const (
+	AggregateFunctionSum           AggregateFunction = "sum"
+	AggregateFunctionMin           AggregateFunction = "min"
+	AggregateFunctionMax           AggregateFunction = "max"
+	AggregateFunctionAvg           AggregateFunction = "avg"
+	AggregateFunctionCountDistinct AggregateFunction = "count_distinct"
+)
+
 type ReferenceType string
 
 const (
@@ -128,3 +153,13 @@ const (
 	DatasetErrorKindSyncFailed         DatasetErrorKind = "sync_failed"
 	DatasetErrorKindEngineError        DatasetErrorKind = "engine_error"
 )
+
+type ResultStatus string
+
+const (
+	ResultStatusPass          ResultStatus = "pass"
+	ResultStatusFail          ResultStatus = "fail"
+	ResultStatusUnknown       ResultStatus = "unknown"
+	ResultStatusError         ResultStatus = "error"
+	ResultStatusNotApplicable ResultStatus = "not_applicable"
+)
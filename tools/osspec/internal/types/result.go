@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+// EvaluationResultDoc is the document an engine emits after evaluating a ruleset.
+// It is not a spec input: osspec only publishes its schema and generated types.
+type EvaluationResultDoc struct {
+	SchemaVersion int              `json:"schema_version"`
+	Kind          string           `json:"kind"`
+	Result        EvaluationResult `json:"result"`
+}
+
+type EvaluationResult struct {
+	Ruleset     EvaluationRuleset      `json:"ruleset"`
+	EvalContext EvaluationContext      `json:"eval_context"`
+	StartedAt   time.Time              `json:"started_at"`
+	FinishedAt  time.Time              `json:"finished_at"`
+	Rules       []EvaluationRuleResult `json:"rules"`
+}
+
+type EvaluationRuleset struct {
+	Key  string `json:"key"`
+	Hash string `json:"hash"`
+}
+
+type EvaluationContext struct {
+	ScopeKind         ScopeKind `json:"scope_kind"`
+	ConnectorKind     string    `json:"connector_kind,omitempty"`
+	ConnectorInstance string    `json:"connector_instance,omitempty"`
+	// ConnectorInstances maps connector kinds to the instances that served datasets
+	// qualified with that kind in global rulesets.
+	ConnectorInstances map[string]string `json:"connector_instances,omitempty"`
+	// ReferenceTime is the "now" that older_than and newer_than compared against.
+	ReferenceTime *time.Time `json:"reference_time,omitempty"`
+}
+
+type EvaluationRuleResult struct {
+	RuleKey           string                   `json:"rule_key"`
+	Status            ResultStatus             `json:"status"`
+	Reason            string                   `json:"reason,omitempty"`
+	EvaluatedAt       *time.Time               `json:"evaluated_at,omitempty"`
+	AffectedResources []EvaluationResource     `json:"affected_resources,omitempty"`
+	DatasetErrors     []EvaluationDatasetError `json:"dataset_errors,omitempty"`
+}
+
+// EvaluationResource is an affected resource resolved via evidence.affected_resources
+// (ID from id_field, Display from display_field).
+type EvaluationResource struct {
+	ID      string `json:"id"`
+	Display string `json:"display,omitempty"`
+}
+
+type EvaluationDatasetError struct {
+	Dataset           string           `json:"dataset"`
+	Version           int              `json:"version"`
+	ConnectorKind     string           `json:"connector_kind,omitempty"`
+	ConnectorInstance string           `json:"connector_instance,omitempty"`
+	Kind              DatasetErrorKind `json:"kind"`
+	Message           string           `json:"message,omitempty"`
+}
@@ -1,6 +1,11 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
 
 type Header struct {
 	SchemaVersion int    `json:"schema_version"`
@@ -48,23 +53,49 @@ type RulesetDoc struct {
 }
 
 type Ruleset struct {
-	Key               string             `json:"key"`
-	Name              string             `json:"name"`
-	Scope             Scope              `json:"scope"`
-	Source            *Source            `json:"source,omitempty"`
-	Status            string             `json:"status,omitempty"`
-	Description       string             `json:"description,omitempty"`
-	Tags              []string           `json:"tags,omitempty"`
-	References        []Reference        `json:"references,omitempty"`
-	FrameworkMappings []FrameworkMapping `json:"framework_mappings,omitempty"`
+	Key               string               `json:"key"`
+	Version           string               `json:"version,omitempty"`
+	Name              string               `json:"name"`
+	Scope             Scope                `json:"scope"`
+	Source            *Source              `json:"source,omitempty"`
+	Status            string               `json:"status,omitempty"`
+	Description       string               `json:"description,omitempty"`
+	Tags              []string             `json:"tags,omitempty"`
+	References        []Reference          `json:"references,omitempty"`
+	FrameworkMappings []FrameworkMapping   `json:"framework_mappings,omitempty"`
 	Requirements      *RulesetRequirements `json:"requirements,omitempty"`
 	DataContracts     []DatasetContractRef `json:"data_contracts,omitempty"`
-	Rules             []Rule             `json:"rules"`
+	Rules             []Rule               `json:"rules"`
+}
+
+// ID returns the ruleset key, qualified with the version ("key@version") if the ruleset
+// has one. It identifies the ruleset in the artifacts and requirements indexes.
+func (r *Ruleset) ID() string {
+	if r.Version == "" {
+		return r.Key
+	}
+	return r.Key + "@" + r.Version
 }
 
 type DatasetRefSpec struct {
 	Dataset string `json:"dataset"`
 	Version int    `json:"version"`
+	// ConnectorKind is set in the requirements index for datasets a check qualifies with a
+	// connector kind.
+	ConnectorKind string `json:"connector_kind,omitempty"`
+}
+
+// String returns "dataset@version", followed by the connector kind in parentheses if set.
+func (r DatasetRefSpec) String() string {
+	return DatasetRefString(r.Dataset, r.Version, r.ConnectorKind)
+}
+
+// DatasetRefString formats a dataset reference like DatasetRefSpec.String.
+func DatasetRefString(dataset string, version int, connectorKind string) string {
+	if connectorKind == "" {
+		return fmt.Sprintf("%s@%d", dataset, version)
+	}
+	return fmt.Sprintf("%s@%d (%s)", dataset, version, connectorKind)
 }
 
 type FrameworkMapping struct {
@@ -155,6 +186,12 @@ type Lifecycle struct {
 	ReplacedBy  string `json:"replaced_by,omitempty"`
 }
 
+// Active reports whether the rule is active. Rules are active unless lifecycle.is_active is
+// false.
+func (r *Rule) Active() bool {
+	return r.Lifecycle == nil || r.Lifecycle.IsActive == nil || *r.Lifecycle.IsActive
+}
+
 type Check struct {
 	Type CheckType `json:"type"`
 
@@ -165,21 +202,59 @@ type Check struct {
 	OnSyncError        ErrorPolicy `json:"on_sync_error,omitempty"`
 	Notes              string      `json:"notes,omitempty"`
 
-	// dataset.field_compare, dataset.count_compare
-	Dataset string      `json:"dataset,omitempty"`
-	Where   []Predicate `json:"where,omitempty"`
+	// dataset.field_compare, dataset.count_compare, dataset.aggregate_compare
+	Dataset string `json:"dataset,omitempty"`
+	// ConnectorKind qualifies Dataset with the connector that provides it (global scope
+	// only). JoinSide.ConnectorKind does the same for join checks.
+	ConnectorKind string      `json:"connector_kind,omitempty"`
+	Where         []Predicate `json:"where,omitempty"`
 
-	// dataset.field_compare
-	Assert *Predicate         `json:"assert,omitempty"`
+	// dataset.field_compare, dataset.join_field_compare
+	Assert *Predicate          `json:"assert,omitempty"`
 	Expect *FieldCompareExpect `json:"expect,omitempty"`
 
-	// dataset.count_compare, dataset.join_count_compare
+	// dataset.count_compare, dataset.join_count_compare, dataset.aggregate_compare
 	Compare *Compare `json:"compare,omitempty"`
 
-	// dataset.join_count_compare
-	Left            *JoinSide        `json:"left,omitempty"`
-	Right           *JoinSide        `json:"right,omitempty"`
-	OnUnmatchedLeft OnUnmatchedLeft  `json:"on_unmatched_left,omitempty"`
+	// dataset.aggregate_compare
+	Aggregate *Aggregate `json:"aggregate,omitempty"`
+
+	// dataset.join_count_compare, dataset.join_field_compare
+	Left            *JoinSide       `json:"left,omitempty"`
+	Right           *JoinSide       `json:"right,omitempty"`
+	OnUnmatchedLeft OnUnmatchedLeft `json:"on_unmatched_left,omitempty"`
+}
+
+// CheckDataset is a dataset read by a check, with the connector kind that qualifies it in
+// global rulesets ("" if unqualified).
+type CheckDataset struct {
+	Dataset       string
+	ConnectorKind string
+}
+
+// Datasets returns the datasets c reads: check.dataset, or check.left and check.right for
+// join checks. Unset datasets are left out.
+func (c *Check) Datasets() []CheckDataset {
+	var out []CheckDataset
+	switch c.Type {
+	case CheckTypeDatasetFieldCompare, CheckTypeDatasetCountCompare, CheckTypeDatasetAggregateCompare:
+		if strings.TrimSpace(c.Dataset) != "" {
+			out = append(out, CheckDataset{Dataset: c.Dataset, ConnectorKind: c.ConnectorKind})
+		}
+	case CheckTypeDatasetJoinCountCompare, CheckTypeDatasetJoinFieldCompare:
+		for _, side := range []*JoinSide{c.Left, c.Right} {
+			if side != nil && strings.TrimSpace(side.Dataset) != "" {
+				out = append(out, CheckDataset{Dataset: side.Dataset, ConnectorKind: side.ConnectorKind})
+			}
+		}
+	}
+	return out
+}
+
+// IsJoin reports whether checks of type t join two datasets (check.left and check.right),
+// so that their predicates read left_path or right_path instead of path.
+func (t CheckType) IsJoin() bool {
+	return t == CheckTypeDatasetJoinCountCompare || t == CheckTypeDatasetJoinFieldCompare
 }
 
 type Predicate struct {
@@ -190,14 +265,85 @@ type Predicate struct {
 	LeftPath  string `json:"left_path,omitempty"`
 	RightPath string `json:"right_path,omitempty"`
 
-	Op        Operator `json:"op"`
-	Value     any      `json:"value,omitempty"`
-	ValueParam string  `json:"value_param,omitempty"`
+	Op         Operator `json:"op,omitempty"`
+	Value      any      `json:"value,omitempty"`
+	ValueParam string   `json:"value_param,omitempty"`
+	// Quantifier combines the values a path with PathWildcard segments reaches.
+	Quantifier Quantifier `json:"quantifier,omitempty"`
+
+	// Boolean group (exactly one of all_of/any_of/not, and none of the fields above)
+	AllOf []Predicate `json:"all_of,omitempty"`
+	AnyOf []Predicate `json:"any_of,omitempty"`
+	Not   *Predicate  `json:"not,omitempty"`
+}
+
+// IsStringMatch reports whether o compares a string field with a string operand (matches,
+// starts_with, ends_with and eq_ignore_case).
+func (o Operator) IsStringMatch() bool {
+	switch o {
+	case OperatorMatches, OperatorStartsWith, OperatorEndsWith, OperatorEqIgnoreCase:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRelativeTime reports whether o compares a date-time field with the reference time minus
+// an ISO-8601 duration (older_than and newer_than).
+func (o Operator) IsRelativeTime() bool {
+	return o == OperatorOlderThan || o == OperatorNewerThan
+}
+
+// PathWildcard is the JSON pointer segment that matches every element of an array in
+// predicate paths.
+const PathWildcard = "*"
+
+// HasWildcard reports whether pointer has a PathWildcard segment.
+func HasWildcard(pointer string) bool {
+	return slices.Contains(strings.Split(pointer, "/"), PathWildcard)
+}
+
+// ComparedPath returns the path a comparison reads: path, or left_path/right_path in join
+// predicates.
+func (p *Predicate) ComparedPath() string {
+	switch {
+	case p.Path != "":
+		return p.Path
+	case p.LeftPath != "":
+		return p.LeftPath
+	default:
+		return p.RightPath
+	}
+}
+
+// IsGroup reports whether p is a boolean group of nested predicates rather than a
+// comparison.
+func (p *Predicate) IsGroup() bool {
+	return p.AllOf != nil || p.AnyOf != nil || p.Not != nil
+}
+
+// Walk calls fn for p and every predicate nested in it, depth first, with the JSON pointer
+// of each relative to p ("" for p itself).
+func (p *Predicate) Walk(fn func(ptr string, q *Predicate)) {
+	p.walk("", fn)
+}
+
+func (p *Predicate) walk(ptr string, fn func(string, *Predicate)) {
+	fn(ptr, p)
+	for i := range p.AllOf {
+		p.AllOf[i].walk(fmt.Sprintf("%s/all_of/%d", ptr, i), fn)
+	}
+	for i := range p.AnyOf {
+		p.AnyOf[i].walk(fmt.Sprintf("%s/any_of/%d", ptr, i), fn)
+	}
+	if p.Not != nil {
+		p.Not.walk(ptr+"/not", fn)
+	}
 }
 
 type Compare struct {
 	Op        CompareOp `json:"op"`
-	Value     *int      `json:"value,omitempty"`
+	Value     *float64  `json:"value,omitempty"`
 	ValueParam string   `json:"value_param,omitempty"`
 }
 
@@ -207,9 +353,16 @@ type FieldCompareExpect struct {
 	OnEmpty     FieldCompareOnEmpty `json:"on_empty,omitempty"`
 }
 
+type Aggregate struct {
+	Function AggregateFunction   `json:"function"`
+	Path     string              `json:"path"`
+	OnEmpty  FieldCompareOnEmpty `json:"on_empty,omitempty"`
+}
+
 type JoinSide struct {
-	Dataset string `json:"dataset"`
-	KeyPath string `json:"key_path"`
+	Dataset       string `json:"dataset"`
+	KeyPath       string `json:"key_path"`
+	ConnectorKind string `json:"connector_kind,omitempty"`
 }
 
 type DatasetContractDoc struct {
@@ -249,10 +402,70 @@ type Profile struct {
 	Key         string              `json:"key"`
 	Name        string              `json:"name"`
 	Description string              `json:"description,omitempty"`
+	Extends     []string            `json:"extends,omitempty"`
 	Rulesets    []ProfileRulesetRef `json:"rulesets"`
 }
 
 type ProfileRulesetRef struct {
-	Key     string `json:"key"`
-	Version string `json:"version,omitempty"`
+	Key       string         `json:"key"`
+	Version   string         `json:"version,omitempty"`
+	Overrides []RuleOverride `json:"overrides,omitempty"`
+}
+
+// RuleOverride tailors one rule of a ruleset included by a profile.
+type RuleOverride struct {
+	RuleKey    string         `json:"rule_key"`
+	Exclude    bool           `json:"exclude,omitempty"`
+	Severity   Severity       `json:"severity,omitempty"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+	Rationale  string         `json:"rationale,omitempty"`
+}
+
+type ConformanceSuiteDoc struct {
+	SchemaVersion int              `json:"schema_version"`
+	Kind          string           `json:"kind"`
+	Suite         ConformanceSuite `json:"suite"`
+}
+
+type ConformanceSuite struct {
+	Key         string              `json:"key"`
+	Description string              `json:"description,omitempty"`
+	Vectors     []ConformanceVector `json:"vectors"`
+}
+
+type ConformanceVector struct {
+	Key           string               `json:"key"`
+	Description   string               `json:"description,omitempty"`
+	Rule          *ConformanceRuleRef  `json:"rule,omitempty"`
+	Check         *Check               `json:"check,omitempty"`
+	Evidence      *Evidence            `json:"evidence,omitempty"`
+	Parameters    map[string]any       `json:"parameters,omitempty"`
+	ReferenceTime string               `json:"reference_time,omitempty"`
+	Datasets      []ConformanceDataset `json:"datasets"`
+	Expect        ConformanceExpect    `json:"expect"`
+}
+
+type ConformanceRuleRef struct {
+	RulesetKey string `json:"ruleset_key"`
+	RuleKey    string `json:"rule_key"`
+}
+
+type ConformanceDataset struct {
+	Dataset       string                   `json:"dataset"`
+	Version       int                      `json:"version"`
+	ConnectorKind string                   `json:"connector_kind,omitempty"`
+	Rows          []json.RawMessage        `json:"rows,omitempty"`
+	Error         *ConformanceDatasetError `json:"error,omitempty"`
+}
+
+type ConformanceDatasetError struct {
+	Kind    DatasetErrorKind `json:"kind"`
+	Message string           `json:"message,omitempty"`
+}
+
+type ConformanceExpect struct {
+	Status ResultStatus `json:"status"`
+	// AffectedResourceIDs is nil if the vector does not check affected resources; an
+	// empty list expects none.
+	AffectedResourceIDs *[]string `json:"affected_resource_ids,omitempty"`
 }
@@ -0,0 +1,200 @@
+// Package osspec compiles and validates Open SSPM spec trees in-process. It is the library
+// behind the osspec command.
+//
+// A spec tree is an fs.FS laid out like this repository: version.json and dictionary.json
+// at the root, the metaschema under metaschema/ and the spec documents under specs/. Use
+// os.DirFS for a checkout, an embed.FS or fstest.MapFS for bundles held in memory, or Open,
+// ReadTarGz and ReadZip for .tar.gz and .zip spec bundles.
+//
+// Compiled documents are returned as the generated types in gen/go/opensspm/spec/v1.
+// Validation problems are reported as Diagnostics, which also implement error.
+package osspec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+
+	specv1 "github.com/open-sspm/open-sspm-spec/gen/go/opensspm/spec/v1"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/buildinfo"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/compiler"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/diag"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/hash"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/loader"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/schemasem"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/semver"
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/types"
+)
+
+type (
+	// Diagnostic is a single validation problem with its location.
+	Diagnostic = diag.Diagnostic
+	// Diagnostics is a list of diagnostics. It implements error.
+	Diagnostics = diag.List
+	Location    = diag.Location
+	Severity    = diag.Severity
+)
+
+const (
+	SeverityError   = diag.SeverityError
+	SeverityWarning = diag.SeverityWarning
+)
+
+// Version returns the osspec version. Compile refuses spec trees whose version.json
+// generator_min_version is newer.
+func Version() string {
+	return buildinfo.Version
+}
+
+type Options struct {
+	// SpecsDir is the directory holding spec documents. Defaults to "specs".
+	SpecsDir string
+	// MetaschemaDir is the directory holding the metaschema. Defaults to "metaschema".
+	MetaschemaDir string
+	// Baseline is a previously published descriptor. If set, dataset contract versions it
+	// contains must not change incompatibly.
+	Baseline *specv1.DescriptorV1
+}
+
+type Result struct {
+	Descriptor   specv1.DescriptorV1
+	Artifacts    specv1.ArtifactsIndex
+	Requirements specv1.RequirementsIndex
+	Conformance  []specv1.Compiled[specv1.ConformanceSuiteDoc]
+	// EffectiveRulesets maps a profile key to the rulesets it includes, with the profile's
+	// rule overrides applied.
+	EffectiveRulesets map[string][]specv1.Compiled[specv1.RulesetDoc]
+
+	compiled *compiler.Result
+}
+
+// Compile loads, validates and compiles the spec tree in fsys. If the tree is invalid the
+// error is Diagnostics (see AsDiagnostics).
+func Compile(ctx context.Context, fsys fs.FS, opts Options) (*Result, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("osspec: fsys is required")
+	}
+	copts := compiler.Options{
+		FS:            fsys,
+		SpecsDir:      opts.SpecsDir,
+		MetaschemaDir: opts.MetaschemaDir,
+	}
+	if opts.Baseline != nil {
+		copts.Baseline = new(types.DescriptorV1)
+		if err := convert(opts.Baseline, copts.Baseline); err != nil {
+			return nil, err
+		}
+	}
+	res, err := compiler.Compile(ctx, copts)
+	if err != nil {
+		return nil, err
+	}
+	out := &Result{compiled: res}
+	for _, c := range []struct {
+		from, to any
+	}{
+		{res.Descriptor, &out.Descriptor},
+		{res.Artifacts, &out.Artifacts},
+		{res.Requirements, &out.Requirements},
+		{res.Conformance, &out.Conformance},
+		{res.EffectiveRulesets, &out.EffectiveRulesets},
+	} {
+		if err := convert(c.from, c.to); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Validate validates the spec tree in fsys and returns its diagnostics, or nil if it is
+// valid. Failures that are not tied to a document (for example a missing metaschema) are
+// reported as a single diagnostic with code "internal".
+func Validate(ctx context.Context, fsys fs.FS, opts Options) Diagnostics {
+	_, err := Compile(ctx, fsys, opts)
+	return diag.FromError(err)
+}
+
+// Build compiles the spec tree in fsys and writes the dist artifacts (descriptor, indexes,
+// compiled documents and conformance suites) to the OS directory distDir.
+func Build(ctx context.Context, fsys fs.FS, distDir string, opts Options) (*Result, error) {
+	res, err := Compile(ctx, fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := compiler.WriteDist(distDir, res.compiled); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Open returns the spec tree at path: a directory, a gzipped tarball (.tar.gz, .tgz) or a
+// zip archive. Archives are read into memory, within the limits of ReadTarGz and ReadZip
+// (a .zip file may be at most 64 MiB); if one holds a single top-level directory the tree
+// is rooted there.
+func Open(path string) (fs.FS, error) {
+	return loader.Open(path)
+}
+
+// ReadTarGz reads a gzipped tarball spec bundle into memory. Bundles with more than 10000
+// entries or 64 MiB of files are rejected.
+func ReadTarGz(r io.Reader) (fs.FS, error) {
+	return loader.ReadTarGz(r)
+}
+
+// ReadZip reads a zip archive spec bundle into memory. Archives with more than 10000
+// entries or 64 MiB of decompressed files are rejected.
+func ReadZip(r io.ReaderAt, size int64) (fs.FS, error) {
+	return loader.ReadZip(r, size)
+}
+
+// AsDiagnostics returns the diagnostics carried by err. Errors that are not diagnostics
+// become a single diagnostic with code "internal"; a nil error yields nil.
+func AsDiagnostics(err error) Diagnostics {
+	return diag.FromError(err)
+}
+
+// CompareVersions orders version strings, such as ruleset versions, by semver precedence:
+// it returns -1, 0 or +1. The empty string (no version) sorts first. Profiles resolve a
+// ruleset key to its highest version satisfying their constraint in this order.
+func CompareVersions(a, b string) int {
+	return semver.CompareStrings(a, b)
+}
+
+// HashObjectJCS returns the SHA-256 hex digest of v's RFC 8785 (JCS) canonical JSON, the
+// hash osspec records for compiled artifacts, and the canonical bytes.
+func HashObjectJCS(v any) (string, []byte, error) {
+	return hash.HashObjectJCS(v)
+}
+
+// Registry validates documents against the metaschema, one schema per kind.
+type Registry struct {
+	r *schemasem.Registry
+}
+
+// LoadRegistry compiles the metaschema files in dir of fsys.
+func LoadRegistry(fsys fs.FS, dir string) (*Registry, error) {
+	r, err := schemasem.LoadRegistryFS(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{r: r}, nil
+}
+
+// Validate validates the JSON document b against the schema for kind. Diagnostics have no
+// file; their pointers and positions refer to b.
+func (r *Registry) Validate(kind string, b []byte) Diagnostics {
+	return diag.FromError(r.r.ValidateKindJSON(kind, b))
+}
+
+func convert(from, to any) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("osspec: encode result: %w", err)
+	}
+	if err := json.Unmarshal(b, to); err != nil {
+		return fmt.Errorf("osspec: decode result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package osspec
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/open-sspm/open-sspm-spec/tools/osspec/internal/testutil"
+)
+
+func TestCompile_MatchesCommittedDescriptor(t *testing.T) {
+	root := testutil.RepoRoot(t)
+	res, err := Compile(context.Background(), os.DirFS(root), Options{})
+	if err != nil {
+		t.Fatalf("Compile() error: %v", err)
+	}
+	_, canonical, err := HashObjectJCS(res.Descriptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(root, "dist", "descriptor.v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(canonical, bytes.TrimSuffix(want, []byte("\n"))) {
+		t.Fatalf("descriptor differs from dist/descriptor.v1.json")
+	}
+	if len(res.Conformance) == 0 {
+		t.Fatalf("expected conformance suites")
+	}
+}
+
+// mapFS copies the inputs Compile reads from the repo into memory.
+func mapFS(t *testing.T) fstest.MapFS {
+	t.Helper()
+	root := testutil.RepoRoot(t)
+	m := fstest.MapFS{}
+	src := os.DirFS(root)
+	for _, name := range []string{"version.json", "dictionary.json", "metaschema", "specs"} {
+		err := fs.WalkDir(src, name, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			b, err := fs.ReadFile(src, p)
+			if err != nil {
+				return err
+			}
+			m[p] = &fstest.MapFile{Data: b, Mode: 0o644}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestValidate_InMemory(t *testing.T) {
+	m := mapFS(t)
+	if diags := Validate(context.Background(), m, Options{}); diags != nil {
+		t.Fatalf("Validate() = %v", diags)
+	}
+
+	const rel = "specs/profiles/cis.okta.idaas_stig.profile.v1.json"
+	m[rel].Data = []byte(strings.Replace(string(m[rel].Data), `"kind": "opensspm.profile"`, `"kind": "opensspm.widget"`, 1))
+	diags := Validate(context.Background(), m, Options{})
+	if len(diags) != 1 || diags[0].File != rel || diags[0].Line == 0 || diags[0].Severity != SeverityError {
+		t.Fatalf("Validate() = %+v", diags)
+	}
+}
+
+func TestBuild_WritesDist(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "dist")
+	if _, err := Build(context.Background(), mapFS(t), out, Options{}); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	for _, p := range []string{"descriptor.v1.json", "index/artifacts.json", "compiled/dictionary.json"} {
+		if _, err := os.Stat(filepath.Join(out, p)); err != nil {
+			t.Errorf("missing %s: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(out), "docs")); err == nil {
+		t.Errorf("Build must not write the docs site")
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	reg, err := LoadRegistry(mapFS(t), "metaschema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags := reg.Validate("opensspm.profile", []byte(`{"schema_version": 1, "kind": "opensspm.profile"}`))
+	if len(diags) == 0 || diags[0].Code != "schema-violation" || diags[0].Line != 1 {
+		t.Fatalf("Validate() = %+v", diags)
+	}
+}